@@ -0,0 +1,44 @@
+package browser
+
+import (
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+// networkTracker counts the requests of a tab that are still in flight.
+type networkTracker struct {
+	mu         sync.Mutex
+	inFlight   map[network.RequestID]bool
+	lastChange time.Time
+}
+
+func newNetworkTracker() *networkTracker {
+	return &networkTracker{inFlight: make(map[network.RequestID]bool), lastChange: time.Now()}
+}
+
+// handle is registered with chromedp.ListenTarget.
+func (t *networkTracker) handle(event interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch ev := event.(type) {
+	case *network.EventRequestWillBeSent:
+		t.inFlight[ev.RequestID] = true
+	case *network.EventLoadingFinished:
+		delete(t.inFlight, ev.RequestID)
+	case *network.EventLoadingFailed:
+		delete(t.inFlight, ev.RequestID)
+	default:
+		return
+	}
+	t.lastChange = time.Now()
+}
+
+// idle reports whether no request has been in flight for at least quiet.
+func (t *networkTracker) idle(quiet time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.inFlight) == 0 && time.Since(t.lastChange) >= quiet
+}
@@ -0,0 +1,401 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"rpa-dfs-engine/internal/logger"
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	"github.com/mailru/easyjson"
+)
+
+// Session is a long-lived browser used to run workflows step by step.
+type Session struct {
+	ctx         context.Context
+	cancelAlloc context.CancelFunc
+	cancelCtx   context.CancelFunc
+	timeout     time.Duration
+	network     *networkTracker
+}
+
+// NewSession starts a visible Chrome instance for workflow execution.
+func NewSession() (*Session, error) {
+	logger.LogInfo("Starting browser session")
+
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("headless", false),
+		chromedp.Flag("disable-gpu", true),
+		chromedp.Flag("no-sandbox", true),
+		chromedp.Flag("disable-dev-shm-usage", true),
+	)
+
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(context.Background(), opts...)
+	ctx, cancelCtx := chromedp.NewContext(allocCtx, chromedp.WithLogf(log.Printf))
+	tracker := newNetworkTracker()
+	chromedp.ListenTarget(ctx, tracker.handle)
+
+	if err := chromedp.Run(ctx); err != nil {
+		cancelCtx()
+		cancelAlloc()
+		return nil, fmt.Errorf("error starting browser: %w", err)
+	}
+
+	return &Session{
+		ctx:         ctx,
+		cancelAlloc: cancelAlloc,
+		cancelCtx:   cancelCtx,
+		timeout:     30 * time.Second,
+		network:     tracker,
+	}, nil
+}
+
+// OpenTab opens a tab for a parallel branch. It shares cookies with this session
+// unless isolated, in which case it gets a fresh browser context.
+func (s *Session) OpenTab(isolated bool) (traverser.Tab, error) {
+	var opts []chromedp.ContextOption
+	if isolated {
+		opts = append(opts, chromedp.WithNewBrowserContext())
+	}
+	ctx, cancel := chromedp.NewContext(s.ctx, opts...)
+	tracker := newNetworkTracker()
+	chromedp.ListenTarget(ctx, tracker.handle)
+
+	if err := chromedp.Run(ctx); err != nil {
+		cancel()
+		return nil, fmt.Errorf("error opening tab: %w", err)
+	}
+	return &Session{
+		ctx:         ctx,
+		cancelAlloc: func() {},
+		cancelCtx:   cancel,
+		timeout:     s.timeout,
+		network:     tracker,
+	}, nil
+}
+
+// SetActionTimeout changes how long a single action may take.
+func (s *Session) SetActionTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		s.timeout = timeout
+	}
+}
+
+// ActionTimeout returns how long a single action may take.
+func (s *Session) ActionTimeout() time.Duration {
+	return s.timeout
+}
+
+func (s *Session) run(actions ...chromedp.Action) error {
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+	return chromedp.Run(ctx, actions...)
+}
+
+// NavigateTo opens url in the current tab.
+func (s *Session) NavigateTo(url string) error {
+	return s.run(chromedp.Navigate(url))
+}
+
+// waitVisible waits for selector and reports traverser.ErrElementNotFound when it never appears.
+func (s *Session) waitVisible(selector string) error {
+	err := s.run(chromedp.WaitVisible(selector, chromedp.ByQuery))
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s", traverser.ErrElementNotFound, selector)
+	}
+	return err
+}
+
+// waitReady waits for selector to exist, visible or not.
+func (s *Session) waitReady(selector string) error {
+	err := s.run(chromedp.WaitReady(selector, chromedp.ByQuery))
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s", traverser.ErrElementNotFound, selector)
+	}
+	return err
+}
+
+// FillField clears the field matched by selector and types value into it.
+func (s *Session) FillField(selector, value string) error {
+	if err := s.waitVisible(selector); err != nil {
+		return err
+	}
+	return s.run(
+		chromedp.Clear(selector, chromedp.ByQuery),
+		chromedp.SendKeys(selector, value, chromedp.ByQuery),
+	)
+}
+
+// ClickButton clicks the element matched by selector.
+func (s *Session) ClickButton(selector string) error {
+	if err := s.waitVisible(selector); err != nil {
+		return err
+	}
+	return s.run(chromedp.Click(selector, chromedp.ByQuery))
+}
+
+// UploadFile sets filePath on the file input matched by selector.
+func (s *Session) UploadFile(selector, filePath string) error {
+	return s.run(chromedp.SetUploadFiles(selector, []string{filePath}, chromedp.ByQuery))
+}
+
+// Text returns the visible text of the element matched by selector.
+func (s *Session) Text(selector string) (string, error) {
+	if err := s.waitVisible(selector); err != nil {
+		return "", err
+	}
+	var text string
+	err := s.run(chromedp.Text(selector, &text, chromedp.ByQuery))
+	return text, err
+}
+
+// Attribute returns an attribute of the element matched by selector.
+func (s *Session) Attribute(selector, name string) (string, error) {
+	if err := s.waitReady(selector); err != nil {
+		return "", err
+	}
+	var value string
+	var ok bool
+	if err := s.run(chromedp.AttributeValue(selector, name, &value, &ok, chromedp.ByQuery)); err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("attribute %q not set on %s", name, selector)
+	}
+	return value, nil
+}
+
+// Value returns the value of the form field matched by selector.
+func (s *Session) Value(selector string) (string, error) {
+	if err := s.waitReady(selector); err != nil {
+		return "", err
+	}
+	var value string
+	err := s.run(chromedp.Value(selector, &value, chromedp.ByQuery))
+	return value, err
+}
+
+// Count returns how many elements currently match selector, without waiting.
+func (s *Session) Count(selector string) (int, error) {
+	quoted, err := json.Marshal(selector)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	err = s.run(chromedp.Evaluate(fmt.Sprintf("document.querySelectorAll(%s).length", quoted), &count))
+	return count, err
+}
+
+// CurrentURL returns the URL of the current tab.
+func (s *Session) CurrentURL() (string, error) {
+	var url string
+	err := s.run(chromedp.Location(&url))
+	return url, err
+}
+
+// Title returns the title of the current page.
+func (s *Session) Title() (string, error) {
+	var title string
+	err := s.run(chromedp.Title(&title))
+	return title, err
+}
+
+// Visible reports whether the first element matched by selector is displayed, without waiting.
+func (s *Session) Visible(selector string) (bool, error) {
+	info, err := s.InspectSelector(selector)
+	if err != nil {
+		return false, err
+	}
+	return info.Count > 0 && info.Visible, nil
+}
+
+// Enabled reports whether the first element matched by selector exists and is not disabled.
+func (s *Session) Enabled(selector string) (bool, error) {
+	info, err := s.InspectSelector(selector)
+	if err != nil {
+		return false, err
+	}
+	return info.Count > 0 && info.Enabled, nil
+}
+
+// Truthy evaluates a JavaScript expression on the page and reports whether the result is truthy.
+// The expression reads args[i]; like Evaluate's, they are sent as protocol values. Promises
+// are awaited.
+func (s *Session) Truthy(script string, args []interface{}) (bool, error) {
+	result, err := s.Evaluate("", fmt.Sprintf("async (...args) => !!(%s)", script), args)
+	if err != nil {
+		return false, err
+	}
+	truthy, _ := result.(bool)
+	return truthy, nil
+}
+
+// NetworkIdle reports whether the tab has had no request in flight for at least quiet.
+func (s *Session) NetworkIdle(quiet time.Duration) (bool, error) {
+	return s.network.idle(quiet), nil
+}
+
+// inspectScript describes the elements matched by a selector; %s is the JSON-quoted selector.
+const inspectScript = `(() => {
+	const all = document.querySelectorAll(%s);
+	const el = all[0];
+	if (!el) return {count: 0};
+	const style = window.getComputedStyle(el);
+	const rect = el.getBoundingClientRect();
+	return {
+		count: all.length,
+		visible: style.display !== "none" && style.visibility !== "hidden" && rect.width > 0 && rect.height > 0,
+		enabled: !el.disabled,
+		tag: el.tagName.toLowerCase(),
+		text: (el.innerText || el.value || "").trim().slice(0, 200),
+	};
+})()`
+
+// InspectSelector reports how many elements selector matches and describes the first one.
+func (s *Session) InspectSelector(selector string) (traverser.SelectorInfo, error) {
+	var info traverser.SelectorInfo
+	quoted, err := json.Marshal(selector)
+	if err != nil {
+		return info, err
+	}
+	err = s.run(chromedp.Evaluate(fmt.Sprintf(inspectScript, quoted), &info))
+	return info, err
+}
+
+// evaluateWrapper calls a user script, given as the source of a function, with the
+// arguments of Runtime.callFunctionOn and converts its awaited result to plain JSON.
+// %s is the script source.
+const evaluateWrapper = `async function(...args) {
+	const fn = (%s);
+	if (typeof fn !== "function") {
+		throw new TypeError("script must be a function, e.g. (arg) => ...");
+	}
+	const json = JSON.stringify(await fn.apply(this, args));
+	return json === undefined ? null : JSON.parse(json);
+}`
+
+// evaluateGroup is the object group the handles used by Evaluate are released with.
+const evaluateGroup = "rpa-evaluate"
+
+// Evaluate calls the JavaScript function script with args on the page, or in the document of
+// the iframe matched by frame, and returns its JSON-decoded result. Arguments are sent as
+// protocol values, never spliced into the source. Promises are awaited; exceptions are
+// returned as *traverser.ScriptError with the JavaScript stack.
+func (s *Session) Evaluate(frame, script string, args []interface{}) (interface{}, error) {
+	arguments := make([]*runtime.CallArgument, len(args))
+	for i, arg := range args {
+		value, err := json.Marshal(arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i+1, err)
+		}
+		arguments[i] = &runtime.CallArgument{Value: easyjson.RawMessage(value)}
+	}
+
+	var frameNodes []cdp.NodeID
+	if frame != "" {
+		if err := s.waitReady(frame); err != nil {
+			return nil, err
+		}
+		if err := s.run(chromedp.NodeIDs(frame, &frameNodes, chromedp.ByQuery)); err != nil {
+			return nil, err
+		}
+	}
+
+	var result interface{}
+	err := s.run(chromedp.ActionFunc(func(ctx context.Context) error {
+		defer func() { _ = runtime.ReleaseObjectGroup(evaluateGroup).Do(ctx) }()
+
+		target, err := s.evaluationTarget(ctx, frame, frameNodes)
+		if err != nil {
+			return err
+		}
+		value, exception, err := runtime.CallFunctionOn(fmt.Sprintf(evaluateWrapper, script)).
+			WithObjectID(target).
+			WithArguments(arguments).
+			WithAwaitPromise(true).
+			WithReturnByValue(true).
+			WithObjectGroup(evaluateGroup).
+			Do(ctx)
+		if err != nil {
+			return err
+		}
+		if exception != nil {
+			return scriptError(exception)
+		}
+		if len(value.Value) == 0 {
+			return nil
+		}
+		return json.Unmarshal(value.Value, &result)
+	}))
+	return result, err
+}
+
+// evaluationTarget returns the object scripts are called on: the page's global object, or
+// the document of the iframe, which makes the script run in that frame.
+func (s *Session) evaluationTarget(ctx context.Context, frame string, frameNodes []cdp.NodeID) (runtime.RemoteObjectID, error) {
+	if frame == "" {
+		global, exception, err := runtime.Evaluate("globalThis").WithObjectGroup(evaluateGroup).Do(ctx)
+		if err != nil {
+			return "", err
+		}
+		if exception != nil {
+			return "", scriptError(exception)
+		}
+		return global.ObjectID, nil
+	}
+
+	if len(frameNodes) == 0 {
+		return "", fmt.Errorf("%w: %s", traverser.ErrElementNotFound, frame)
+	}
+	node, err := dom.DescribeNode().WithNodeID(frameNodes[0]).WithDepth(1).Do(ctx)
+	if err != nil {
+		return "", err
+	}
+	if node.ContentDocument == nil {
+		return "", fmt.Errorf("%s is not a same-process iframe", frame)
+	}
+	document, err := dom.ResolveNode().WithBackendNodeID(node.ContentDocument.BackendNodeID).
+		WithObjectGroup(evaluateGroup).Do(ctx)
+	if err != nil {
+		return "", err
+	}
+	return document.ObjectID, nil
+}
+
+// scriptError converts an exception thrown by page JavaScript. The description of an Error
+// object holds its message followed by the stack.
+func scriptError(exception *runtime.ExceptionDetails) *traverser.ScriptError {
+	err := &traverser.ScriptError{Message: exception.Text}
+	if thrown := exception.Exception; thrown != nil {
+		if thrown.Description != "" {
+			err.Stack = thrown.Description
+			err.Message = strings.SplitN(thrown.Description, "\n", 2)[0]
+		} else if len(thrown.Value) > 0 {
+			err.Message = string(thrown.Value)
+		}
+	}
+	if err.Stack == "" && exception.StackTrace != nil {
+		var stack strings.Builder
+		stack.WriteString(err.Message)
+		for _, frame := range exception.StackTrace.CallFrames {
+			fmt.Fprintf(&stack, "\n    at %s (%s:%d:%d)", frame.FunctionName, frame.URL, frame.LineNumber+1, frame.ColumnNumber+1)
+		}
+		err.Stack = stack.String()
+	}
+	return err
+}
+
+// Close shuts down the browser, or only the tab of a session returned by OpenTab.
+func (s *Session) Close() {
+	s.cancelCtx()
+	s.cancelAlloc()
+}
@@ -5,6 +5,13 @@ import "os"
 var (
 	PROTOCOL_NAME = os.Getenv("PROTOCOL_NAME")
 	SITE_FOR_TEST = os.Getenv("SITE_FOR_TEST")
+	WORKFLOW_PATH = os.Getenv("WORKFLOW_PATH")
+	CONTEXT_PATH  = os.Getenv("CONTEXT_PATH")
+	CONTEXT_ENV   = os.Getenv("CONTEXT_ENV")
+
+	WORKFLOW_CATALOG = os.Getenv("WORKFLOW_CATALOG")
+	RUNS_DIR         = os.Getenv("RUNS_DIR")
+	SECRET_KEY_PATH  = os.Getenv("SECRET_KEY_PATH")
 )
 
 const (
@@ -0,0 +1,461 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EvalError is a runtime error raised while evaluating an expression.
+type EvalError struct {
+	Column  int
+	Message string
+}
+
+func (e *EvalError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Message)
+}
+
+func evalError(pos int, format string, args ...interface{}) *EvalError {
+	return &EvalError{Column: pos + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+// Evaluate computes the value of the expression. References that cannot be
+// resolved evaluate to null.
+func (e *Expression) Evaluate(resolver Resolver) (interface{}, error) {
+	return e.root.eval(resolver)
+}
+
+// EvaluateBool evaluates the expression and requires a boolean result.
+func (e *Expression) EvaluateBool(resolver Resolver) (bool, error) {
+	value, err := e.Evaluate(resolver)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, evalError(0, "expression must evaluate to a boolean, got %s", typeName(value))
+	}
+	return result, nil
+}
+
+type node interface {
+	eval(r Resolver) (interface{}, error)
+}
+
+type literalNode struct {
+	pos   int
+	value interface{}
+}
+
+func (n *literalNode) eval(Resolver) (interface{}, error) {
+	return n.value, nil
+}
+
+type referenceNode struct {
+	pos  int
+	name string
+}
+
+func (n *referenceNode) eval(r Resolver) (interface{}, error) {
+	if r == nil {
+		return nil, nil
+	}
+	value, _ := r.Get(n.name)
+	return normalize(value), nil
+}
+
+type accessNode struct {
+	pos    int
+	target node
+	key    node
+}
+
+func (n *accessNode) eval(r Resolver) (interface{}, error) {
+	target, err := n.target.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	key, err := n.key.eval(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := target.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		name, ok := key.(string)
+		if !ok {
+			return nil, evalError(n.pos, "object key must be a string, got %s", typeName(key))
+		}
+		return normalize(t[name]), nil
+	case []interface{}:
+		index, ok := key.(float64)
+		if !ok || index != math.Trunc(index) {
+			return nil, evalError(n.pos, "array index must be an integer, got %s", typeName(key))
+		}
+		if index < 0 || int(index) >= len(t) {
+			return nil, nil
+		}
+		return normalize(t[int(index)]), nil
+	}
+	return nil, evalError(n.pos, "cannot access property of %s", typeName(target))
+}
+
+type notNode struct {
+	pos     int
+	operand node
+}
+
+func (n *notNode) eval(r Resolver) (interface{}, error) {
+	value, err := n.operand.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return nil, evalError(n.pos, "not requires a boolean, got %s", typeName(value))
+	}
+	return !b, nil
+}
+
+type negateNode struct {
+	pos     int
+	operand node
+}
+
+func (n *negateNode) eval(r Resolver) (interface{}, error) {
+	value, err := n.operand.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	number, ok := value.(float64)
+	if !ok {
+		return nil, evalError(n.pos, "cannot negate %s", typeName(value))
+	}
+	return -number, nil
+}
+
+type logicalNode struct {
+	pos         int
+	op          string
+	left, right node
+}
+
+func (n *logicalNode) eval(r Resolver) (interface{}, error) {
+	left, err := n.left.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	l, ok := left.(bool)
+	if !ok {
+		return nil, evalError(n.pos, "%s requires booleans, got %s", n.op, typeName(left))
+	}
+	if (n.op == "&&" && !l) || (n.op == "||" && l) {
+		return l, nil
+	}
+
+	right, err := n.right.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	rb, ok := right.(bool)
+	if !ok {
+		return nil, evalError(n.pos, "%s requires booleans, got %s", n.op, typeName(right))
+	}
+	return rb, nil
+}
+
+type compareNode struct {
+	pos         int
+	op          string
+	left, right node
+}
+
+func (n *compareNode) eval(r Resolver) (interface{}, error) {
+	left, err := n.left.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	}
+
+	switch l := left.(type) {
+	case float64:
+		if rn, ok := right.(float64); ok {
+			return compareOrdered(n.op, l < rn, l == rn), nil
+		}
+	case string:
+		if rs, ok := right.(string); ok {
+			return compareOrdered(n.op, l < rs, l == rs), nil
+		}
+	}
+	return nil, evalError(n.pos, "cannot compare %s %s %s", typeName(left), n.op, typeName(right))
+}
+
+func compareOrdered(op string, less, equal bool) bool {
+	switch op {
+	case "<":
+		return less
+	case "<=":
+		return less || equal
+	case ">":
+		return !less && !equal
+	case ">=":
+		return !less
+	}
+	return false
+}
+
+type arithmeticNode struct {
+	pos         int
+	op          string
+	left, right node
+}
+
+func (n *arithmeticNode) eval(r Resolver) (interface{}, error) {
+	left, err := n.left.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "+" {
+		if ls, ok := left.(string); ok {
+			if rs, ok := right.(string); ok {
+				return ls + rs, nil
+			}
+		}
+	}
+
+	l, lok := left.(float64)
+	rn, rok := right.(float64)
+	if !lok || !rok {
+		return nil, evalError(n.pos, "cannot apply %s to %s and %s", n.op, typeName(left), typeName(right))
+	}
+
+	switch n.op {
+	case "+":
+		return l + rn, nil
+	case "-":
+		return l - rn, nil
+	case "*":
+		return l * rn, nil
+	case "/":
+		if rn == 0 {
+			return nil, evalError(n.pos, "division by zero")
+		}
+		return l / rn, nil
+	case "%":
+		if rn == 0 {
+			return nil, evalError(n.pos, "division by zero")
+		}
+		return math.Mod(l, rn), nil
+	}
+	return nil, evalError(n.pos, "unknown operator %s", n.op)
+}
+
+type function struct {
+	arity int
+	call  func(n *callNode, args []interface{}) (interface{}, error)
+}
+
+type callNode struct {
+	pos   int
+	name  string
+	fn    function
+	args  []node
+	regex *regexp.Regexp
+}
+
+func (n *callNode) eval(r Resolver) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(r)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	return n.fn.call(n, args)
+}
+
+// functions is the complete set of callable functions. Expressions cannot reach anything else.
+var functions = map[string]function{
+	"contains": {arity: 2, call: func(n *callNode, args []interface{}) (interface{}, error) {
+		switch haystack := args[0].(type) {
+		case nil:
+			return false, nil
+		case string:
+			needle, ok := args[1].(string)
+			if !ok {
+				return nil, evalError(n.pos, "contains on a string requires a string, got %s", typeName(args[1]))
+			}
+			return strings.Contains(haystack, needle), nil
+		case []interface{}:
+			for _, item := range haystack {
+				if equal(normalize(item), args[1]) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+		return nil, evalError(n.pos, "contains requires a string or array, got %s", typeName(args[0]))
+	}},
+	"startsWith": {arity: 2, call: func(n *callNode, args []interface{}) (interface{}, error) {
+		s, prefix, err := stringArgs(n, args)
+		if err != nil {
+			return nil, err
+		}
+		return strings.HasPrefix(s, prefix), nil
+	}},
+	"endsWith": {arity: 2, call: func(n *callNode, args []interface{}) (interface{}, error) {
+		s, suffix, err := stringArgs(n, args)
+		if err != nil {
+			return nil, err
+		}
+		return strings.HasSuffix(s, suffix), nil
+	}},
+	"matches": {arity: 2, call: func(n *callNode, args []interface{}) (interface{}, error) {
+		s, pattern, err := stringArgs(n, args)
+		if err != nil {
+			return nil, err
+		}
+		re := n.regex
+		if re == nil {
+			if re, err = regexp.Compile(pattern); err != nil {
+				return nil, evalError(n.pos, "invalid regular expression: %v", err)
+			}
+		}
+		return re.MatchString(s), nil
+	}},
+	"len": {arity: 1, call: func(n *callNode, args []interface{}) (interface{}, error) {
+		switch v := args[0].(type) {
+		case nil:
+			return float64(0), nil
+		case string:
+			return float64(len([]rune(v))), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		case map[string]interface{}:
+			return float64(len(v)), nil
+		}
+		return nil, evalError(n.pos, "len requires a string, array or object, got %s", typeName(args[0]))
+	}},
+	"lower": {arity: 1, call: func(n *callNode, args []interface{}) (interface{}, error) {
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, evalError(n.pos, "lower requires a string, got %s", typeName(args[0]))
+		}
+		return strings.ToLower(s), nil
+	}},
+	"upper": {arity: 1, call: func(n *callNode, args []interface{}) (interface{}, error) {
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, evalError(n.pos, "upper requires a string, got %s", typeName(args[0]))
+		}
+		return strings.ToUpper(s), nil
+	}},
+	"trim": {arity: 1, call: func(n *callNode, args []interface{}) (interface{}, error) {
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, evalError(n.pos, "trim requires a string, got %s", typeName(args[0]))
+		}
+		return strings.TrimSpace(s), nil
+	}},
+	"isNull": {arity: 1, call: func(n *callNode, args []interface{}) (interface{}, error) {
+		return args[0] == nil, nil
+	}},
+	"number": {arity: 1, call: func(n *callNode, args []interface{}) (interface{}, error) {
+		switch v := args[0].(type) {
+		case float64:
+			return v, nil
+		case string:
+			number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, evalError(n.pos, "cannot convert %q to number", v)
+			}
+			return number, nil
+		}
+		return nil, evalError(n.pos, "cannot convert %s to number", typeName(args[0]))
+	}},
+	"string": {arity: 1, call: func(n *callNode, args []interface{}) (interface{}, error) {
+		switch v := args[0].(type) {
+		case nil:
+			return "", nil
+		case string:
+			return v, nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+		return nil, evalError(n.pos, "cannot convert %s to string", typeName(args[0]))
+	}},
+}
+
+func stringArgs(n *callNode, args []interface{}) (string, string, error) {
+	a, aok := args[0].(string)
+	b, bok := args[1].(string)
+	if !aok || !bok {
+		return "", "", evalError(n.pos, "%s requires strings, got %s and %s", n.name, typeName(args[0]), typeName(args[1]))
+	}
+	return a, b, nil
+}
+
+// equal compares scalars by type and value; arrays and objects are never equal.
+func equal(a, b interface{}) bool {
+	switch a.(type) {
+	case nil, float64, string, bool:
+		return a == b
+	}
+	return false
+}
+
+// normalize converts Go numeric types to float64 so all numbers compare alike.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case float32:
+		return float64(v)
+	}
+	return value
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
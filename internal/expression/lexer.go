@@ -0,0 +1,158 @@
+package expression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOperator
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+	tokComma
+	tokDot
+	tokOpenTemplate
+	tokCloseTemplate
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value interface{}
+	pos   int
+}
+
+// ParseError is a syntax error at a 1-based column of the expression source.
+type ParseError struct {
+	Column  int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Message)
+}
+
+func errorAt(pos int, format string, args ...interface{}) *ParseError {
+	return &ParseError{Column: pos + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+var twoCharOperators = []string{"==", "!=", "<=", ">=", "&&", "||"}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		ch := source[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		case strings.HasPrefix(source[i:], "{{"):
+			tokens = append(tokens, token{kind: tokOpenTemplate, text: "{{", pos: i})
+			i += 2
+		case strings.HasPrefix(source[i:], "}}"):
+			tokens = append(tokens, token{kind: tokCloseTemplate, text: "}}", pos: i})
+			i += 2
+		case isDigit(ch):
+			start := i
+			for i < len(source) && (isDigit(source[i]) || source[i] == '.') {
+				i++
+			}
+			text := source[start:i]
+			number, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, errorAt(start, "invalid number %q", text)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, value: number, pos: start})
+		case ch == '"' || ch == '\'':
+			text, end, err := readString(source, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokString, text: source[i:end], value: text, pos: i})
+			i = end
+		case isIdentStart(ch):
+			start := i
+			for i < len(source) && isIdentPart(source[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: source[start:i], pos: start})
+		default:
+			kind, text := tokOperator, ""
+			for _, op := range twoCharOperators {
+				if strings.HasPrefix(source[i:], op) {
+					text = op
+					break
+				}
+			}
+			if text == "" {
+				text = string(ch)
+				switch ch {
+				case '(':
+					kind = tokLParen
+				case ')':
+					kind = tokRParen
+				case '[':
+					kind = tokLBracket
+				case ']':
+					kind = tokRBracket
+				case ',':
+					kind = tokComma
+				case '.':
+					kind = tokDot
+				case '<', '>', '+', '-', '*', '/', '%', '!':
+				default:
+					return nil, errorAt(i, "unexpected character %q", ch)
+				}
+			}
+			tokens = append(tokens, token{kind: kind, text: text, pos: i})
+			i += len(text)
+		}
+	}
+	tokens = append(tokens, token{kind: tokEOF, pos: len(source)})
+	return tokens, nil
+}
+
+func readString(source string, start int) (string, int, error) {
+	quote := source[start]
+	var sb strings.Builder
+	for i := start + 1; i < len(source); i++ {
+		ch := source[i]
+		switch {
+		case ch == '\\' && i+1 < len(source):
+			i++
+			switch source[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(source[i])
+			}
+		case ch == quote:
+			return sb.String(), i + 1, nil
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+	return "", 0, errorAt(start, "unterminated string")
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isIdentStart(ch byte) bool {
+	return ch == '_' || ch == '$' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isIdentPart(ch byte) bool {
+	return isIdentStart(ch) || isDigit(ch)
+}
@@ -0,0 +1,375 @@
+package expression
+
+import (
+	"regexp"
+)
+
+// Resolver looks up context paths such as "user" or "iterator".
+type Resolver interface {
+	Get(path string) (interface{}, bool)
+}
+
+// Expression is a parsed, reusable expression.
+type Expression struct {
+	source string
+	root   node
+}
+
+// Parse compiles source into an Expression. Template braces are allowed around
+// references, so "{{user.age}} > 18" and "user.age > 18" are equivalent.
+func Parse(source string) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, errorAt(tok.pos, "unexpected %q", tok.text)
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// Source returns the original expression text.
+func (e *Expression) Source() string {
+	return e.source
+}
+
+// References returns the context paths the expression reads, in order of appearance.
+// Property access is followed as far as it is literal, so "user.items[iterator.index].name"
+// reads "user.items" and "iterator.index".
+func (e *Expression) References() []string {
+	var paths []string
+	collectReferences(e.root, &paths)
+	return paths
+}
+
+func collectReferences(n node, paths *[]string) {
+	switch n := n.(type) {
+	case *referenceNode, *accessNode:
+		path, dynamic := referencePath(n)
+		if path != "" {
+			*paths = append(*paths, path)
+		}
+		for _, key := range dynamic {
+			collectReferences(key, paths)
+		}
+	case *notNode:
+		collectReferences(n.operand, paths)
+	case *negateNode:
+		collectReferences(n.operand, paths)
+	case *logicalNode:
+		collectReferences(n.left, paths)
+		collectReferences(n.right, paths)
+	case *compareNode:
+		collectReferences(n.left, paths)
+		collectReferences(n.right, paths)
+	case *arithmeticNode:
+		collectReferences(n.left, paths)
+		collectReferences(n.right, paths)
+	case *callNode:
+		for _, arg := range n.args {
+			collectReferences(arg, paths)
+		}
+	}
+}
+
+// referencePath returns the literal path of a reference chain and the keys computed at run time.
+// The path stops at the first computed key.
+func referencePath(n node) (string, []node) {
+	switch n := n.(type) {
+	case *referenceNode:
+		return n.name, nil
+	case *accessNode:
+		path, dynamic := referencePath(n.target)
+		if len(dynamic) == 0 && path != "" {
+			if key, ok := n.key.(*literalNode); ok {
+				if name, ok := key.value.(string); ok {
+					return path + "." + name, nil
+				}
+			}
+		}
+		return path, append(dynamic, n.key)
+	}
+	var dynamic []node
+	if n != nil {
+		dynamic = append(dynamic, n)
+	}
+	return "", dynamic
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// matchOperator consumes the next token if it is one of the given operators or keywords.
+func (p *parser) matchOperator(ops ...string) (token, bool) {
+	tok := p.peek()
+	if tok.kind != tokOperator && tok.kind != tokIdent {
+		return tok, false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			return p.advance(), true
+		}
+	}
+	return tok, false
+}
+
+func (p *parser) expect(kind tokenKind, text string) (token, error) {
+	tok := p.peek()
+	if tok.kind != kind {
+		if tok.kind == tokEOF {
+			return tok, errorAt(tok.pos, "expected %q, found end of expression", text)
+		}
+		return tok, errorAt(tok.pos, "expected %q, found %q", text, tok.text)
+	}
+	return p.advance(), nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.matchOperator("||", "or")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{pos: tok.pos, op: "||", left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.matchOperator("&&", "and")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{pos: tok.pos, op: "&&", left: left, right: right}
+	}
+}
+
+func (p *parser) parseNot() (node, error) {
+	if tok, ok := p.matchOperator("!", "not"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{pos: tok.pos, operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	tok, ok := p.matchOperator("==", "!=", "<", "<=", ">", ">=")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	return &compareNode{pos: tok.pos, op: tok.text, left: left, right: right}, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.matchOperator("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &arithmeticNode{pos: tok.pos, op: tok.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.matchOperator("*", "/", "%")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &arithmeticNode{pos: tok.pos, op: tok.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if tok, ok := p.matchOperator("-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &negateNode{pos: tok.pos, operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	target, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch tok := p.peek(); tok.kind {
+		case tokDot:
+			p.advance()
+			name, err := p.expect(tokIdent, "property name")
+			if err != nil {
+				return nil, err
+			}
+			target = &accessNode{pos: tok.pos, target: target, key: &literalNode{pos: name.pos, value: name.text}}
+		case tokLBracket:
+			p.advance()
+			key, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(tokRBracket, "]"); err != nil {
+				return nil, err
+			}
+			target = &accessNode{pos: tok.pos, target: target, key: key}
+		default:
+			return target, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.advance()
+	switch tok.kind {
+	case tokNumber, tokString:
+		return &literalNode{pos: tok.pos, value: tok.value}, nil
+	case tokLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, ")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	case tokOpenTemplate:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokCloseTemplate, "}}"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			return &literalNode{pos: tok.pos, value: true}, nil
+		case "false":
+			return &literalNode{pos: tok.pos, value: false}, nil
+		case "null":
+			return &literalNode{pos: tok.pos, value: nil}, nil
+		case "and", "or", "not":
+			return nil, errorAt(tok.pos, "unexpected %q", tok.text)
+		}
+		if p.peek().kind == tokLParen {
+			return p.parseCall(tok)
+		}
+		return &referenceNode{pos: tok.pos, name: tok.text}, nil
+	case tokEOF:
+		return nil, errorAt(tok.pos, "unexpected end of expression")
+	}
+	return nil, errorAt(tok.pos, "unexpected %q", tok.text)
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, errorAt(name.pos, "unknown function %q", name.text)
+	}
+	p.advance()
+
+	var args []node
+	if p.peek().kind != tokRParen {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.advance()
+		}
+	}
+	if _, err := p.expect(tokRParen, ")"); err != nil {
+		return nil, err
+	}
+
+	if len(args) != fn.arity {
+		return nil, errorAt(name.pos, "%s expects %d arguments, got %d", name.text, fn.arity, len(args))
+	}
+
+	call := &callNode{pos: name.pos, name: name.text, fn: fn, args: args}
+	if name.text == "matches" {
+		if pattern, ok := args[1].(*literalNode); ok {
+			text, isString := pattern.value.(string)
+			if !isString {
+				return nil, errorAt(pattern.pos, "matches pattern must be a string")
+			}
+			re, err := regexp.Compile(text)
+			if err != nil {
+				return nil, errorAt(pattern.pos, "invalid regular expression: %v", err)
+			}
+			call.regex = re
+		}
+	}
+	return call, nil
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"rpa-dfs-engine/internal/config"
+	"rpa-dfs-engine/internal/logger"
+	"rpa-dfs-engine/internal/traverser"
+)
+
+const contextUsage = "usage: context show [--context file|json] [--env name] [--use-env] " +
+	"[--env-map PREFIX=path]... [--set path=value]... [--format text|json]"
+
+// shownSecret replaces encrypted and secret-looking values in "context show".
+const shownSecret = "********"
+
+// stringList is a flag that may be repeated.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// contextFlags are the context layer flags shared by run, debug and context show.
+type contextFlags struct {
+	context string
+	env     string
+	useEnv  bool
+	envMap  stringList
+	sets    stringList
+}
+
+func addContextFlags(fs *flag.FlagSet) *contextFlags {
+	f := &contextFlags{}
+	fs.StringVar(&f.context, "context", config.CONTEXT_PATH, "user context file, or inline JSON")
+	fs.StringVar(&f.env, "env", config.CONTEXT_ENV, "environment whose context file is merged over the base, e.g. staging")
+	fs.BoolVar(&f.useEnv, "use-env", false, "map "+traverser.DefaultEnvPrefix+"* environment variables to user.*")
+	fs.Var(&f.envMap, "env-map", "map environment variables with a prefix to a path, e.g. ACME_=user.acme (repeatable)")
+	fs.Var(&f.sets, "set", "override a value, e.g. user.email=ada@example.com (repeatable)")
+	return f
+}
+
+// sources returns the layers selected by the flags.
+func (f *contextFlags) sources() (traverser.ContextSources, error) {
+	sources := traverser.ContextSources{Context: f.context, Env: f.env, Sets: f.sets}
+	if f.useEnv || len(f.envMap) > 0 {
+		sources.EnvMap = make(map[string]string)
+	}
+	if f.useEnv {
+		sources.EnvMap[traverser.DefaultEnvPrefix] = "user"
+	}
+	for _, mapping := range f.envMap {
+		prefix, path, ok := strings.Cut(mapping, "=")
+		if !ok || prefix == "" || path == "" {
+			return sources, fmt.Errorf("invalid --env-map %q: expected PREFIX=path", mapping)
+		}
+		sources.EnvMap[prefix] = path
+	}
+	return sources, nil
+}
+
+// load merges the context layers and returns the user scope.
+func (f *contextFlags) load() (map[string]interface{}, error) {
+	sources, err := f.sources()
+	if err != nil {
+		return nil, err
+	}
+	loaded, err := traverser.LoadContext(sources)
+	if err != nil {
+		return nil, err
+	}
+	return loaded.User, nil
+}
+
+// ContextHandler prints the context a run would get and where each value came from.
+type ContextHandler struct {
+	action   string
+	context  *contextFlags
+	format   string
+	output   io.Writer
+	parseErr error
+}
+
+// contextEntry is one value in --format json output.
+type contextEntry struct {
+	Path   string      `json:"path"`
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+}
+
+// NewContextHandler creates a handler for "context show [--context file|json] [--env name]
+// [--use-env] [--env-map PREFIX=path]... [--set path=value]... [--format text|json]".
+func NewContextHandler(args []string) Handler {
+	h := &ContextHandler{output: os.Stdout}
+	if len(args) == 0 {
+		return h
+	}
+	h.action = args[0]
+
+	fs := flag.NewFlagSet("context "+h.action, flag.ContinueOnError)
+	h.context = addContextFlags(fs)
+	format := fs.String("format", "text", "output format: text or json")
+	h.parseErr = fs.Parse(args[1:])
+
+	h.format = *format
+	return h
+}
+
+// Execute merges the context layers and prints every value with its source. Encrypted
+// and secret-looking values are masked.
+func (h *ContextHandler) Execute() error {
+	logger.LogInfo("=== RPA DFS Engine - Context Mode ===")
+
+	if h.parseErr != nil {
+		return parseResult(h.parseErr)
+	}
+
+	if h.action != "show" {
+		return errors.New(contextUsage)
+	}
+	if h.format != "text" && h.format != "json" {
+		return fmt.Errorf("unknown format %q: use text or json", h.format)
+	}
+
+	sources, err := h.context.sources()
+	if err != nil {
+		return err
+	}
+	loaded, err := traverser.LoadContext(sources)
+	if err != nil {
+		fmt.Fprintf(h.output, "❌ %v\n", err)
+		return err
+	}
+	ctx := traverser.NewContext(loaded.User)
+
+	entries := make([]contextEntry, 0, len(loaded.Sources))
+	for _, path := range loaded.Paths() {
+		value, _ := ctx.Get(path)
+		if _, encrypted := value.(traverser.Secret); encrypted || traverser.IsSecretPath(path) {
+			value = shownSecret
+		}
+		entries = append(entries, contextEntry{Path: path, Value: value, Source: loaded.Sources[path]})
+	}
+
+	if h.format == "json" {
+		enc := json.NewEncoder(h.output)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintln(h.output, "📝 the context is empty")
+		return nil
+	}
+	pathWidth, valueWidth := 0, 0
+	values := make([]string, len(entries))
+	for i, entry := range entries {
+		values[i] = formatContextValue(entry.Value)
+		pathWidth = max(pathWidth, len(entry.Path))
+		valueWidth = max(valueWidth, len(values[i]))
+	}
+	for i, entry := range entries {
+		fmt.Fprintf(h.output, "%-*s  %-*s  ← %s\n", pathWidth, entry.Path, valueWidth, values[i], entry.Source)
+	}
+	return nil
+}
+
+func formatContextValue(value interface{}) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// GetDescription implements the Handler interface
+func (h *ContextHandler) GetDescription() string {
+	return "Shows the merged user context and where each value came from"
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"rpa-dfs-engine/internal/logger"
+	"rpa-dfs-engine/internal/traverser"
+)
+
+// ConvertHandler translates workflow and context files between JSON and YAML.
+type ConvertHandler struct {
+	inputPath  string
+	outputPath string
+	to         string
+	output     io.Writer
+	parseErr   error
+}
+
+// NewConvertHandler creates a handler for "convert [--to json|yaml] <input> [output]".
+// The target format defaults to the extension of output, or to the other format than input.
+// Without an output file the result is printed.
+func NewConvertHandler(args []string) Handler {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	to := fs.String("to", "", "target format: json or yaml")
+	parseErr := fs.Parse(args)
+
+	return &ConvertHandler{
+		inputPath:  fs.Arg(0),
+		outputPath: fs.Arg(1),
+		to:         *to,
+		output:     os.Stdout,
+		parseErr:   parseErr,
+	}
+}
+
+// Execute converts the input file and writes or prints the result.
+func (h *ConvertHandler) Execute() error {
+	logger.LogInfo("=== RPA DFS Engine - Convert Mode ===")
+
+	if h.parseErr != nil {
+		return parseResult(h.parseErr)
+	}
+
+	if h.inputPath == "" {
+		return fmt.Errorf("usage: convert [--to json|yaml] <input> [output]")
+	}
+
+	data, err := os.ReadFile(h.inputPath)
+	if err != nil {
+		return err
+	}
+
+	toYAML, err := h.targetIsYAML()
+	if err != nil {
+		return err
+	}
+	switch {
+	case toYAML && !traverser.IsYAML(h.inputPath):
+		data, err = traverser.JSONToYAML(data)
+	case !toYAML && traverser.IsYAML(h.inputPath):
+		data, err = traverser.YAMLToJSON(data)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", h.inputPath, err)
+	}
+
+	if h.outputPath == "" {
+		_, err = h.output.Write(data)
+		return err
+	}
+	if err := os.WriteFile(h.outputPath, data, 0o644); err != nil {
+		return err
+	}
+	logger.LogSuccess("Converted %s to %s", h.inputPath, h.outputPath)
+	fmt.Fprintf(h.output, "✅ %s → %s\n", h.inputPath, h.outputPath)
+	return nil
+}
+
+func (h *ConvertHandler) targetIsYAML() (bool, error) {
+	switch h.to {
+	case "yaml", "yml":
+		return true, nil
+	case "json":
+		return false, nil
+	case "":
+		if h.outputPath != "" {
+			return traverser.IsYAML(h.outputPath), nil
+		}
+		return !traverser.IsYAML(h.inputPath), nil
+	}
+	return false, fmt.Errorf("unknown format %q: use json or yaml", h.to)
+}
+
+// GetDescription implements the Handler interface
+func (h *ConvertHandler) GetDescription() string {
+	return "Converts workflow and context files between JSON and YAML"
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"rpa-dfs-engine/internal/config"
+	"rpa-dfs-engine/internal/logger"
+	"rpa-dfs-engine/internal/traverser"
+)
+
+// GraphHandler renders a workflow as a Mermaid flowchart or a Graphviz DOT file.
+type GraphHandler struct {
+	workflowPath string
+	format       string
+	run          string
+	outputPath   string
+	output       io.Writer
+	parseErr     error
+}
+
+// NewGraphHandler creates a handler for "graph [--format mermaid|dot] [--run run-id|result.json]
+// [-o file] <workflow>". The format defaults to the extension of the output file (.dot or .gv
+// for DOT), otherwise Mermaid. Without an output file the diagram is printed.
+func NewGraphHandler(args []string) Handler {
+	fs := flag.NewFlagSet("graph", flag.ContinueOnError)
+	format := fs.String("format", "", "diagram format: mermaid or dot")
+	run := fs.String("run", "", "overlay the executed path of a run, by run id or result file")
+	outputPath := fs.String("o", "", "write the diagram to this file")
+	parseErr := fs.Parse(args)
+
+	return &GraphHandler{
+		workflowPath: fs.Arg(0),
+		format:       *format,
+		run:          *run,
+		outputPath:   *outputPath,
+		output:       os.Stdout,
+		parseErr:     parseErr,
+	}
+}
+
+// Execute loads the workflow and the optional run result and writes the diagram.
+func (h *GraphHandler) Execute() error {
+	logger.LogInfo("=== RPA DFS Engine - Graph Mode ===")
+
+	if h.parseErr != nil {
+		return parseResult(h.parseErr)
+	}
+
+	if h.workflowPath == "" {
+		return fmt.Errorf("usage: graph [--format mermaid|dot] [--run run-id|result.json] [-o file] <workflow>")
+	}
+
+	workflow, err := traverser.LoadWorkflow(h.workflowPath)
+	if err != nil {
+		return err
+	}
+
+	var run *traverser.RunResult
+	if h.run != "" {
+		if run, err = traverser.LoadRunResult(h.resultPath()); err != nil {
+			return err
+		}
+		if run.Workflow != workflow.Name() {
+			logger.LogWarning("Run %s executed %q, not %q", run.RunID, run.Workflow, workflow.Name())
+		}
+	}
+
+	diagram, err := workflow.RenderGraph(h.diagramFormat(), run)
+	if err != nil {
+		return err
+	}
+
+	if h.outputPath == "" {
+		_, err = io.WriteString(h.output, diagram)
+		return err
+	}
+	if err := os.WriteFile(h.outputPath, []byte(diagram), 0o644); err != nil {
+		return err
+	}
+	logger.LogSuccess("Graph written to %s", h.outputPath)
+	fmt.Fprintf(h.output, "✅ %s → %s\n", h.workflowPath, h.outputPath)
+	return nil
+}
+
+// resultPath accepts a result file, or a run id looked up under RUNS_DIR.
+func (h *GraphHandler) resultPath() string {
+	if _, err := os.Stat(h.run); err == nil {
+		return h.run
+	}
+	return traverser.ResultPath(config.RUNS_DIR, h.run)
+}
+
+func (h *GraphHandler) diagramFormat() string {
+	if h.format != "" {
+		return h.format
+	}
+	switch strings.ToLower(filepath.Ext(h.outputPath)) {
+	case ".dot", ".gv":
+		return traverser.GraphDOT
+	}
+	return traverser.GraphMermaid
+}
+
+// GetDescription implements the Handler interface
+func (h *GraphHandler) GetDescription() string {
+	return "Renders a workflow as a Mermaid or Graphviz diagram"
+}
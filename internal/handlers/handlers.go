@@ -1,19 +1,41 @@
 package handlers
 
 import (
+	"errors"
+	"flag"
 	"net/url"
 	"os"
 	"strings"
 
 	"rpa-dfs-engine/internal/config"
 	"rpa-dfs-engine/internal/logger"
+	"rpa-dfs-engine/internal/traverser"
 )
 
 var protocolPrefix = config.PROTOCOL_NAME + "://"
 
+// commands maps command-line subcommands to their handler constructors.
+// Each constructor receives the arguments following the subcommand name.
+var commands = map[string]func(args []string) Handler{
+	"validate": NewValidateHandler,
+	"resume":   NewResumeHandler,
+	"run":      NewRunHandler,
+	"debug":    NewDebugHandler,
+	"migrate":  NewMigrateHandler,
+	"convert":  NewConvertHandler,
+	"graph":    NewGraphHandler,
+	"lint":     NewLintHandler,
+	"secret":   NewSecretHandler,
+	"context":  NewContextHandler,
+}
+
 func GetHandler() Handler {
 	args := os.Args
-	logger.LogInfo("args: %v", args)
+	logger.LogInfo("args: %v", logger.RedactArgs(args))
+	if command, ok := getCommand(args); ok {
+		return command(args[2:])
+	}
+
 	if !isProtocolCall(args) {
 		return NewSetupHandler()
 	}
@@ -27,16 +49,24 @@ func GetHandler() Handler {
 	logger.LogInfo("Parsed query parameters: %v", query)
 
 	if isTestMode(query) {
-		return NewTestHandler()
+		return NewTestHandler(query)
 	}
 
 	if hasEmailAndToken(query) {
-		return NewProcessHandler()
+		return NewProcessHandler(query)
 	}
 
 	return NewSetupHandler()
 }
 
+func getCommand(args []string) (func(args []string) Handler, bool) {
+	if len(args) < 2 {
+		return nil, false
+	}
+	command, ok := commands[args[1]]
+	return command, ok
+}
+
 func isProtocolCall(args []string) bool {
 	return len(args) > 1 && strings.HasPrefix(args[1], protocolPrefix)
 }
@@ -58,3 +88,23 @@ func hasEmailAndToken(query url.Values) bool {
 	token := query.Get("token")
 	return email != "" && token != ""
 }
+
+// parseResult is what Execute returns when parsing the command's flags failed: nil for
+// -h, whose usage the flag set has already printed, otherwise the parse error.
+func parseResult(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
+
+// protocolWorkflow returns the workflow a protocol call runs: the catalog workflow named by
+// the "workflow" query parameter, or WORKFLOW_PATH. Any web page can open a protocol URL,
+// so the parameter is never used as a file path.
+func protocolWorkflow(query url.Values) (string, error) {
+	name := query.Get("workflow")
+	if name == "" {
+		return config.WORKFLOW_PATH, nil
+	}
+	return traverser.CatalogPath(config.WORKFLOW_CATALOG, name)
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"rpa-dfs-engine/internal/logger"
+	"rpa-dfs-engine/internal/traverser"
+)
+
+// LintHandler reports problems in workflow files that the schema allows but that are
+// likely mistakes, such as duplicate ids, missing branches and fragile selectors.
+type LintHandler struct {
+	paths       []string
+	contextPath string
+	configPath  string
+	format      string
+	listRules   bool
+	output      io.Writer
+	parseErr    error
+}
+
+// lintReport is the result for one file in --format json output.
+type lintReport struct {
+	File   string                `json:"file"`
+	Error  string                `json:"error,omitempty"`
+	Issues []traverser.LintIssue `json:"issues"`
+}
+
+// NewLintHandler creates a handler for "lint [--context file] [--config file]
+// [--format text|json] [--rules] <workflow>...".
+func NewLintHandler(args []string) Handler {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	contextPath := fs.String("context", "", "check user.* references against this context file")
+	configPath := fs.String("config", "", "rule severities, e.g. {\"rules\": {\"fragile-selector\": \"error\"}}")
+	format := fs.String("format", "text", "output format: text or json")
+	listRules := fs.Bool("rules", false, "list the rules and their default severities")
+	parseErr := fs.Parse(args)
+
+	return &LintHandler{
+		paths:       fs.Args(),
+		contextPath: *contextPath,
+		configPath:  *configPath,
+		format:      *format,
+		listRules:   *listRules,
+		output:      os.Stdout,
+		parseErr:    parseErr,
+	}
+}
+
+// Execute lints every given workflow. It fails when a file cannot be read or a problem
+// with error severity is found; warnings and infos are only reported.
+func (h *LintHandler) Execute() error {
+	logger.LogInfo("=== RPA DFS Engine - Lint Mode ===")
+
+	if h.parseErr != nil {
+		return parseResult(h.parseErr)
+	}
+
+	if h.listRules {
+		for _, rule := range traverser.LintRules() {
+			fmt.Fprintf(h.output, "%-20s %-8s %s\n", rule.Name, rule.Severity, rule.Description)
+		}
+		return nil
+	}
+	if len(h.paths) == 0 {
+		return fmt.Errorf("usage: lint [--context file] [--config file] [--format text|json] [--rules] <workflow>...")
+	}
+	if h.format != "text" && h.format != "json" {
+		return fmt.Errorf("unknown format %q: use text or json", h.format)
+	}
+
+	var config *traverser.LintConfig
+	if h.configPath != "" {
+		var err error
+		if config, err = traverser.LoadLintConfig(h.configPath); err != nil {
+			return err
+		}
+	}
+	var userData map[string]interface{}
+	if h.contextPath != "" {
+		var err error
+		if userData, err = traverser.LoadContextFile(h.contextPath); err != nil {
+			return err
+		}
+	}
+
+	reports := make([]lintReport, 0, len(h.paths))
+	failed := false
+	for _, path := range h.paths {
+		report := h.lint(path, config, userData)
+		if report.Error != "" || countSeverity(report.Issues, traverser.LintError) > 0 {
+			failed = true
+		}
+		reports = append(reports, report)
+	}
+
+	if h.format == "json" {
+		enc := json.NewEncoder(h.output)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			return err
+		}
+	} else {
+		for _, report := range reports {
+			h.printReport(report)
+		}
+	}
+
+	if failed {
+		return errors.New("lint failed")
+	}
+	return nil
+}
+
+func (h *LintHandler) lint(path string, config *traverser.LintConfig, userData map[string]interface{}) lintReport {
+	report := lintReport{File: path, Issues: []traverser.LintIssue{}}
+	data, err := os.ReadFile(path)
+	if err == nil {
+		lint := traverser.LintWorkflow
+		if traverser.IsYAML(path) {
+			lint = traverser.LintWorkflowYAML
+		}
+		var issues []traverser.LintIssue
+		if issues, err = lint(data, config, userData); issues != nil {
+			report.Issues = issues
+		}
+	}
+	if err != nil {
+		report.Error = err.Error()
+		logger.LogError("Lint failed: %s: %v", path, err)
+	}
+	return report
+}
+
+func (h *LintHandler) printReport(report lintReport) {
+	if report.Error != "" {
+		fmt.Fprintf(h.output, "❌ %s: %s\n", report.File, report.Error)
+		return
+	}
+
+	errs := countSeverity(report.Issues, traverser.LintError)
+	warnings := countSeverity(report.Issues, traverser.LintWarning)
+	infos := len(report.Issues) - errs - warnings
+	switch {
+	case errs > 0:
+		fmt.Fprintf(h.output, "❌ %s: %d errors, %d warnings, %d infos\n", report.File, errs, warnings, infos)
+	case len(report.Issues) > 0:
+		fmt.Fprintf(h.output, "⚠️ %s: %d warnings, %d infos\n", report.File, warnings, infos)
+	default:
+		fmt.Fprintf(h.output, "✅ %s: no problems\n", report.File)
+		logger.LogSuccess("Workflow lint clean: %s", report.File)
+		return
+	}
+	for _, issue := range report.Issues {
+		fmt.Fprintf(h.output, "  %s:%d:%d %s: %s [%s]\n",
+			report.File, issue.Line, issue.Column, issue.Severity, issue.Message, issue.Rule)
+	}
+	logger.LogWarning("Workflow lint: %s (%d errors, %d warnings, %d infos)", report.File, errs, warnings, infos)
+}
+
+func countSeverity(issues []traverser.LintIssue, severity string) int {
+	count := 0
+	for _, issue := range issues {
+		if issue.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// GetDescription implements the Handler interface
+func (h *LintHandler) GetDescription() string {
+	return "Reports likely mistakes in workflow files"
+}
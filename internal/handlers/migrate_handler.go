@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"rpa-dfs-engine/internal/logger"
+	"rpa-dfs-engine/internal/traverser"
+)
+
+// diffContext is how many unchanged lines surround each change in the preview.
+const diffContext = 3
+
+// MigrateHandler upgrades workflow files to the current schema version in place.
+type MigrateHandler struct {
+	paths    []string
+	dryRun   bool
+	output   io.Writer
+	parseErr error
+}
+
+// NewMigrateHandler creates a handler for "migrate [--dry-run] <workflow.json>...".
+func NewMigrateHandler(args []string) Handler {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "print the changes without writing the files")
+	parseErr := fs.Parse(args)
+
+	return &MigrateHandler{
+		paths:    fs.Args(),
+		dryRun:   *dryRun,
+		output:   os.Stdout,
+		parseErr: parseErr,
+	}
+}
+
+// Execute migrates every given workflow, printing a diff of each change before writing it.
+// Files that would not be valid after migrating are left untouched.
+func (h *MigrateHandler) Execute() error {
+	logger.LogInfo("=== RPA DFS Engine - Migrate Mode ===")
+
+	if h.parseErr != nil {
+		return parseResult(h.parseErr)
+	}
+
+	if len(h.paths) == 0 {
+		return fmt.Errorf("usage: migrate [--dry-run] <workflow.json>...")
+	}
+
+	failed := 0
+	for _, path := range h.paths {
+		if err := h.migrate(path); err != nil {
+			fmt.Fprintf(h.output, "❌ %s: %v\n", path, err)
+			logger.LogError("Migration failed: %s: %v", path, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return errors.New("migration failed")
+	}
+	return nil
+}
+
+func (h *MigrateHandler) migrate(path string) error {
+	if traverser.IsYAML(path) {
+		return fmt.Errorf("YAML workflows are migrated when loaded; only JSON files are rewritten")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	migration, err := traverser.MigrateWorkflow(data)
+	if err != nil {
+		return err
+	}
+	if !migration.Changed() {
+		fmt.Fprintf(h.output, "✅ %s is already at schema version %d\n", path, migration.To)
+		return nil
+	}
+	if errs := traverser.ValidateWorkflow(migration.Data); errs != nil {
+		return errs
+	}
+
+	if migration.From == migration.To {
+		fmt.Fprintf(h.output, "📝 %s: declaring schema version %d\n", path, migration.To)
+	} else {
+		fmt.Fprintf(h.output, "📝 %s: schema version %d → %d\n", path, migration.From, migration.To)
+	}
+	for _, applied := range migration.Applied {
+		fmt.Fprintf(h.output, "   %s\n", applied)
+	}
+	fmt.Fprint(h.output, unifiedDiff(path, string(data), string(migration.Data)))
+
+	if h.dryRun {
+		return nil
+	}
+	if err := os.WriteFile(path, migration.Data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	logger.LogSuccess("Workflow migrated: %s (schema version %d → %d)", path, migration.From, migration.To)
+	return nil
+}
+
+// GetDescription implements the Handler interface
+func (h *MigrateHandler) GetDescription() string {
+	return "Upgrades workflow files to the current schema version"
+}
+
+// diffLine is one line of a diff: ' ' unchanged, '-' removed or '+' added.
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// unifiedDiff returns the changes from before to after in unified diff format.
+func unifiedDiff(path, before, after string) string {
+	lines := diffLines(splitLines(before), splitLines(after))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", path, path)
+	for start := 0; start < len(lines); {
+		if lines[start].op == ' ' {
+			start++
+			continue
+		}
+
+		// Grow the hunk until the next change is further away than twice the context.
+		from := max(start-diffContext, 0)
+		end := start
+		for end < len(lines) {
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next++
+			}
+			if next == len(lines) || next-end > 2*diffContext {
+				break
+			}
+			for next < len(lines) && lines[next].op != ' ' {
+				next++
+			}
+			end = next
+		}
+		to := min(end+diffContext, len(lines))
+
+		oldStart, newStart := lineNumbers(lines[:from])
+		oldCount, newCount := lineNumbers(lines[from:to])
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", oldStart+1, oldCount, newStart+1, newCount)
+		for _, line := range lines[from:to] {
+			fmt.Fprintf(&out, "%c%s\n", line.op, line.text)
+		}
+		start = to
+	}
+	return out.String()
+}
+
+// diffLines aligns two texts on their longest common subsequence of lines.
+func diffLines(a, b []string) []diffLine {
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || common[i+1][j] >= common[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	return lines
+}
+
+// lineNumbers counts the lines of the old and new text covered by lines.
+func lineNumbers(lines []diffLine) (int, int) {
+	before, after := 0, 0
+	for _, line := range lines {
+		if line.op != '+' {
+			before++
+		}
+		if line.op != '-' {
+			after++
+		}
+	}
+	return before, after
+}
+
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
@@ -1,32 +1,128 @@
 package handlers
 
 import (
+	"fmt"
+	"net/url"
+
+	"rpa-dfs-engine/internal/browser"
+	"rpa-dfs-engine/internal/config"
 	"rpa-dfs-engine/internal/logger"
+	"rpa-dfs-engine/internal/traverser"
 )
 
 // ProcessHandler handles processing requests that include email and token credentials.
-// Currently this is a placeholder implementation that logs processing information.
-type ProcessHandler struct{}
+// It runs the configured traverser workflow with the credentials available as
+// {{user.email}} and {{user.token}}.
+type ProcessHandler struct {
+	email        string
+	token        string
+	workflowPath string
+	workflowErr  error
+	context      *contextFlags
+	strict       bool
+}
 
-// NewProcessHandler creates a new ProcessHandler instance.
+// NewProcessHandler creates a new ProcessHandler instance from the protocol query.
+// The workflow defaults to WORKFLOW_PATH; the "workflow" query parameter selects another
+// workflow by its name in WORKFLOW_CATALOG. The context is CONTEXT_PATH with the CONTEXT_ENV
+// layer merged over it, as for run and debug; the environment variable and --set layers are
+// command line flags that a protocol URL cannot carry.
+// "strict=true" fails the run on unresolved template references.
 // It returns the Handler interface to promote loose coupling.
-func NewProcessHandler() Handler {
-	return &ProcessHandler{}
+func NewProcessHandler(query url.Values) Handler {
+	h := &ProcessHandler{
+		email:   query.Get("email"),
+		token:   query.Get("token"),
+		context: &contextFlags{context: config.CONTEXT_PATH, env: config.CONTEXT_ENV},
+		strict:  query.Get("strict") == "true",
+	}
+	h.workflowPath, h.workflowErr = protocolWorkflow(query)
+	return h
 }
 
 // Execute implements the Handler interface for processing with credentials.
-// This is currently a placeholder implementation that logs the processing mode.
+// It loads the workflow and user context, starts a browser session and runs the workflow.
 func (h *ProcessHandler) Execute() error {
 	logger.LogInfo("=== RPA DFS Engine - Process Mode ===")
-	logger.LogInfo("Processing handler initialized with email and token")
-	logger.LogInfo("Note: This is currently a placeholder implementation")
-	logger.LogSuccess("Process handler completed successfully")
 
+	if h.workflowErr != nil {
+		return h.workflowErr
+	}
+	if h.workflowPath == "" {
+		return fmt.Errorf("no workflow configured: set WORKFLOW_PATH or pass workflow=<catalog name>")
+	}
+	logger.LogInfo("Workflow: %s", h.workflowPath)
+
+	workflow, err := traverser.LoadWorkflow(h.workflowPath)
+	if err != nil {
+		return err
+	}
+
+	userData, err := h.loadUserData()
+	if err != nil {
+		return err
+	}
+	secretKey, err := traverser.LoadSecretKey(config.SECRET_KEY_PATH)
+	if err != nil {
+		return err
+	}
+
+	session, err := browser.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	engine := traverser.NewEngine(session)
+	engine.SetWorkflow(workflow)
+	engine.SetContext(userData)
+	engine.SetSecretKey(secretKey)
+	engine.SetStrict(h.strict)
+	engine.SetCatalogDir(config.WORKFLOW_CATALOG)
+	engine.SetRunsDir(config.RUNS_DIR)
+
+	runErr := engine.Execute()
+	saveRunResult(engine.Result())
+	if runErr != nil {
+		return fmt.Errorf("workflow execution failed: %w", runErr)
+	}
+
+	logger.LogSuccess("Process handler completed successfully")
 	return nil
 }
 
 // GetDescription implements the Handler interface and returns a description
 // of what this handler does.
 func (h *ProcessHandler) GetDescription() string {
-	return "Processes requests using email and token credentials (placeholder implementation)"
+	return "Runs a traverser workflow using email and token credentials"
+}
+
+func (h *ProcessHandler) loadUserData() (map[string]interface{}, error) {
+	if h.context.context != "" {
+		logger.LogInfo("Context: %s", h.context.context)
+	}
+	userData, err := h.context.load()
+	if err != nil {
+		return nil, err
+	}
+
+	userData["email"] = h.email
+	userData["token"] = h.token
+	return userData, nil
+}
+
+// saveRunResult writes the run result next to its checkpoint when RUNS_DIR is set.
+func saveRunResult(result *traverser.RunResult) {
+	if config.RUNS_DIR == "" || result == nil {
+		return
+	}
+	path := traverser.ResultPath(config.RUNS_DIR, result.RunID)
+	if err := result.Save(path); err != nil {
+		logger.LogError("Could not save run result: %v", err)
+		return
+	}
+	logger.LogInfo("Run result: %s", path)
+	if result.Status == traverser.RunFailed {
+		logger.LogInfo("Resume with: resume %s", result.RunID)
+	}
 }
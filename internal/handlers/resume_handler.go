@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"flag"
+	"fmt"
+
+	"rpa-dfs-engine/internal/browser"
+	"rpa-dfs-engine/internal/config"
+	"rpa-dfs-engine/internal/logger"
+	"rpa-dfs-engine/internal/traverser"
+)
+
+// ResumeHandler continues an interrupted run from its last checkpoint.
+type ResumeHandler struct {
+	runID     string
+	runsDir   string
+	secretKey string
+	parseErr  error
+}
+
+// NewResumeHandler creates a handler for "resume [-runs dir] [-secret-key file] <run-id>".
+// The runs directory and key file default to RUNS_DIR and SECRET_KEY_PATH.
+func NewResumeHandler(args []string) Handler {
+	fs := flag.NewFlagSet("resume", flag.ContinueOnError)
+	runsDir := fs.String("runs", config.RUNS_DIR, "directory holding run checkpoints")
+	secretKey := fs.String("secret-key", config.SECRET_KEY_PATH, "key file for encrypted context values (default $"+traverser.SecretKeyEnv+")")
+	parseErr := fs.Parse(args)
+
+	return &ResumeHandler{
+		runID:     fs.Arg(0),
+		runsDir:   *runsDir,
+		secretKey: *secretKey,
+		parseErr:  parseErr,
+	}
+}
+
+// Execute loads the checkpoint and its workflow, restores the page and resumes the run.
+func (h *ResumeHandler) Execute() error {
+	logger.LogInfo("=== RPA DFS Engine - Resume Mode ===")
+
+	if h.parseErr != nil {
+		return parseResult(h.parseErr)
+	}
+
+	if h.runID == "" {
+		return fmt.Errorf("usage: resume [-runs dir] [-secret-key file] <run-id>")
+	}
+	if h.runsDir == "" {
+		return fmt.Errorf("no runs directory configured: set RUNS_DIR or pass -runs")
+	}
+
+	checkpoint, err := traverser.LoadCheckpoint(traverser.CheckpointPath(h.runsDir, h.runID))
+	if err != nil {
+		return fmt.Errorf("no checkpoint for run %s (it may have completed): %w", h.runID, err)
+	}
+	logger.LogInfo("Workflow: %s", checkpoint.WorkflowPath)
+
+	workflow, err := traverser.LoadWorkflow(checkpoint.WorkflowPath)
+	if err != nil {
+		return err
+	}
+	secretKey, err := traverser.LoadSecretKey(h.secretKey)
+	if err != nil {
+		return err
+	}
+
+	session, err := browser.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	engine := traverser.NewEngine(session)
+	engine.SetWorkflow(workflow)
+	engine.SetSecretKey(secretKey)
+	engine.SetCatalogDir(config.WORKFLOW_CATALOG)
+	engine.SetRunsDir(h.runsDir)
+
+	runErr := engine.Resume(checkpoint)
+	saveRunResult(engine.Result())
+	if runErr != nil {
+		return fmt.Errorf("resumed run failed: %w", runErr)
+	}
+
+	logger.LogSuccess("Run %s resumed and completed", h.runID)
+	return nil
+}
+
+// GetDescription implements the Handler interface
+func (h *ResumeHandler) GetDescription() string {
+	return "Resumes an interrupted workflow run from its checkpoint"
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"rpa-dfs-engine/internal/browser"
+	"rpa-dfs-engine/internal/config"
+	"rpa-dfs-engine/internal/logger"
+	"rpa-dfs-engine/internal/traverser"
+)
+
+// RunHandler runs a workflow from the command line, or plans it with --dry-run.
+type RunHandler struct {
+	workflowPath string
+	context      *contextFlags
+	secretKey    string
+	strict       bool
+	dryRun       bool
+	assertions   string
+	junitPath    string
+	answer       string
+	output       io.Writer
+	parseErr     error
+}
+
+// NewRunHandler creates a handler for "run [--context file|json] [--env name] [--use-env]
+// [--env-map PREFIX=path]... [--set path=value]... [--secret-key file] [--strict] [--dry-run]
+// [--assertions stop|collect] [--junit report.xml] [--answer always|never|ask] <workflow.json>".
+// The workflow, context, environment and key file default to WORKFLOW_PATH, CONTEXT_PATH,
+// CONTEXT_ENV and SECRET_KEY_PATH.
+func NewRunHandler(args []string) Handler {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	context := addContextFlags(fs)
+	secretKey := fs.String("secret-key", config.SECRET_KEY_PATH, "key file for encrypted context values (default $"+traverser.SecretKeyEnv+")")
+	strict := fs.Bool("strict", false, "fail on unresolved template references")
+	dryRun := fs.Bool("dry-run", false, "print the resolved actions without launching a browser")
+	assertions := fs.String("assertions", traverser.AssertStop, "stop at the first failed assertion, or collect them all")
+	junitPath := fs.String("junit", "", "write assertion results as JUnit XML to this file")
+	answer := fs.String("answer", "", "answer forEach questions without prompting: always, never or ask")
+	parseErr := fs.Parse(args)
+
+	h := &RunHandler{
+		workflowPath: config.WORKFLOW_PATH,
+		context:      context,
+		secretKey:    *secretKey,
+		strict:       *strict,
+		dryRun:       *dryRun,
+		assertions:   *assertions,
+		junitPath:    *junitPath,
+		answer:       *answer,
+		output:       os.Stdout,
+		parseErr:     parseErr,
+	}
+	if fs.NArg() > 0 {
+		h.workflowPath = fs.Arg(0)
+	}
+	return h
+}
+
+// Execute loads the workflow and context and either runs the workflow in a browser
+// or prints its dry-run plan.
+func (h *RunHandler) Execute() error {
+	logger.LogInfo("=== RPA DFS Engine - Run Mode ===")
+
+	if h.parseErr != nil {
+		return parseResult(h.parseErr)
+	}
+
+	if h.workflowPath == "" {
+		return fmt.Errorf("usage: run [--context file|json] [--env name] [--use-env] [--set path=value]... [--secret-key file] [--strict] [--dry-run] [--assertions stop|collect] [--junit file] [--answer policy] <workflow.json>")
+	}
+
+	workflow, err := traverser.LoadWorkflow(h.workflowPath)
+	if err != nil {
+		return err
+	}
+
+	userData, err := h.context.load()
+	if err != nil {
+		return err
+	}
+
+	if h.dryRun {
+		return h.plan(workflow, userData)
+	}
+
+	secretKey, err := traverser.LoadSecretKey(h.secretKey)
+	if err != nil {
+		return err
+	}
+
+	session, err := browser.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	engine := traverser.NewEngine(session)
+	engine.SetWorkflow(workflow)
+	engine.SetContext(userData)
+	engine.SetSecretKey(secretKey)
+	engine.SetStrict(h.strict)
+	engine.SetCatalogDir(config.WORKFLOW_CATALOG)
+	engine.SetRunsDir(config.RUNS_DIR)
+	if err := engine.SetAssertionMode(h.assertions); err != nil {
+		return err
+	}
+	if err := engine.SetAnswerPolicy(h.answer); err != nil {
+		return err
+	}
+
+	runErr := engine.Execute()
+	saveRunResult(engine.Result())
+	if h.junitPath != "" {
+		if err := engine.Result().SaveJUnit(h.junitPath); err != nil {
+			logger.LogError("Could not write JUnit report: %v", err)
+		} else {
+			logger.LogInfo("JUnit report written to %s", h.junitPath)
+		}
+	}
+	if runErr != nil {
+		return fmt.Errorf("workflow execution failed: %w", runErr)
+	}
+	return nil
+}
+
+// plan walks the workflow without a browser and prints each step with its issues.
+func (h *RunHandler) plan(workflow *traverser.Workflow, userData map[string]interface{}) error {
+	engine := traverser.NewEngine(nil)
+	engine.SetWorkflow(workflow)
+	engine.SetContext(userData)
+	engine.SetStrict(h.strict)
+	engine.SetCatalogDir(config.WORKFLOW_CATALOG)
+	engine.SetDryRun(true)
+
+	runErr := engine.Execute()
+	plan := engine.Plan()
+
+	fmt.Fprintf(h.output, "Plan for %s:\n", h.workflowPath)
+	for i, step := range plan.Steps {
+		indent := strings.Repeat("  ", step.Depth)
+		fmt.Fprintf(h.output, "%s%d. %s (%s)\n", indent, i+1, step.Node, step.NodeType)
+		for _, action := range step.Actions {
+			fmt.Fprintf(h.output, "%s     %s\n", indent, action)
+		}
+		for _, issue := range step.Issues {
+			fmt.Fprintf(h.output, "%s     ⚠️  %s\n", indent, issue)
+		}
+	}
+
+	if runErr != nil {
+		fmt.Fprintf(h.output, "❌ dry run stopped: %v\n", runErr)
+		return fmt.Errorf("dry run failed: %w", runErr)
+	}
+	if issues := plan.IssueCount(); issues > 0 {
+		fmt.Fprintf(h.output, "⚠️  %d issues found\n", issues)
+		return errors.New("dry run found issues")
+	}
+	fmt.Fprintln(h.output, "✅ no issues found")
+	return nil
+}
+
+// GetDescription implements the Handler interface
+func (h *RunHandler) GetDescription() string {
+	return "Runs a workflow from the command line, or prints its plan with --dry-run"
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"rpa-dfs-engine/internal/config"
+	"rpa-dfs-engine/internal/logger"
+	"rpa-dfs-engine/internal/traverser"
+)
+
+const secretUsage = "usage: secret keygen [--out file] | secret encrypt [--key file] < value | " +
+	"secret rotate [--key file] --new-key file <context>..."
+
+// SecretHandler creates secret keys, encrypts context values and re-encrypts context
+// files with a new key.
+type SecretHandler struct {
+	action   string
+	args     []string
+	keyPath  string
+	newKey   string
+	outPath  string
+	input    io.Reader
+	output   io.Writer
+	parseErr error
+}
+
+// NewSecretHandler creates a handler for "secret keygen [--out file]",
+// "secret encrypt [--key file]" (the value is read from standard input) and "secret rotate [--key file] --new-key file <context>...".
+// The key defaults to SECRET_KEY_PATH, then to the RPA_SECRET_KEY environment variable.
+func NewSecretHandler(args []string) Handler {
+	h := &SecretHandler{input: os.Stdin, output: os.Stdout}
+	if len(args) == 0 {
+		return h
+	}
+	h.action = args[0]
+
+	fs := flag.NewFlagSet("secret "+h.action, flag.ContinueOnError)
+	keyPath := fs.String("key", config.SECRET_KEY_PATH, "key file (default $"+traverser.SecretKeyEnv+")")
+	newKey := fs.String("new-key", "", "key file to re-encrypt with")
+	outPath := fs.String("out", "", "write the generated key to this file")
+	h.parseErr = fs.Parse(args[1:])
+
+	h.args = fs.Args()
+	h.keyPath = *keyPath
+	h.newKey = *newKey
+	h.outPath = *outPath
+	return h
+}
+
+// Execute runs the secret subcommand.
+func (h *SecretHandler) Execute() error {
+	logger.LogInfo("=== RPA DFS Engine - Secret Mode ===")
+
+	if h.parseErr != nil {
+		return parseResult(h.parseErr)
+	}
+
+	switch h.action {
+	case "keygen":
+		return h.keygen()
+	case "encrypt":
+		return h.encrypt()
+	case "rotate":
+		return h.rotate()
+	}
+	return errors.New(secretUsage)
+}
+
+// keygen prints a new key, or writes it to a file only the current user can read.
+func (h *SecretHandler) keygen() error {
+	key, err := traverser.GenerateSecretKey()
+	if err != nil {
+		return err
+	}
+	if h.outPath == "" {
+		fmt.Fprintln(h.output, key)
+		return nil
+	}
+
+	file, err := os.OpenFile(h.outPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("cannot create key file: %w", err)
+	}
+	defer file.Close()
+	if _, err := fmt.Fprintln(file, key); err != nil {
+		return err
+	}
+	fmt.Fprintf(h.output, "✅ key written to %s\n", h.outPath)
+	logger.LogSuccess("Secret key written to %s", h.outPath)
+	return nil
+}
+
+// encrypt prints the context value for the plaintext read from standard input. It is not
+// taken as an argument, which would end up in the shell history and the log file.
+func (h *SecretHandler) encrypt() error {
+	if len(h.args) > 0 {
+		return errors.New("secret encrypt reads the value from standard input, not from its arguments; " + secretUsage)
+	}
+	key, err := h.loadKey(h.keyPath)
+	if err != nil {
+		return err
+	}
+
+	line, err := bufio.NewReader(h.input).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	plaintext := strings.TrimRight(line, "\r\n")
+
+	value, err := traverser.EncryptSecret(key, plaintext)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(traverser.Secret{Ciphertext: value})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(h.output, string(data))
+	return nil
+}
+
+// rotate re-encrypts the secrets of every given context file with the new key, in place.
+// A file is only written when all of its secrets could be decrypted.
+func (h *SecretHandler) rotate() error {
+	if h.newKey == "" || len(h.args) == 0 {
+		return errors.New(secretUsage)
+	}
+	oldKey, err := h.loadKey(h.keyPath)
+	if err != nil {
+		return err
+	}
+	newKey, err := traverser.LoadSecretKey(h.newKey)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, path := range h.args {
+		count, err := rotateFile(path, oldKey, newKey)
+		if err != nil {
+			fmt.Fprintf(h.output, "❌ %s: %v\n", path, err)
+			logger.LogError("Secret rotation failed: %s: %v", path, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(h.output, "✅ %s: %d secrets rotated\n", path, count)
+		logger.LogSuccess("Secrets rotated: %s (%d)", path, count)
+	}
+
+	if failed > 0 {
+		return errors.New("secret rotation failed")
+	}
+	return nil
+}
+
+func rotateFile(path string, oldKey, newKey []byte) (int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	rotated, count, err := traverser.RotateSecrets(data, oldKey, newKey)
+	if err != nil || count == 0 {
+		return 0, err
+	}
+	return count, os.WriteFile(path, rotated, info.Mode().Perm())
+}
+
+func (h *SecretHandler) loadKey(path string) ([]byte, error) {
+	key, err := traverser.LoadSecretKey(path)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("no secret key: pass --key, or set SECRET_KEY_PATH or %s", traverser.SecretKeyEnv)
+	}
+	return key, nil
+}
+
+// GetDescription implements the Handler interface
+func (h *SecretHandler) GetDescription() string {
+	return "Creates secret keys and encrypts or rotates context values"
+}
@@ -1,131 +1,159 @@
 package handlers
 
 import (
-	"context"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
+	"net/url"
+	"os"
 	"strings"
-	"time"
 
+	"rpa-dfs-engine/internal/browser"
 	"rpa-dfs-engine/internal/config"
 	"rpa-dfs-engine/internal/logger"
-
-	"github.com/chromedp/chromedp"
+	"rpa-dfs-engine/internal/traverser"
 )
 
+// TestHandler runs a workflow in a headed browser under the interactive debugger,
+// pausing at breakpoints so workflows can be authored and fixed step by step.
 type TestHandler struct {
-	url               string
-	navigationTimeout time.Duration
-	displayDuration   time.Duration
+	workflowPath string
+	context      *contextFlags
+	secretKey    string
+	breakpoints  []string
+	siteURL      string
+	parseErr     error
+	workflowErr  error
 }
 
-func NewTestHandler() Handler {
-	return &TestHandler{
-		url:               config.SITE_FOR_TEST,
-		navigationTimeout: 7777777777 * time.Second,
+// NewTestHandler creates the debug handler for "isTest=true" protocol calls.
+// The "workflow" query parameter selects a workflow by its name in WORKFLOW_CATALOG
+// instead of WORKFLOW_PATH, and "break" takes comma-separated node ids or nodeTypes.
+// The context is always CONTEXT_PATH.
+func NewTestHandler(query url.Values) Handler {
+	h := &TestHandler{
+		context:   &contextFlags{context: config.CONTEXT_PATH, env: config.CONTEXT_ENV},
+		secretKey: config.SECRET_KEY_PATH,
+		siteURL:   config.SITE_FOR_TEST,
+	}
+	h.workflowPath, h.workflowErr = protocolWorkflow(query)
+	if breakpoints := query.Get("break"); breakpoints != "" {
+		h.breakpoints = strings.Split(breakpoints, ",")
 	}
+	return h
 }
 
+// NewDebugHandler creates the debug handler for "debug [context flags] [--secret-key file]
+// [--break id,type] <workflow.json>", taking the context flags of run.
+func NewDebugHandler(args []string) Handler {
+	fs := flag.NewFlagSet("debug", flag.ContinueOnError)
+	context := addContextFlags(fs)
+	secretKey := fs.String("secret-key", config.SECRET_KEY_PATH, "key file for encrypted context values (default $"+traverser.SecretKeyEnv+")")
+	breakpoints := fs.String("break", "", "comma-separated node ids or nodeTypes to stop at")
+	parseErr := fs.Parse(args)
+
+	h := &TestHandler{
+		workflowPath: config.WORKFLOW_PATH,
+		context:      context,
+		secretKey:    *secretKey,
+		siteURL:      config.SITE_FOR_TEST,
+		parseErr:     parseErr,
+	}
+	if fs.NArg() > 0 {
+		h.workflowPath = fs.Arg(0)
+	}
+	if *breakpoints != "" {
+		h.breakpoints = strings.Split(*breakpoints, ",")
+	}
+	return h
+}
+
+// Execute starts a browser session and runs the workflow under the debugger.
+// Without a configured workflow it debugs a single moveToPage to SITE_FOR_TEST.
 func (h *TestHandler) Execute() error {
 	originalOutput := log.Writer()
 	log.SetOutput(&cookieErrorFilter{originalOutput})
 	defer log.SetOutput(originalOutput)
 
 	logger.LogInfo("=== RPA DFS Engine - Test Mode ===")
-	logger.LogInfo("Opening test website: %s", h.url)
-
-	opts := append(
-		chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", false),
-		chromedp.Flag("disable-gpu", false),
-		chromedp.Flag("disable-web-security", true),
-		chromedp.Flag("disable-features", "VizDisplayCompositor,NetworkService,CookieStore,NavigationThreadingOptimizations"),
-		chromedp.Flag("disable-background-timer-throttling", true),
-		chromedp.Flag("disable-backgrounding-occluded-windows", true),
-		chromedp.Flag("disable-renderer-backgrounding", true),
-		chromedp.Flag("disable-dev-shm-usage", true),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.Flag("ignore-certificate-errors", true),
-		chromedp.Flag("ignore-ssl-errors", true),
-		chromedp.Flag("ignore-certificate-errors-spki-list", true),
-		chromedp.Flag("ignore-certificate-errors-ssl-version-fallback-min", true),
-		chromedp.Flag("disable-cookies", true),
-		chromedp.Flag("disable-local-storage", true),
-		chromedp.Flag("disable-session-storage", true),
-		chromedp.Flag("disable-extensions", true),
-		chromedp.Flag("disable-plugins", true),
-		chromedp.Flag("disable-default-apps", true),
-		chromedp.Flag("disable-background-networking", true),
-		chromedp.Flag("disable-client-side-phishing-detection", true),
-		chromedp.Flag("disable-sync", true),
-		chromedp.Flag("disable-hang-monitor", true),
-		chromedp.Flag("disable-prompt-on-repost", true),
-		chromedp.Flag("disable-domain-reliability", true),
-	)
-
-	allocCtx, cancelAlloc := chromedp.NewExecAllocator(context.Background(), opts...)
-	defer cancelAlloc()
-
-	ctx, cancelCtx := chromedp.NewContext(allocCtx)
-	defer cancelCtx()
-
-	ctxTimeout, cancelTimeout := context.WithTimeout(ctx, h.navigationTimeout)
-	defer cancelTimeout()
-
-	if err := h.navigateToWebsite(ctxTimeout); err != nil {
-		logger.LogError("Failed to navigate to website: %v", err)
-		return fmt.Errorf("website navigation failed: %w", err)
-	}
-
-	logger.LogSuccess("Successfully navigated to test website")
-	logger.LogInfo("Press ENTER in the console to navigate to the next link...")
 
-	fmt.Print("Press ENTER to continue to the next link...")
-	_, _ = fmt.Scanln()
+	if h.parseErr != nil {
+		return parseResult(h.parseErr)
+	}
+	if h.workflowErr != nil {
+		return h.workflowErr
+	}
 
-	nextURL := "https://example.com/"
-	logger.LogInfo("Navigating to next website: %s", nextURL)
-	if err := chromedp.Run(ctxTimeout,
-		chromedp.Navigate(nextURL),
-		chromedp.WaitVisible("body", chromedp.ByQuery),
-	); err != nil {
-		logger.LogError("Failed to navigate to next website: %v", err)
-		return fmt.Errorf("next website navigation failed: %w", err)
+	workflow, err := h.loadWorkflow()
+	if err != nil {
+		return err
 	}
-	logger.LogSuccess("Successfully navigated to next website")
 
-	logger.LogInfo("Waiting for browser window to close...")
+	userData, err := h.context.load()
+	if err != nil {
+		return err
+	}
+	secretKey, err := traverser.LoadSecretKey(h.secretKey)
+	if err != nil {
+		return err
+	}
 
-	<-ctx.Done()
+	session, err := browser.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	input := bufio.NewReader(os.Stdin)
+	engine := traverser.NewEngine(session)
+	engine.SetWorkflow(workflow)
+	engine.SetContext(userData)
+	engine.SetSecretKey(secretKey)
+	engine.SetIO(input, os.Stdout)
+	engine.SetCatalogDir(config.WORKFLOW_CATALOG)
+	engine.SetDebugger(traverser.NewDebugger(h.breakpoints))
+
+	fmt.Printf("Debugging %s (type help at the prompt)\n", workflow.Name())
+	err = engine.Execute()
+	switch {
+	case errors.Is(err, traverser.ErrDebugQuit):
+		logger.LogInfo("Debug session stopped by user")
+		return nil
+	case err != nil:
+		fmt.Printf("❌ %v\n", err)
+	default:
+		fmt.Println("✅ workflow finished")
+	}
 
-	logger.LogInfo("Browser window closed. Exiting program.")
-	return nil
+	fmt.Print("Press ENTER to close the browser...")
+	_, _ = input.ReadString('\n')
+	return err
 }
 
+// GetDescription implements the Handler interface
 func (h *TestHandler) GetDescription() string {
-	return "Opens test websites using Chrome automation for RPA engine testing"
+	return "Runs a workflow in a headed browser under the interactive debugger"
 }
 
-func (h *TestHandler) SetURL(url string) {
-	if url != "" {
-		h.url = url
+func (h *TestHandler) loadWorkflow() (*traverser.Workflow, error) {
+	if h.workflowPath != "" {
+		logger.LogInfo("Workflow: %s", h.workflowPath)
+		return traverser.LoadWorkflow(h.workflowPath)
 	}
-}
 
-// SetNavigationTimeout allows customizing the navigation timeout.
-func (h *TestHandler) SetNavigationTimeout(timeout time.Duration) {
-	if timeout > 0 {
-		h.navigationTimeout = timeout
+	logger.LogInfo("No workflow configured, opening test website: %s", h.siteURL)
+	data, err := json.Marshal(map[string]interface{}{
+		"graph":    map[string]interface{}{"nodeType": traverser.NodeTypeMoveToPage, "url": h.siteURL},
+		"metadata": map[string]interface{}{"name": "test website"},
+	})
+	if err != nil {
+		return nil, err
 	}
-}
-
-// navigateToWebsite handles the Chrome automation for website navigation.
-func (h *TestHandler) navigateToWebsite(ctx context.Context) error {
-	return chromedp.Run(ctx,
-		chromedp.Navigate(h.url),
-		chromedp.WaitVisible("body", chromedp.ByQuery),
-	)
+	return traverser.ParseWorkflow(data)
 }
 
 // cookieErrorFilter фильтрует ошибки связанные с cookie парсингом
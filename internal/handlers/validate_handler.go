@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"rpa-dfs-engine/internal/logger"
+	"rpa-dfs-engine/internal/traverser"
+)
+
+// ValidateHandler checks workflow files against the traverser schema without running them.
+type ValidateHandler struct {
+	paths    []string
+	output   io.Writer
+	parseErr error
+}
+
+// NewValidateHandler creates a handler for "validate <workflow.json|workflow.yaml>...".
+func NewValidateHandler(args []string) Handler {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	parseErr := fs.Parse(args)
+
+	return &ValidateHandler{
+		paths:    fs.Args(),
+		output:   os.Stdout,
+		parseErr: parseErr,
+	}
+}
+
+// Execute validates every given workflow and prints each violation with its location.
+func (h *ValidateHandler) Execute() error {
+	logger.LogInfo("=== RPA DFS Engine - Validate Mode ===")
+
+	if h.parseErr != nil {
+		return parseResult(h.parseErr)
+	}
+
+	if len(h.paths) == 0 {
+		return fmt.Errorf("usage: validate <workflow.json>...")
+	}
+
+	invalid := 0
+	for _, path := range h.paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(h.output, "❌ %s: %v\n", path, err)
+			invalid++
+			continue
+		}
+
+		validate := traverser.ValidateWorkflow
+		if traverser.IsYAML(path) {
+			validate = traverser.ValidateWorkflowYAML
+		}
+		errs := validate(data)
+		if errs == nil {
+			fmt.Fprintf(h.output, "✅ %s is valid\n", path)
+			logger.LogSuccess("Workflow valid: %s", path)
+			continue
+		}
+
+		invalid++
+		fmt.Fprintf(h.output, "❌ %s: %d errors\n", path, len(errs))
+		for _, e := range errs {
+			fmt.Fprintf(h.output, "  %s:%d:%d %s: %s\n", path, e.Line, e.Column, e.Pointer, e.Message)
+		}
+		logger.LogError("Workflow invalid: %s (%d errors)", path, len(errs))
+	}
+
+	if invalid > 0 {
+		return errors.New("validation failed")
+	}
+	return nil
+}
+
+// GetDescription implements the Handler interface
+func (h *ValidateHandler) GetDescription() string {
+	return "Validates workflow files against the traverser schema"
+}
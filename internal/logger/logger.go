@@ -2,15 +2,27 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
 var logFile *os.File
 var logger *log.Logger
 
+// redactions are values that are replaced by redactionMask in every log line.
+var (
+	redactions   []string
+	redactionsMu sync.RWMutex
+)
+
+const redactionMask = "********"
+
 func InitLogger() error {
 	exePath, err := os.Executable()
 	if err != nil {
@@ -40,7 +52,7 @@ func InitLogger() error {
 
 	logger.Println("=== SESSION START ===")
 	logger.Printf("Start time: %s", time.Now().Format("2006-01-02 15:04:05"))
-	logger.Printf("Arguments: %v", os.Args)
+	logger.Printf("Arguments: %v", RedactArgs(os.Args))
 
 	workDir, _ := os.Getwd()
 	logger.Printf("Working directory: %s", workDir)
@@ -48,6 +60,16 @@ func InitLogger() error {
 	return nil
 }
 
+// SetOutput writes log lines to w instead of the log file, e.g. to capture them in tests.
+// A nil w turns logging off.
+func SetOutput(w io.Writer) {
+	if w == nil {
+		logger = nil
+		return
+	}
+	logger = log.New(w, "", log.LstdFlags)
+}
+
 func CloseLogger() {
 	if logger != nil {
 		logger.Println("=== SESSION END ===")
@@ -58,7 +80,7 @@ func CloseLogger() {
 }
 
 func LogInfo(format string, v ...interface{}) {
-	message := fmt.Sprintf(format, v...)
+	message := redact(fmt.Sprintf(format, v...))
 	//fmt.Printf("ℹ️ %s\n", message)
 	if logger != nil {
 		logger.Printf("INFO: %s", message)
@@ -66,7 +88,7 @@ func LogInfo(format string, v ...interface{}) {
 }
 
 func LogSuccess(format string, v ...interface{}) {
-	message := fmt.Sprintf(format, v...)
+	message := redact(fmt.Sprintf(format, v...))
 	//fmt.Printf("✅ %s\n", message)
 	if logger != nil {
 		logger.Printf("SUCCESS: %s", message)
@@ -74,7 +96,7 @@ func LogSuccess(format string, v ...interface{}) {
 }
 
 func LogError(format string, v ...interface{}) {
-	message := fmt.Sprintf(format, v...)
+	message := redact(fmt.Sprintf(format, v...))
 	//fmt.Printf("❌ %s\n", message)
 	if logger != nil {
 		logger.Printf("ERROR: %s", message)
@@ -82,7 +104,7 @@ func LogError(format string, v ...interface{}) {
 }
 
 func LogWarning(format string, v ...interface{}) {
-	message := fmt.Sprintf(format, v...)
+	message := redact(fmt.Sprintf(format, v...))
 	//fmt.Printf("⚠️ %s\n", message)
 	if logger != nil {
 		logger.Printf("WARNING: %s", message)
@@ -90,9 +112,69 @@ func LogWarning(format string, v ...interface{}) {
 }
 
 func LogDebug(format string, v ...interface{}) {
-	message := fmt.Sprintf(format, v...)
+	message := redact(fmt.Sprintf(format, v...))
 	//fmt.Printf("🐛 %s\n", message)
 	if logger != nil {
 		logger.Printf("DEBUG: %s", message)
 	}
 }
+
+// Redact masks value in all later log lines, such as a decrypted secret.
+func Redact(value string) {
+	if value == "" {
+		return
+	}
+	redactionsMu.Lock()
+	defer redactionsMu.Unlock()
+	for _, existing := range redactions {
+		if existing == value {
+			return
+		}
+	}
+	redactions = append(redactions, value)
+	// Longest first, so a value containing another is masked whole.
+	sort.Slice(redactions, func(i, j int) bool { return len(redactions[i]) > len(redactions[j]) })
+}
+
+// RedactArgs returns a copy of the command line that is safe to log: the values of --set
+// overrides and the arguments of "secret encrypt" are masked.
+func RedactArgs(args []string) []string {
+	redacted := append([]string(nil), args...)
+	encrypt := len(args) > 2 && args[1] == "secret" && args[2] == "encrypt"
+	for i := 1; i < len(redacted); i++ {
+		arg := redacted[i]
+		switch {
+		case encrypt && (arg == "--key" || arg == "-key"):
+			i++ // the key file path
+		case encrypt && i > 2 && !strings.HasPrefix(arg, "-"):
+			redacted[i] = redactionMask
+		case arg == "--set" || arg == "-set":
+			if i+1 < len(redacted) {
+				i++
+				redacted[i] = redactSet(redacted[i])
+			}
+		case strings.HasPrefix(arg, "--set=") || strings.HasPrefix(arg, "-set="):
+			flag, value, _ := strings.Cut(arg, "=")
+			redacted[i] = flag + "=" + redactSet(value)
+		}
+	}
+	return redacted
+}
+
+// redactSet masks the value of a path=value override.
+func redactSet(override string) string {
+	path, _, ok := strings.Cut(override, "=")
+	if !ok {
+		return redactionMask
+	}
+	return path + "=" + redactionMask
+}
+
+func redact(message string) string {
+	redactionsMu.RLock()
+	defer redactionsMu.RUnlock()
+	for _, value := range redactions {
+		message = strings.ReplaceAll(message, value, redactionMask)
+	}
+	return message
+}
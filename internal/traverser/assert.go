@@ -0,0 +1,219 @@
+package traverser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"rpa-dfs-engine/internal/logger"
+)
+
+// Assertion modes: stop fails the run at the first failed assertion, collect records
+// every failure, keeps going and fails the run at the end.
+const (
+	AssertStop    = "stop"
+	AssertCollect = "collect"
+)
+
+// Ways assertText, assertURL and assertTitle compare the actual value with expected.
+const (
+	MatchEquals   = "equals"
+	MatchContains = "contains"
+	MatchRegex    = "matches"
+)
+
+var matchModes = []string{MatchEquals, MatchContains, MatchRegex}
+
+// AssertionError is a failed assertion with the expected and actual values.
+type AssertionError struct {
+	Assertion string
+	Target    string
+	Expected  string
+	Actual    string
+}
+
+func (e *AssertionError) Error() string {
+	return fmt.Sprintf("%s %s: expected %s, got %s", e.Assertion, e.Target, e.Expected, e.Actual)
+}
+
+// AssertionFailures is returned by a run in collect mode when any assertion failed.
+type AssertionFailures struct {
+	Failures []error
+}
+
+func (e *AssertionFailures) Error() string {
+	messages := make([]string, len(e.Failures))
+	for i, err := range e.Failures {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d assertion(s) failed:\n  %s", len(e.Failures), strings.Join(messages, "\n  "))
+}
+
+// SetAssertionMode chooses between AssertStop (the default) and AssertCollect.
+func (e *Engine) SetAssertionMode(mode string) error {
+	switch mode {
+	case "", AssertStop:
+		e.collectAssertions = false
+	case AssertCollect:
+		e.collectAssertions = true
+	default:
+		return fmt.Errorf("unknown assertion mode %q, expected %s or %s", mode, AssertStop, AssertCollect)
+	}
+	return nil
+}
+
+func (e *Engine) assertionMode() string {
+	if e.collectAssertions {
+		return AssertCollect
+	}
+	return AssertStop
+}
+
+// executeAssert checks the page once. To wait for a state, give the node a retry policy
+// with "on": ["assertion"].
+func (e *Engine) executeAssert(node *Node) error {
+	selector, err := e.resolveString(node.Selector)
+	if err != nil {
+		return err
+	}
+	expected, err := e.resolveString(node.Expected)
+	if err != nil {
+		return err
+	}
+	if e.plan != nil {
+		e.planNote("%s", describeAssertion(node, selector, expected))
+		return nil
+	}
+
+	var failure *AssertionError
+	switch node.NodeType {
+	case NodeTypeAssertVisible, NodeTypeAssertNotVisible:
+		failure, err = e.assertVisibility(node, selector)
+	case NodeTypeAssertText:
+		failure, err = e.assertText(node, selector, expected)
+	case NodeTypeAssertURL:
+		failure, err = e.assertPage(node, "url", e.browser.CurrentURL, expected)
+	case NodeTypeAssertTitle:
+		failure, err = e.assertPage(node, "title", e.browser.Title, expected)
+	}
+	if err != nil {
+		return err
+	}
+	if failure != nil {
+		return failure
+	}
+	logger.LogSuccess("Assertion passed: %s", describeAssertion(node, selector, expected))
+	return nil
+}
+
+func (e *Engine) assertVisibility(node *Node, selector string) (*AssertionError, error) {
+	visible, err := e.browser.Visible(selector)
+	if err != nil {
+		return nil, &ActionError{Action: node.NodeType, Target: selector, Err: err}
+	}
+	want := node.NodeType == NodeTypeAssertVisible
+	if visible == want {
+		return nil, nil
+	}
+	return &AssertionError{
+		Assertion: node.NodeType,
+		Target:    selector,
+		Expected:  visibility(want),
+		Actual:    visibility(visible),
+	}, nil
+}
+
+func (e *Engine) assertText(node *Node, selector, expected string) (*AssertionError, error) {
+	text, err := e.browser.Text(selector)
+	if ErrorKind(err) == ErrorKindNotFound {
+		return &AssertionError{
+			Assertion: node.NodeType,
+			Target:    selector,
+			Expected:  describeMatch(node.Match, expected),
+			Actual:    "no matching element",
+		}, nil
+	}
+	if err != nil {
+		return nil, &ActionError{Action: node.NodeType, Target: selector, Err: err}
+	}
+	return compareAssertion(node, selector, expected, strings.TrimSpace(text))
+}
+
+func (e *Engine) assertPage(node *Node, target string, read func() (string, error), expected string) (*AssertionError, error) {
+	actual, err := read()
+	if err != nil {
+		return nil, &ActionError{Action: node.NodeType, Target: target, Err: err}
+	}
+	return compareAssertion(node, target, expected, actual)
+}
+
+// compareAssertion matches actual against expected using the node's match mode.
+func compareAssertion(node *Node, target, expected, actual string) (*AssertionError, error) {
+	var ok bool
+	switch node.Match {
+	case "", MatchEquals:
+		ok = actual == expected
+	case MatchContains:
+		ok = strings.Contains(actual, expected)
+	case MatchRegex:
+		re, err := regexp.Compile(expected)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", expected, err)
+		}
+		ok = re.MatchString(actual)
+	default:
+		return nil, fmt.Errorf("unknown match %q", node.Match)
+	}
+	if ok {
+		return nil, nil
+	}
+	return &AssertionError{
+		Assertion: node.NodeType,
+		Target:    target,
+		Expected:  describeMatch(node.Match, expected),
+		Actual:    fmt.Sprintf("%q", actual),
+	}, nil
+}
+
+func describeMatch(match, expected string) string {
+	switch match {
+	case MatchContains:
+		return fmt.Sprintf("containing %q", expected)
+	case MatchRegex:
+		return fmt.Sprintf("matching /%s/", expected)
+	}
+	return fmt.Sprintf("%q", expected)
+}
+
+func describeAssertion(node *Node, selector, expected string) string {
+	switch node.NodeType {
+	case NodeTypeAssertVisible:
+		return fmt.Sprintf("assert %s visible", selector)
+	case NodeTypeAssertNotVisible:
+		return fmt.Sprintf("assert %s not visible", selector)
+	case NodeTypeAssertText:
+		return fmt.Sprintf("assert text of %s %s", selector, describeMatch(node.Match, expected))
+	case NodeTypeAssertURL:
+		return fmt.Sprintf("assert url %s", describeMatch(node.Match, expected))
+	default:
+		return fmt.Sprintf("assert title %s", describeMatch(node.Match, expected))
+	}
+}
+
+func visibility(visible bool) string {
+	if visible {
+		return "visible"
+	}
+	return "not visible"
+}
+
+// collectAssertion records a failed assertion in collect mode. It reports false when
+// err must fail the node instead.
+func (e *Engine) collectAssertion(node *Node, err error) bool {
+	if !e.collectAssertions || ErrorKind(err) != ErrorKindAssertion {
+		return false
+	}
+	logger.LogError("Assertion failed, continuing: %v", err)
+	e.assertionFailures = append(e.assertionFailures, e.nodeError(node, err))
+	return true
+}
@@ -0,0 +1,222 @@
+package traverser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"rpa-dfs-engine/internal/logger"
+)
+
+// DefaultMaxCallDepth limits how deeply callWorkflow nodes may nest.
+const DefaultMaxCallDepth = 8
+
+// catalogNamePattern matches workflow names without path separators, which cannot leave
+// the catalog directory.
+var catalogNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// StackFrame is one level of the workflow call stack: a workflow and the node executing in it.
+type StackFrame struct {
+	Workflow string `json:"workflow"`
+	Node     string `json:"node"`
+}
+
+func (f StackFrame) String() string {
+	return fmt.Sprintf("%s#%s", f.Workflow, f.Node)
+}
+
+// WorkflowError is a node failure with the call stack that led to it,
+// outermost workflow first.
+type WorkflowError struct {
+	Stack []StackFrame
+	Err   error
+}
+
+func (e *WorkflowError) Error() string {
+	frames := make([]string, len(e.Stack))
+	for i, frame := range e.Stack {
+		frames[i] = frame.String()
+	}
+	return fmt.Sprintf("%s: %v", strings.Join(frames, " > "), e.Err)
+}
+
+func (e *WorkflowError) Unwrap() error {
+	return e.Err
+}
+
+// nodeError attaches the current workflow and node to err unless a deeper node already did.
+func (e *Engine) nodeError(node *Node, err error) error {
+	var wfErr *WorkflowError
+	if errors.As(err, &wfErr) {
+		return err
+	}
+	return &WorkflowError{
+		Stack: []StackFrame{{Workflow: e.workflow.Name(), Node: node.Label()}},
+		Err:   err,
+	}
+}
+
+// SetCatalogDir sets the directory where callWorkflow looks up workflows by name.
+func (e *Engine) SetCatalogDir(dir string) {
+	e.catalogDir = dir
+}
+
+// SetMaxCallDepth changes how deeply callWorkflow nodes may nest.
+func (e *Engine) SetMaxCallDepth(depth int) {
+	if depth > 0 {
+		e.maxCallDepth = depth
+	}
+}
+
+func (e *Engine) executeCallWorkflow(node *Node) error {
+	if e.callDepth >= e.maxCallDepth {
+		return fmt.Errorf("call depth limit (%d) exceeded calling %q", e.maxCallDepth, node.Workflow)
+	}
+
+	callee, err := e.loadCallee(node.Workflow)
+	if err != nil {
+		return err
+	}
+
+	step := e.step
+	context, restored := e.calleeContext(step)
+	if !restored {
+		params, err := e.resolveParams(node.Params)
+		if err != nil {
+			return err
+		}
+		context = NewContext(params)
+		context.secrets = e.context.secrets
+	}
+
+	logger.LogInfo("Call workflow: %s", callee.Name())
+	e.planNote("call %s", callee.Name())
+
+	caller, callerContext := e.workflow, e.context
+	e.workflow, e.context = callee, context
+	e.calls = append(e.calls, callFrame{step: step, context: context})
+	e.callDepth++
+	callErr := e.executeNode(callee.Graph)
+	e.callDepth--
+	e.calls = e.calls[:len(e.calls)-1]
+	e.workflow, e.context = caller, callerContext
+
+	if callErr != nil {
+		var wfErr *WorkflowError
+		if errors.As(callErr, &wfErr) {
+			stack := append([]StackFrame{{Workflow: caller.Name(), Node: node.Label()}}, wfErr.Stack...)
+			return &WorkflowError{Stack: stack, Err: wfErr.Err}
+		}
+		return callErr
+	}
+
+	// A call that finished before the checkpoint already copied its outputs
+	// into the restored context.
+	if e.replaying(e.step) {
+		return nil
+	}
+
+	for _, target := range sortedStringKeys(node.Outputs) {
+		source := node.Outputs[target]
+		value, ok := context.Get(source)
+		if !ok {
+			return fmt.Errorf("output %s not found in %s", source, callee.Name())
+		}
+		if err := e.context.Set(target, value); err != nil {
+			return err
+		}
+	}
+
+	logger.LogSuccess("Workflow returned: %s", callee.Name())
+	return nil
+}
+
+// loadCallee finds a workflow by catalog name or by path relative to the calling workflow.
+// A catalog name resolves to <name>.json, or <name>.yaml or <name>.yml when there is no JSON file.
+func (e *Engine) loadCallee(ref string) (*Workflow, error) {
+	path := ref
+	if !strings.HasSuffix(ref, ".json") && !IsYAML(ref) {
+		path = catalogFile(e.catalogDir, ref)
+	} else if !filepath.IsAbs(ref) && e.workflow.Path() != "" {
+		path = filepath.Join(filepath.Dir(e.workflow.Path()), ref)
+	}
+
+	if workflow, ok := e.loaded[path]; ok {
+		return workflow, nil
+	}
+	workflow, err := LoadWorkflow(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load workflow %q: %w", ref, err)
+	}
+	e.loaded[path] = workflow
+	return workflow, nil
+}
+
+// CatalogPath returns the file of the workflow called name in the catalog directory dir.
+// Unlike callWorkflow references, name cannot be a path, so it is safe to take from
+// untrusted input.
+func CatalogPath(dir, name string) (string, error) {
+	if !catalogNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid workflow name %q: expected a catalog name such as \"checkout\"", name)
+	}
+	if dir == "" {
+		return "", fmt.Errorf("cannot find workflow %q: no workflow catalog configured", name)
+	}
+	return catalogFile(dir, name), nil
+}
+
+// catalogFile returns the file of a catalog workflow, preferring JSON.
+func catalogFile(dir, name string) string {
+	for _, ext := range []string{".json", ".yaml", ".yml"} {
+		path := filepath.Join(dir, name+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(dir, name+".json")
+}
+
+// resolveParams builds the callee's user scope from params, resolving string values
+// with resolveValue. Values derived from secrets are encrypted again.
+func (e *Engine) resolveParams(params map[string]interface{}) (map[string]interface{}, error) {
+	resolved := make(map[string]interface{}, len(params))
+	for key, raw := range params {
+		text, ok := raw.(string)
+		if !ok {
+			resolved[key] = raw
+			continue
+		}
+		value, err := e.resolveValue(text)
+		if err != nil {
+			return nil, err
+		}
+		if resolved[key], err = e.secrets.seal(value); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// resolveValue resolves a template string. A string that is exactly one {{path}} reference
+// passes the referenced value unchanged, so arrays and objects keep their type and secrets
+// stay encrypted.
+func (e *Engine) resolveValue(text string) (interface{}, error) {
+	trimmed := strings.TrimSpace(text)
+	if strings.HasPrefix(trimmed, "{{") && findTemplateEnd(trimmed, 2) == len(trimmed)-2 {
+		expr, err := parseTemplateExpr(strings.TrimSpace(trimmed[2 : len(trimmed)-2]))
+		if err != nil {
+			return nil, err
+		}
+		value, found, err := evaluateTemplateExpr(e.context, expr)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return value, nil
+		}
+	}
+	return e.resolveString(text)
+}
@@ -0,0 +1,293 @@
+package traverser
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unaryCheckOperators test the data alone and take no expectedValue.
+var unaryCheckOperators = map[string]bool{"exists": true, "notExists": true, "isEmpty": true}
+
+// checkTypes are the expectedValue names accepted by the isType operator.
+var checkTypes = []string{"string", "number", "boolean", "array", "object", "null", "date"}
+
+// dateLayouts are the string formats compared as dates.
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+
+// String describes the check for logs, plans and graphs, e.g. "user.age between [18,65]".
+func (c *DataCheck) String() string {
+	if unaryCheckOperators[c.Operator] {
+		return c.DataPath + " " + c.Operator
+	}
+	return fmt.Sprintf("%s %s %s", c.DataPath, c.Operator, formatValue(c.ExpectedValue))
+}
+
+// lookup returns the value at a check's dataPath. Paths starting with "$" are JSONPath: a
+// definite path such as "$.user.orders[0].total" returns the value itself, any other
+// path returns the array of matches and is found when something matched. Encrypted values
+// are revealed.
+func (c *Context) lookup(path string) (interface{}, bool, error) {
+	var value interface{}
+	var found bool
+	if isJSONPath(path) {
+		compiled, err := parseJSONPath(path)
+		if err != nil {
+			return nil, false, err
+		}
+		matches := compiled.evaluate(c.data, revealingResolver{ctx: c})
+		switch {
+		case compiled.definite():
+			if len(matches) == 1 {
+				value, found = matches[0], true
+			}
+		default:
+			value, found = matches, len(matches) > 0
+			if matches == nil {
+				value = []interface{}{}
+			}
+		}
+	} else {
+		value, found = c.Get(path)
+	}
+	if !found {
+		return nil, false, nil
+	}
+	value, err := c.revealAll(value)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// evaluateCheck applies a question node check to the value found at its dataPath. Only
+// exists, notExists and isEmpty accept missing data.
+func evaluateCheck(check *DataCheck, value interface{}, found bool) (bool, error) {
+	switch check.Operator {
+	case "exists":
+		return found, nil
+	case "notExists":
+		return !found, nil
+	case "isEmpty":
+		return !found || isEmptyValue(value), nil
+	}
+	if !found {
+		return false, fmt.Errorf("data not found: %s", check.DataPath)
+	}
+	return compareValues(value, check.Operator, check.ExpectedValue)
+}
+
+// compareValues applies a question node operator to a context value.
+func compareValues(actual interface{}, operator string, expected interface{}) (bool, error) {
+	switch operator {
+	case "equals":
+		return equalValues(actual, expected), nil
+	case "notEquals":
+		return !equalValues(actual, expected), nil
+	case "greaterThan", "lessThan":
+		order, err := orderValues(actual, expected)
+		if err != nil {
+			return false, err
+		}
+		if operator == "greaterThan" {
+			return order > 0, nil
+		}
+		return order < 0, nil
+	case "between":
+		bounds, ok := expected.([]interface{})
+		if !ok || len(bounds) != 2 {
+			return false, fmt.Errorf("between expects [min, max], got %s", formatValue(expected))
+		}
+		low, err := orderValues(actual, bounds[0])
+		if err != nil {
+			return false, err
+		}
+		high, err := orderValues(actual, bounds[1])
+		if err != nil {
+			return false, err
+		}
+		return low >= 0 && high <= 0, nil
+	case "in":
+		options, ok := expected.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("in expects an array, got %s", formatValue(expected))
+		}
+		for _, option := range options {
+			if equalValues(actual, option) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "contains":
+		switch v := actual.(type) {
+		case []interface{}:
+			for _, item := range v {
+				if equalValues(item, expected) {
+					return true, nil
+				}
+			}
+		case map[string]interface{}:
+			_, ok := v[formatValue(expected)]
+			return ok, nil
+		case string:
+			if b, ok := expected.(string); ok {
+				return strings.Contains(v, b), nil
+			}
+		}
+		return false, nil
+	case "matches":
+		pattern, ok := expected.(string)
+		if !ok {
+			return false, fmt.Errorf("matches expects a regular expression string, got %s", formatValue(expected))
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		return re.MatchString(formatValue(actual)), nil
+	case "isType":
+		return valueType(actual) == expected || (expected == "date" && isDate(actual)), nil
+	}
+	return false, fmt.Errorf("unknown operator: %s", operator)
+}
+
+// equalValues reports whether two values are equal by type. Numbers, numeric strings and
+// dates are equal when orderValues finds them equal, so "42" equals 42 but "042" does not
+// equal "42"; arrays and objects are
+// compared element by element. Other values of different types are never equal, so null is
+// not "" and true is not "true".
+func equalValues(a, b interface{}) bool {
+	switch x := a.(type) {
+	case nil:
+		return b == nil
+	case bool:
+		y, ok := b.(bool)
+		return ok && x == y
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equalValues(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for key, value := range x {
+			other, exists := y[key]
+			if !exists || !equalValues(value, other) {
+				return false
+			}
+		}
+		return true
+	}
+	switch b.(type) {
+	case nil, bool, []interface{}, map[string]interface{}:
+		return false
+	}
+	order, err := orderValues(a, b)
+	return err == nil && order == 0
+}
+
+// orderValues compares two values by type. Numbers compare numerically, and a numeric
+// string compares as a number against a number, never against another string: "01234" is
+// not "1234". Dates compare chronologically, other strings lexically. Anything else cannot
+// be ordered.
+func orderValues(a, b interface{}) (int, error) {
+	if x, ok := toFloat(a); ok {
+		if y, ok := numericValue(b); ok {
+			return cmp.Compare(x, y), nil
+		}
+	}
+	if y, ok := toFloat(b); ok {
+		if x, ok := numericValue(a); ok {
+			return cmp.Compare(x, y), nil
+		}
+	}
+	if x, ok := dateValue(a); ok {
+		if y, ok := dateValue(b); ok {
+			return x.Compare(y), nil
+		}
+	}
+	if x, ok := a.(string); ok {
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %s with %s", valueType(a), valueType(b))
+}
+
+// numericValue returns a number or a string holding one as a float.
+func numericValue(value interface{}) (float64, bool) {
+	if s, ok := value.(string); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return f, err == nil
+	}
+	return toFloat(value)
+}
+
+// dateValue returns a time or a string in one of dateLayouts as a time.
+func dateValue(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func isDate(value interface{}) bool {
+	_, ok := dateValue(value)
+	return ok
+}
+
+// isEmptyValue reports whether a value is null, an empty string, array or object.
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// valueType names the type of a context value as the isType operator spells it.
+func valueType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case time.Time:
+		return "date"
+	}
+	if _, ok := toFloat(value); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,288 @@
+package traverser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"rpa-dfs-engine/internal/logger"
+)
+
+// Checkpoint is the saved position of a run, written after every completed action.
+//
+// Resuming replays the workflow from its root without touching the browser:
+// Step counts the nodes started so far, Decisions holds every branch outcome and
+// forEach answer in order, and Failures holds the error kind of each action that
+// failed (and was recovered) by step. Replay ends after Step, so actions that
+// already succeeded are never performed twice.
+type Checkpoint struct {
+	RunID        string                 `json:"runId"`
+	WorkflowPath string                 `json:"workflowPath"`
+	Strict       bool                   `json:"strict"`
+	Assertions   string                 `json:"assertions,omitempty"`
+	Answer       string                 `json:"answer,omitempty"`
+	Workflow     string                 `json:"workflow"`
+	Node         string                 `json:"node"`
+	Step         int                    `json:"step"`
+	Iterator     interface{}            `json:"iterator"`
+	URL          string                 `json:"url,omitempty"`
+	Context      map[string]interface{} `json:"context"`
+	Calls        []CallCheckpoint       `json:"calls,omitempty"`
+	Decisions    []bool                 `json:"decisions"`
+	Failures     map[int]string         `json:"failures,omitempty"`
+	SavedAt      time.Time              `json:"savedAt"`
+}
+
+// CallCheckpoint is the context of a callWorkflow that was active when the checkpoint was saved.
+type CallCheckpoint struct {
+	Step    int                    `json:"step"`
+	Context map[string]interface{} `json:"context"`
+}
+
+// callFrame is an active callWorkflow node and the callee's context.
+type callFrame struct {
+	step    int
+	context *Context
+}
+
+// replayedError stands in for a failure recorded before the run was resumed.
+type replayedError struct {
+	kind string
+}
+
+func (e *replayedError) Error() string {
+	return fmt.Sprintf("%s error (replayed from checkpoint)", e.kind)
+}
+
+// RunDir returns the directory holding a run's checkpoint and result.
+func RunDir(runsDir, runID string) string {
+	return filepath.Join(runsDir, runID)
+}
+
+// CheckpointPath returns where the checkpoint of a run is stored.
+func CheckpointPath(runsDir, runID string) string {
+	return filepath.Join(RunDir(runsDir, runID), "checkpoint.json")
+}
+
+// ResultPath returns where the result of a run is stored.
+func ResultPath(runsDir, runID string) string {
+	return filepath.Join(RunDir(runsDir, runID), "result.json")
+}
+
+// LoadCheckpoint reads a checkpoint file.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading checkpoint: %w", err)
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("error parsing checkpoint: %w", err)
+	}
+	return &checkpoint, nil
+}
+
+// SetRunsDir enables checkpoints, stored under dir/<run-id>/. They are removed when the run succeeds.
+func (e *Engine) SetRunsDir(dir string) {
+	e.runsDir = dir
+}
+
+// Resume runs the workflow again, skipping everything completed before the checkpoint was saved.
+// The workflow must be the one the checkpoint was taken from.
+func (e *Engine) Resume(checkpoint *Checkpoint) error {
+	e.runID = checkpoint.RunID
+	e.strict = checkpoint.Strict
+	e.collectAssertions = checkpoint.Assertions == AssertCollect
+	if e.answer == "" {
+		e.answer = checkpoint.Answer
+	}
+	e.resume = checkpoint
+	defer func() { e.resume = nil }()
+	return e.Execute()
+}
+
+// startProgress resets the replay state for a new run, restoring it from the checkpoint when resuming.
+func (e *Engine) startProgress() error {
+	e.step = 0
+	e.decisions = nil
+	e.decisionIndex = 0
+	e.failures = make(map[int]string)
+	e.calls = nil
+	e.secrets = &secretStore{key: e.secretKey, masked: e.dryRun}
+
+	if e.resume == nil {
+		e.context.secrets = e.secrets
+		e.rootContext = e.context
+		return e.checkSecrets()
+	}
+
+	if _, err := decodeSecrets(e.resume.Context, ""); err != nil {
+		return fmt.Errorf("invalid checkpoint context: %w", err)
+	}
+	e.context = &Context{data: e.resume.Context, secrets: e.secrets}
+	e.rootContext = e.context
+	if err := e.checkSecrets(); err != nil {
+		return err
+	}
+	e.decisions = append(e.decisions, e.resume.Decisions...)
+	for step, kind := range e.resume.Failures {
+		e.failures[step] = kind
+	}
+
+	logger.LogInfo("Resuming run %s after step %d (%s#%s)", e.resume.RunID, e.resume.Step, e.resume.Workflow, e.resume.Node)
+	if e.resume.URL != "" {
+		if err := e.browser.NavigateTo(e.resume.URL); err != nil {
+			return fmt.Errorf("cannot restore page %s: %w", e.resume.URL, err)
+		}
+	}
+	return nil
+}
+
+// beginStep numbers the node about to run.
+func (e *Engine) beginStep() int {
+	e.step++
+	return e.step
+}
+
+// replaying reports whether step was already completed before the run was resumed.
+func (e *Engine) replaying(step int) bool {
+	return e.resume != nil && step <= e.resume.Step
+}
+
+// replayAction returns the recorded outcome of an action instead of performing it.
+func (e *Engine) replayAction(node *Node, step int) error {
+	if step == e.resume.Step {
+		if e.workflow.Name() != e.resume.Workflow || e.workflow.Pointer(node) != e.resume.Node {
+			return fmt.Errorf("checkpoint does not match workflow: expected %s#%s at step %d, found %s#%s",
+				e.resume.Workflow, e.resume.Node, step, e.workflow.Name(), e.workflow.Pointer(node))
+		}
+		logger.LogInfo("Replay complete, continuing after %s", node.Label())
+	}
+	if kind, failed := e.failures[step]; failed {
+		return &replayedError{kind: kind}
+	}
+	return nil
+}
+
+// decide returns the next recorded decision while replaying, otherwise evaluates and records it.
+func (e *Engine) decide(evaluate func() (bool, error)) (bool, error) {
+	if e.decisionIndex < len(e.decisions) {
+		decision := e.decisions[e.decisionIndex]
+		e.decisionIndex++
+		return decision, nil
+	}
+	decision, err := evaluate()
+	if err != nil {
+		return false, err
+	}
+	e.decisions = append(e.decisions, decision)
+	e.decisionIndex++
+	return decision, nil
+}
+
+// recordFailure remembers a failed action so a resumed run takes the same recovery path.
+func (e *Engine) recordFailure(step int, err error) {
+	var wfErr *WorkflowError
+	if !errors.As(err, &wfErr) {
+		e.failures[step] = ErrorKind(err)
+	}
+}
+
+// checkSecrets fails the run before its first action when the user scope holds secrets
+// that the key cannot decrypt. A dry run never decrypts them.
+func (e *Engine) checkSecrets() error {
+	if e.dryRun {
+		return nil
+	}
+	return e.secrets.check(e.context.data["user"], "user")
+}
+
+// calleeContext returns the context a callWorkflow node was using when the checkpoint was saved.
+func (e *Engine) calleeContext(step int) (*Context, bool) {
+	if e.resume == nil {
+		return nil, false
+	}
+	for _, call := range e.resume.Calls {
+		if call.Step == step {
+			if _, err := decodeSecrets(call.Context, ""); err != nil {
+				return nil, false
+			}
+			return &Context{data: call.Context, secrets: e.secrets}, true
+		}
+	}
+	return nil, false
+}
+
+// saveCheckpoint records the run's position after the action at step completed.
+func (e *Engine) saveCheckpoint(node *Node, step int) {
+	if e.runsDir == "" || e.plan != nil {
+		return
+	}
+
+	// Values revealed into the context, e.g. by storeAs, are written encrypted.
+	context, err := e.secrets.seal(e.rootContext.data)
+	if err != nil {
+		logger.LogWarning("Could not save checkpoint: %v", err)
+		return
+	}
+	checkpoint := Checkpoint{
+		RunID:        e.result.RunID,
+		WorkflowPath: e.rootWorkflow.Path(),
+		Strict:       e.strict,
+		Assertions:   e.assertionMode(),
+		Answer:       e.answer,
+		Workflow:     e.workflow.Name(),
+		Node:         e.workflow.Pointer(node),
+		Step:         step,
+		Iterator:     e.context.iterator(),
+		Context:      context.(map[string]interface{}),
+		Decisions:    e.decisions,
+		Failures:     e.failures,
+		SavedAt:      time.Now(),
+	}
+	for _, call := range e.calls {
+		context, err := e.secrets.seal(call.context.data)
+		if err != nil {
+			logger.LogWarning("Could not save checkpoint: %v", err)
+			return
+		}
+		checkpoint.Calls = append(checkpoint.Calls, CallCheckpoint{Step: call.step, Context: context.(map[string]interface{})})
+	}
+	if url, err := e.browser.CurrentURL(); err == nil {
+		checkpoint.URL = url
+	}
+
+	if err := writeFileAtomic(CheckpointPath(e.runsDir, checkpoint.RunID), &checkpoint); err != nil {
+		logger.LogWarning("Could not save checkpoint: %v", err)
+	}
+}
+
+// clearCheckpoint removes the checkpoint of a run that finished successfully.
+func (e *Engine) clearCheckpoint() {
+	if e.runsDir == "" || e.plan != nil {
+		return
+	}
+	path := CheckpointPath(e.runsDir, e.result.RunID)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		logger.LogWarning("Could not remove checkpoint: %v", err)
+	}
+}
+
+// writeFileAtomic writes value as JSON through a temporary file so a crash never leaves a partial file.
+func writeFileAtomic(path string, value interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
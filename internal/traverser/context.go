@@ -0,0 +1,297 @@
+package traverser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Context holds the data available to templates and checks.
+// Top-level scopes are "user" (loaded data), "iterator" (managed by loops)
+// and "vars" (values extracted from pages during the run).
+type Context struct {
+	data map[string]interface{}
+
+	// secrets decrypts the Secret values of the context when they are read.
+	secrets *secretStore
+}
+
+// NewContext creates a context whose user scope is userData.
+func NewContext(userData map[string]interface{}) *Context {
+	if userData == nil {
+		userData = make(map[string]interface{})
+	}
+	return &Context{
+		data: map[string]interface{}{
+			"user":     userData,
+			"iterator": make(map[string]interface{}),
+			"vars":     make(map[string]interface{}),
+		},
+	}
+}
+
+// LoadContextFile reads a context file of the form {"user": {...}}, JSON or YAML
+// depending on its extension, and returns the user scope.
+func LoadContextFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading context file: %w", err)
+	}
+	if IsYAML(path) {
+		return ParseContextYAML(data)
+	}
+	return ParseContext(data)
+}
+
+// ParseContext parses a context JSON document of the form {"user": {...}}
+// and returns the user scope. Encrypted {"$secret": "enc:..."} values become Secret.
+func ParseContext(data []byte) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing context: %w", err)
+	}
+	user, ok := doc["user"].(map[string]interface{})
+	if !ok {
+		if _, exists := doc["user"]; exists {
+			return nil, fmt.Errorf("context \"user\" must be an object")
+		}
+		user = make(map[string]interface{})
+	}
+	if _, err := decodeSecrets(user, "user"); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Get resolves a path such as "user.profile.email" or "user.files[iterator.index]".
+// Surrounding {{ }} are ignored so dataSource values can be passed as written.
+func (c *Context) Get(path string) (interface{}, bool) {
+	segments, err := splitPath(trimTemplate(path))
+	if err != nil || len(segments) == 0 {
+		return nil, false
+	}
+
+	var current interface{} = c.data
+	for _, segment := range segments {
+		if segment.isIndex {
+			index, ok := c.resolveIndex(segment.value)
+			if !ok {
+				return nil, false
+			}
+			arr, ok := current.([]interface{})
+			if !ok || index < 0 || index >= len(arr) {
+				return nil, false
+			}
+			current = arr[index]
+			continue
+		}
+
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = obj[segment.value]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// Set stores value at a path such as "user.session.id", creating intermediate objects.
+// The iterator scope is managed by loops and cannot be written.
+func (c *Context) Set(path string, value interface{}) error {
+	segments, err := splitPath(trimTemplate(path))
+	if err != nil {
+		return err
+	}
+	if len(segments) < 2 || segments[0].isIndex {
+		return fmt.Errorf("cannot set %q: path must name a scope and a key", path)
+	}
+	if segments[0].value == "iterator" {
+		return fmt.Errorf("cannot set %q: iterator is read-only", path)
+	}
+
+	var current interface{} = c.data
+	for i, segment := range segments {
+		last := i == len(segments)-1
+
+		if segment.isIndex {
+			arr, ok := current.([]interface{})
+			index, indexOk := c.resolveIndex(segment.value)
+			if !ok || !indexOk || index < 0 || index >= len(arr) {
+				return fmt.Errorf("cannot set %q: index %s out of range", path, segment.value)
+			}
+			if last {
+				arr[index] = value
+				return nil
+			}
+			current = arr[index]
+			continue
+		}
+
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("cannot set %q: %s is not an object", path, segment.value)
+		}
+		if last {
+			obj[segment.value] = value
+			return nil
+		}
+		child, exists := obj[segment.value]
+		if !exists || child == nil {
+			child = make(map[string]interface{})
+			obj[segment.value] = child
+		}
+		current = child
+	}
+	return nil
+}
+
+// SetIterator replaces the iterator scope for the current forEach item.
+func (c *Context) SetIterator(index, total int) {
+	c.data["iterator"] = map[string]interface{}{
+		"index": index,
+		"count": index + 1,
+		"total": total,
+	}
+}
+
+// setItem sets the iterator scope for a forEach item. A negative total is unknown
+// (streamed sources) and is left out.
+func (c *Context) setItem(index, total int, item interface{}) {
+	c.SetIterator(index, total)
+	iterator := c.data["iterator"].(map[string]interface{})
+	iterator["item"] = item
+	if total < 0 {
+		delete(iterator, "total")
+	}
+}
+
+// setPass sets the iterator scope for a pass of a while or until loop, which has no total.
+func (c *Context) setPass(index int) {
+	c.SetIterator(index, 0)
+	delete(c.data["iterator"].(map[string]interface{}), "total")
+}
+
+// clone returns a deep copy of the context, for a parallel branch.
+func (c *Context) clone() *Context {
+	return &Context{data: deepCopy(c.data).(map[string]interface{}), secrets: c.secrets}
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = deepCopy(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = deepCopy(item)
+		}
+		return copied
+	}
+	return value
+}
+
+// iterator returns the current iterator scope so loops can restore it when they finish.
+func (c *Context) iterator() interface{} {
+	return c.data["iterator"]
+}
+
+func (c *Context) restoreIterator(iterator interface{}) {
+	c.data["iterator"] = iterator
+}
+
+func (c *Context) resolveIndex(expr string) (int, bool) {
+	expr = strings.TrimSpace(expr)
+	if index, err := strconv.Atoi(expr); err == nil {
+		return index, true
+	}
+	value, ok := c.Get(expr)
+	if !ok {
+		return 0, false
+	}
+	return toInt(value)
+}
+
+type pathSegment struct {
+	value   string
+	isIndex bool
+}
+
+// splitPath splits "a.b[c.d].e" into key and index segments.
+// Index expressions may themselves be paths and may nest brackets.
+func splitPath(path string) ([]pathSegment, error) {
+	var segments []pathSegment
+	var key strings.Builder
+
+	flushKey := func() {
+		if key.Len() > 0 {
+			segments = append(segments, pathSegment{value: key.String()})
+			key.Reset()
+		}
+	}
+
+	for i := 0; i < len(path); i++ {
+		switch ch := path[i]; ch {
+		case '.':
+			flushKey()
+		case '[':
+			flushKey()
+			depth := 1
+			start := i + 1
+			for i++; i < len(path) && depth > 0; i++ {
+				switch path[i] {
+				case '[':
+					depth++
+				case ']':
+					depth--
+				}
+			}
+			if depth != 0 {
+				return nil, fmt.Errorf("unclosed '[' in path %q", path)
+			}
+			i--
+			segments = append(segments, pathSegment{value: path[start:i], isIndex: true})
+		case ']':
+			return nil, fmt.Errorf("unexpected ']' in path %q", path)
+		case ' ', '\t':
+		default:
+			key.WriteByte(ch)
+		}
+	}
+	flushKey()
+	return segments, nil
+}
+
+func trimTemplate(path string) string {
+	path = strings.TrimSpace(path)
+	if strings.HasPrefix(path, "{{") && strings.HasSuffix(path, "}}") {
+		path = strings.TrimSpace(path[2 : len(path)-2])
+	}
+	return path
+}
+
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		if v != float64(int(v)) {
+			return 0, false
+		}
+		return int(v), true
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		return i, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,209 @@
+package traverser
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Answer policies for forEach questions.
+const (
+	AnswerAsk    = "ask"
+	AnswerAlways = "always"
+	AnswerNever  = "never"
+)
+
+var answerPolicies = []string{AnswerAsk, AnswerAlways, AnswerNever}
+
+// rowSource yields forEach items one at a time. Next returns io.EOF after the last item.
+type rowSource interface {
+	Next() (interface{}, error)
+	Close() error
+}
+
+// isDataFile reports whether a forEach dataSource names a file rather than a context path.
+func isDataFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".jsonl", ".ndjson", ".xlsx":
+		return true
+	}
+	return false
+}
+
+// openDataSource returns the items of a forEach node and their number, or -1 when the
+// items are streamed from a file and the total is not known in advance. Relative file
+// paths are resolved against the directory of the running workflow, as callWorkflow does.
+func (e *Engine) openDataSource(node *Node) (rowSource, int, string, error) {
+	path := ""
+	if isDataFile(node.DataSource) {
+		resolved, err := e.resolveString(node.DataSource)
+		if err != nil {
+			return nil, 0, "", err
+		}
+		path = resolved
+	} else {
+		data, exists := e.context.Get(node.DataSource)
+		if !exists {
+			return nil, 0, "", fmt.Errorf("data source not found: %s", node.DataSource)
+		}
+		if file, ok := data.(string); ok && isDataFile(file) {
+			path = file
+		} else {
+			arr, ok := data.([]interface{})
+			if !ok {
+				return nil, 0, "", fmt.Errorf("data source is not array: %s", node.DataSource)
+			}
+			return &sliceSource{items: arr}, len(arr), node.DataSource, nil
+		}
+	}
+
+	if !filepath.IsAbs(path) && e.workflow.Path() != "" {
+		path = filepath.Join(filepath.Dir(e.workflow.Path()), path)
+	}
+	source, err := openDataFile(path, node.Columns, node.Sheet)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("data source %s: %w", path, err)
+	}
+	return source, -1, path, nil
+}
+
+// openDataFile opens a CSV, JSON Lines or XLSX file. Columns renames CSV headers
+// and object keys; sheet selects an XLSX worksheet by name (default: the first).
+func openDataFile(path string, columns map[string]string, sheet string) (rowSource, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xlsx":
+		return openXLSX(path, sheet, columns)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		source, err := newCSVSource(file, columns)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return source, nil
+	}
+	return &jsonlSource{file: file, decoder: json.NewDecoder(file), columns: columns}, nil
+}
+
+type sliceSource struct {
+	items []interface{}
+	next  int
+}
+
+func (s *sliceSource) Next() (interface{}, error) {
+	if s.next >= len(s.items) {
+		return nil, io.EOF
+	}
+	s.next++
+	return s.items[s.next-1], nil
+}
+
+func (s *sliceSource) Close() error {
+	return nil
+}
+
+// csvSource reads one row per item, keyed by the header row.
+type csvSource struct {
+	file   *os.File
+	reader *csv.Reader
+	header []string
+}
+
+func newCSVSource(file *os.File, columns map[string]string) (*csvSource, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("missing header row")
+	}
+	if err != nil {
+		return nil, err
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	return &csvSource{file: file, reader: reader, header: mapColumns(header, columns)}, nil
+}
+
+func (s *csvSource) Next() (interface{}, error) {
+	record, err := s.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	return rowItem(s.header, record), nil
+}
+
+func (s *csvSource) Close() error {
+	return s.file.Close()
+}
+
+// jsonlSource reads one JSON value per item.
+type jsonlSource struct {
+	file    *os.File
+	decoder *json.Decoder
+	columns map[string]string
+	line    int
+}
+
+func (s *jsonlSource) Next() (interface{}, error) {
+	var item interface{}
+	if err := s.decoder.Decode(&item); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("item %d: %w", s.line+1, err)
+	}
+	s.line++
+	if object, ok := item.(map[string]interface{}); ok && len(s.columns) > 0 {
+		renamed := make(map[string]interface{}, len(object))
+		for key, value := range object {
+			if name, ok := s.columns[key]; ok {
+				key = name
+			}
+			renamed[key] = value
+		}
+		item = renamed
+	}
+	return item, nil
+}
+
+func (s *jsonlSource) Close() error {
+	return s.file.Close()
+}
+
+// mapColumns renames headers found in columns and trims the others.
+func mapColumns(header []string, columns map[string]string) []string {
+	names := make([]string, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if mapped, ok := columns[name]; ok {
+			name = mapped
+		}
+		names[i] = name
+	}
+	return names
+}
+
+// rowItem pairs a row's cells with the header; missing cells are empty strings.
+func rowItem(header, cells []string) map[string]interface{} {
+	item := make(map[string]interface{}, len(header))
+	for i, name := range header {
+		if name == "" {
+			continue
+		}
+		value := ""
+		if i < len(cells) {
+			value = cells[i]
+		}
+		item[name] = value
+	}
+	return item
+}
@@ -0,0 +1,266 @@
+package traverser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrDebugQuit is returned when the run is stopped from the debugger.
+var ErrDebugQuit = errors.New("run stopped from debugger")
+
+// SelectorInfo describes what a selector matches on the live page.
+type SelectorInfo struct {
+	Count   int    `json:"count"`
+	Visible bool   `json:"visible"`
+	Enabled bool   `json:"enabled"`
+	Tag     string `json:"tag,omitempty"`
+	Text    string `json:"text,omitempty"`
+}
+
+// selectorInspector is implemented by browsers that can evaluate a selector on the current page.
+type selectorInspector interface {
+	InspectSelector(selector string) (SelectorInfo, error)
+}
+
+type debugMode int
+
+const (
+	debugContinue debugMode = iota
+	debugStep
+	debugStepOver
+)
+
+// Debugger pauses a run before nodes matching a breakpoint. While paused it reads commands
+// from the engine's input: step, step over, inspect and edit the context, evaluate a selector
+// on the live page, and continue.
+type Debugger struct {
+	breakpoints map[string]bool
+	mode        debugMode
+	overDepth   int
+}
+
+// NewDebugger creates a debugger that breaks at nodes whose id or nodeType is in breakpoints.
+// Without breakpoints it stops before the first node.
+func NewDebugger(breakpoints []string) *Debugger {
+	d := &Debugger{breakpoints: make(map[string]bool)}
+	for _, breakpoint := range breakpoints {
+		if breakpoint = strings.TrimSpace(breakpoint); breakpoint != "" {
+			d.breakpoints[breakpoint] = true
+		}
+	}
+	if len(d.breakpoints) == 0 {
+		d.mode = debugStep
+	}
+	return d
+}
+
+// SetDebugger attaches a debugger that is consulted before every node.
+func (e *Engine) SetDebugger(debugger *Debugger) {
+	e.debugger = debugger
+}
+
+// shouldStop reports whether the run pauses before node at depth.
+func (d *Debugger) shouldStop(node *Node, depth int) bool {
+	switch {
+	case d.breakpoints[node.NodeType] || (node.ID != "" && d.breakpoints[node.ID]):
+		return true
+	case d.mode == debugStep:
+		return true
+	case d.mode == debugStepOver:
+		return depth <= d.overDepth
+	}
+	return false
+}
+
+// pause stops before node when required and handles commands until the user resumes.
+func (d *Debugger) pause(e *Engine, node *Node) error {
+	if !d.shouldStop(node, e.depth) {
+		return nil
+	}
+
+	d.describe(e, node)
+	for {
+		fmt.Fprint(e.output, "(debug) ")
+		line, err := e.input.ReadString('\n')
+		if err != nil && line == "" {
+			return ErrDebugQuit
+		}
+
+		command, arg := splitCommand(line)
+		switch command {
+		case "", "s", "step":
+			d.mode = debugStep
+			return nil
+		case "n", "next":
+			d.mode, d.overDepth = debugStepOver, e.depth
+			return nil
+		case "c", "continue":
+			d.mode = debugContinue
+			return nil
+		case "q", "quit":
+			return ErrDebugQuit
+		case "p", "print":
+			d.print(e, arg)
+		case "set":
+			d.set(e, arg)
+		case "$", "eval":
+			d.inspect(e, arg)
+		case "b", "break":
+			if arg != "" {
+				d.breakpoints[arg] = true
+			}
+			d.listBreakpoints(e)
+		case "d", "delete":
+			delete(d.breakpoints, arg)
+			d.listBreakpoints(e)
+		case "w", "where":
+			d.describe(e, node)
+		case "h", "help":
+			fmt.Fprint(e.output, debugHelp)
+		default:
+			d.printf(e, "unknown command %q, type help\n", command)
+		}
+	}
+}
+
+const debugHelp = `  step, s (or Enter)   run this node and stop at the next one
+  next, n              run this node, stopping again at this depth (steps over sequences, loops and calls)
+  continue, c          run until the next breakpoint
+  print, p [path]      show the context, or the value at path
+  set <path> <json>    change a context value, e.g. set user.email "a@b.c"
+  eval, $ <selector>   evaluate a selector on the live page
+  break, b <id|type>   add a breakpoint
+  delete, d <id|type>  remove a breakpoint
+  where, w             show the current node again
+  quit, q              stop the run
+`
+
+// debuggerFields are the node properties describe always lists.
+var debuggerFields = map[string]bool{
+	"url": true, "selector": true, "value": true, "filePath": true, "urlPattern": true,
+	"script": true, "conditionExpression": true, "dataSource": true, "workflow": true,
+}
+
+func (d *Debugger) describe(e *Engine, node *Node) {
+	d.printf(e, "⏸  %s (%s) at %s#%s\n", node.Label(), node.NodeType, e.workflow.Name(), e.workflow.Pointer(node))
+	fields := []struct{ name, value string }{
+		{"url", node.URL},
+		{"selector", node.Selector},
+		{"value", node.Value},
+		{"filePath", node.FilePath},
+		{"urlPattern", node.URLPattern},
+		{"script", node.Script},
+		{"condition", node.ConditionExpression},
+		{"dataSource", node.DataSource},
+		{"workflow", node.Workflow},
+	}
+	// Custom node types show their own string properties; shared ones such as selector are listed above.
+	for _, name := range sortedKeys(node.Properties) {
+		if value, ok := node.Properties[name].(string); ok && !debuggerFields[name] {
+			fields = append(fields, struct{ name, value string }{name, value})
+		}
+	}
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		resolved, _, err := expandTemplate(e.context, field.value, nil)
+		if err != nil || resolved == field.value {
+			d.printf(e, "   %s: %s\n", field.name, field.value)
+			continue
+		}
+		d.printf(e, "   %s: %s → %s\n", field.name, field.value, resolved)
+	}
+	if len(e.trail) > 1 {
+		d.printf(e, "   path: %s\n", strings.Join(e.trail, " -> "))
+	}
+}
+
+func (d *Debugger) print(e *Engine, path string) {
+	var value interface{} = e.context.data
+	if path != "" {
+		var ok bool
+		if value, ok = e.context.Get(path); !ok {
+			d.printf(e, "%s is not set\n", path)
+			return
+		}
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		d.printf(e, "%v\n", value)
+		return
+	}
+	d.printf(e, "%s\n", data)
+}
+
+// set parses the value as JSON, falling back to a plain string.
+func (d *Debugger) set(e *Engine, arg string) {
+	path, raw := splitCommand(arg)
+	if path == "" || raw == "" {
+		d.printf(e, "usage: set <path> <json>\n")
+		return
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		value = raw
+	}
+	if err := e.context.Set(path, value); err != nil {
+		d.printf(e, "%v\n", err)
+		return
+	}
+	d.printf(e, "%s = %s\n", path, formatValue(value))
+}
+
+func (d *Debugger) inspect(e *Engine, selector string) {
+	inspector, ok := e.browser.(selectorInspector)
+	if !ok {
+		d.printf(e, "the browser cannot evaluate selectors\n")
+		return
+	}
+	if selector == "" {
+		d.printf(e, "usage: eval <selector>\n")
+		return
+	}
+	selector, _, err := expandTemplate(e.context, selector, nil)
+	if err != nil {
+		d.printf(e, "%v\n", err)
+		return
+	}
+	info, err := inspector.InspectSelector(selector)
+	if err != nil {
+		d.printf(e, "%v\n", err)
+		return
+	}
+	if info.Count == 0 {
+		d.printf(e, "%s matches nothing\n", selector)
+		return
+	}
+	d.printf(e, "%s matches %d element(s); first: <%s> visible=%t enabled=%t text=%q\n",
+		selector, info.Count, info.Tag, info.Visible, info.Enabled, info.Text)
+}
+
+// printf writes to the console with the secrets revealed so far masked, as in the logs.
+func (d *Debugger) printf(e *Engine, format string, args ...interface{}) {
+	fmt.Fprint(e.output, e.context.secrets.mask(fmt.Sprintf(format, args...)))
+}
+
+func (d *Debugger) listBreakpoints(e *Engine) {
+	var names []string
+	for name := range d.breakpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	d.printf(e, "breakpoints: %s\n", strings.Join(names, ", "))
+}
+
+// splitCommand splits "cmd rest of line" at the first space.
+func splitCommand(line string) (string, string) {
+	line = strings.TrimSpace(line)
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		return line[:i], strings.TrimSpace(line[i+1:])
+	}
+	return line, ""
+}
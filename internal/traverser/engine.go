@@ -0,0 +1,605 @@
+package traverser
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"rpa-dfs-engine/internal/logger"
+)
+
+// Browser is the set of page actions the engine needs.
+// browser.Session implements it on top of chromedp.
+type Browser interface {
+	NavigateTo(url string) error
+	FillField(selector, value string) error
+	ClickButton(selector string) error
+	UploadFile(selector, filePath string) error
+
+	// Text, Attribute and Value read from the first element matched by selector;
+	// Count returns how many elements match.
+	Text(selector string) (string, error)
+	Attribute(selector, name string) (string, error)
+	Value(selector string) (string, error)
+	Count(selector string) (int, error)
+
+	// Visible reports whether the first element matched by selector is displayed,
+	// false when nothing matches. CurrentURL and Title describe the current page.
+	Visible(selector string) (bool, error)
+	CurrentURL() (string, error)
+	Title() (string, error)
+
+	// Enabled, Truthy and NetworkIdle check a waitFor condition once, without waiting.
+	// Truthy evaluates a JavaScript expression in which args[i] are the given values;
+	// NetworkIdle reports whether no request has been in flight for at least quiet.
+	Enabled(selector string) (bool, error)
+	Truthy(script string, args []interface{}) (bool, error)
+	NetworkIdle(quiet time.Duration) (bool, error)
+
+	// Evaluate calls the JavaScript function script with args in the page, or in the
+	// document of the iframe matched by frame, awaits a returned promise and returns the
+	// JSON-decoded result. Exceptions are returned as *ScriptError.
+	Evaluate(frame, script string, args []interface{}) (interface{}, error)
+}
+
+// Engine walks a workflow graph depth-first and performs each node against the browser.
+type Engine struct {
+	workflow *Workflow
+	context  *Context
+	browser  Browser
+	input    *bufio.Reader
+	output   io.Writer
+	prompt   *sync.Mutex
+	strict   bool
+	runCtx   context.Context
+
+	maxVisits   int
+	visits      map[*Node]int
+	trail       []string
+	pendingJump *Node
+
+	catalogDir   string
+	maxCallDepth int
+	callDepth    int
+	depth        int
+	loaded       map[string]*Workflow
+
+	runID  string
+	result *RunResult
+
+	runsDir       string
+	resume        *Checkpoint
+	rootWorkflow  *Workflow
+	rootContext   *Context
+	step          int
+	decisions     []bool
+	decisionIndex int
+	failures      map[int]string
+	calls         []callFrame
+
+	dryRun bool
+	plan   *Plan
+	answer string
+
+	secretKey []byte
+	secrets   *secretStore
+
+	collectAssertions bool
+	assertionFailures []error
+
+	debugger *Debugger
+}
+
+// DefaultMaxVisits is how often a node may be entered by a jump when it declares no maxVisits.
+const DefaultMaxVisits = 100
+
+// trailLength is how many recently executed nodes are kept for cycle errors.
+const trailLength = 30
+
+// CycleError is returned when a jump target is entered more often than its visit limit.
+type CycleError struct {
+	NodeID    string
+	MaxVisits int
+	Path      []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("node %q exceeded %d visits, possible cycle; path: %s",
+		e.NodeID, e.MaxVisits, strings.Join(e.Path, " -> "))
+}
+
+// NewEngine creates an engine that drives the given browser.
+func NewEngine(browser Browser) *Engine {
+	return &Engine{
+		browser:      browser,
+		context:      NewContext(nil),
+		input:        bufio.NewReader(os.Stdin),
+		output:       os.Stdout,
+		prompt:       &sync.Mutex{},
+		runCtx:       context.Background(),
+		maxVisits:    DefaultMaxVisits,
+		maxCallDepth: DefaultMaxCallDepth,
+		loaded:       make(map[string]*Workflow),
+	}
+}
+
+// LoadWorkflow reads the workflow to execute from a JSON or YAML file.
+func (e *Engine) LoadWorkflow(workflowPath string) error {
+	workflow, err := LoadWorkflow(workflowPath)
+	if err != nil {
+		return err
+	}
+	e.workflow = workflow
+	return nil
+}
+
+// SetWorkflow sets an already parsed workflow.
+func (e *Engine) SetWorkflow(workflow *Workflow) {
+	e.workflow = workflow
+}
+
+// SetContext replaces the user data available to templates.
+func (e *Engine) SetContext(userData map[string]interface{}) {
+	e.context = NewContext(userData)
+}
+
+// Context returns the engine's current context.
+func (e *Engine) Context() *Context {
+	return e.context
+}
+
+// SetSecretKey sets the key that decrypts the context's {"$secret": "enc:..."} values.
+func (e *Engine) SetSecretKey(key []byte) {
+	e.secretKey = key
+}
+
+// SetIO sets where forEach questions are read from and written to.
+func (e *Engine) SetIO(input io.Reader, output io.Writer) {
+	e.input = bufio.NewReader(input)
+	e.output = output
+}
+
+// SetStrict makes unresolved template references fail the run instead of being left in place.
+func (e *Engine) SetStrict(strict bool) {
+	e.strict = strict
+}
+
+// SetAnswerPolicy answers every forEach question that would be asked: "always" processes
+// each item, "never" skips it and "ask" (the default) prompts. Nodes with their own
+// always or never policy keep it.
+func (e *Engine) SetAnswerPolicy(policy string) error {
+	if policy != "" && !containsString(answerPolicies, policy) {
+		return fmt.Errorf("unknown answer policy %q, expected one of %s", policy, strings.Join(answerPolicies, ", "))
+	}
+	e.answer = policy
+	return nil
+}
+
+// SetMaxVisits changes the default jump limit for nodes that declare no maxVisits.
+func (e *Engine) SetMaxVisits(maxVisits int) {
+	if maxVisits > 0 {
+		e.maxVisits = maxVisits
+	}
+}
+
+// SetRunID sets the id reported in the run result. By default it is derived from the start time.
+func (e *Engine) SetRunID(runID string) {
+	e.runID = runID
+}
+
+// Result returns the result of the last Execute call, or nil before the first run.
+func (e *Engine) Result() *RunResult {
+	return e.result
+}
+
+// Execute runs the loaded workflow from its root node.
+func (e *Engine) Execute() error {
+	if e.workflow == nil || e.workflow.Graph == nil {
+		return fmt.Errorf("no workflow loaded")
+	}
+	e.plan = nil
+	if e.dryRun {
+		e.plan = &Plan{secrets: make(map[string]bool)}
+		browser := e.browser
+		e.browser = &dryRunBrowser{engine: e}
+		defer func() { e.browser = browser }()
+	}
+	if e.browser == nil {
+		return fmt.Errorf("no browser configured")
+	}
+
+	e.visits = make(map[*Node]int)
+	e.trail = nil
+	e.assertionFailures = nil
+
+	started := time.Now()
+	runID := e.runID
+	if runID == "" {
+		runID = newRunID(started)
+	}
+	e.result = &RunResult{RunID: runID, Workflow: e.workflow.Name(), StartedAt: started}
+	e.rootWorkflow = e.workflow
+
+	logger.LogInfo("Starting workflow: %s", e.workflow.Metadata.Name)
+	err := e.startProgress()
+	if err == nil {
+		err = e.executeNode(e.workflow.Graph)
+	}
+	if err == nil && len(e.assertionFailures) > 0 {
+		err = &AssertionFailures{Failures: e.assertionFailures}
+	}
+	e.result.FinishedAt = time.Now()
+	if err != nil {
+		e.result.Status = RunFailed
+		e.result.Error = err.Error()
+		e.result.ErrorKind = ErrorKind(err)
+		e.result.redact(e.secrets)
+		logger.LogError("Workflow failed: %v", err)
+		return err
+	}
+	e.result.redact(e.secrets)
+	e.result.Status = RunSucceeded
+	e.clearCheckpoint()
+	logger.LogSuccess("Workflow completed: %s", e.workflow.Metadata.Name)
+	return nil
+}
+
+// executeNode performs a node's own action and then follows its continuation
+// until the chain ends.
+func (e *Engine) executeNode(node *Node) error {
+	e.depth++
+	defer func() { e.depth-- }()
+
+	for node != nil {
+		if e.runCtx.Err() != nil {
+			return errBranchCancelled
+		}
+		logger.LogDebug("Executing: %s", node.Label())
+		e.record(node)
+		if e.debugger != nil {
+			if err := e.debugger.pause(e, node); err != nil {
+				return e.nodeError(node, err)
+			}
+		}
+		e.beginPlanStep(node, e.depth-1)
+
+		next, err := e.runNode(node)
+		e.endPlanStep()
+		if err != nil {
+			return e.nodeError(node, err)
+		}
+		node = next
+	}
+	return nil
+}
+
+// runNode performs node and returns the node to continue with.
+func (e *Engine) runNode(node *Node) (*Node, error) {
+	if err := e.runWithPolicy(node); err != nil {
+		return nil, err
+	}
+	if e.pendingJump != nil {
+		next := e.pendingJump
+		e.pendingJump = nil
+		return next, nil
+	}
+	return e.nextNode(node)
+}
+
+// nextNode returns the node that follows node, resolving nextId jumps.
+func (e *Engine) nextNode(node *Node) (*Node, error) {
+	if node.NextID != "" {
+		return e.jump(node.NextID)
+	}
+	return node.continuation(), nil
+}
+
+// jump resolves a node id and enforces its visit limit.
+func (e *Engine) jump(id string) (*Node, error) {
+	target, ok := e.workflow.NodeByID(id)
+	if !ok {
+		return nil, fmt.Errorf("jump to unknown node id %q", id)
+	}
+
+	limit := target.MaxVisits
+	if limit <= 0 {
+		limit = e.maxVisits
+	}
+	e.visits[target]++
+	if e.visits[target] > limit {
+		path := append([]string(nil), e.trail...)
+		return nil, &CycleError{NodeID: id, MaxVisits: limit, Path: append(path, id)}
+	}
+
+	logger.LogDebug("Jump to %s (visit %d/%d)", id, e.visits[target], limit)
+	e.planNote("jump to %s", id)
+	return target, nil
+}
+
+// record appends node to the trail of recently executed nodes.
+func (e *Engine) record(node *Node) {
+	e.trail = append(e.trail, node.Label())
+	if len(e.trail) > trailLength {
+		e.trail = e.trail[len(e.trail)-trailLength:]
+	}
+}
+
+func (e *Engine) executeAction(node *Node) error {
+	switch node.NodeType {
+	case NodeTypeMoveToPage:
+		return e.executeMoveToPage(node)
+	case NodeTypeFillField:
+		return e.executeFillField(node)
+	case NodeTypeClickButton:
+		return e.executeClickButton(node)
+	case NodeTypeSendFile:
+		return e.executeSendFile(node)
+	case NodeTypeConditional:
+		return e.executeConditional(node)
+	case NodeTypeQuestion:
+		return e.executeQuestion(node)
+	case NodeTypeSequence:
+		return e.executeSequence(node)
+	case NodeTypeForEach:
+		return e.executeForEach(node)
+	case NodeTypeWhile, NodeTypeUntil:
+		return e.executeLoop(node)
+	case NodeTypeWait:
+		return e.executeWait(node)
+	case NodeTypeWaitFor:
+		return e.executeWaitFor(node)
+	case NodeTypeCall:
+		return e.executeCallWorkflow(node)
+	case NodeTypeEvaluate:
+		return e.executeEvaluate(node)
+	case NodeTypeParallel:
+		return e.executeParallel(node)
+	case NodeTypeExtractText, NodeTypeExtractAttribute, NodeTypeExtractValue, NodeTypeExtractCount:
+		return e.executeExtract(node)
+	case NodeTypeAssertVisible, NodeTypeAssertNotVisible, NodeTypeAssertText, NodeTypeAssertURL, NodeTypeAssertTitle:
+		return e.executeAssert(node)
+	default:
+		if nodeType, ok := customNodeType(node.NodeType); ok {
+			return e.executeCustom(nodeType, node)
+		}
+		return fmt.Errorf("unknown node: %s", node.NodeType)
+	}
+}
+
+func (e *Engine) executeMoveToPage(node *Node) error {
+	url, err := e.resolveString(node.URL)
+	if err != nil {
+		return err
+	}
+	logger.LogInfo("Navigate to: %s", url)
+
+	if err := e.browser.NavigateTo(url); err != nil {
+		return &ActionError{Action: NodeTypeMoveToPage, Target: url, Err: err}
+	}
+	return nil
+}
+
+func (e *Engine) executeFillField(node *Node) error {
+	selector, err := e.resolveString(node.Selector)
+	if err != nil {
+		return err
+	}
+	value, err := e.resolveString(node.Value)
+	if err != nil {
+		return err
+	}
+	logger.LogInfo("Fill field: %s", selector)
+
+	if err := e.browser.FillField(selector, value); err != nil {
+		return &ActionError{Action: NodeTypeFillField, Target: selector, Err: err}
+	}
+	return nil
+}
+
+func (e *Engine) executeClickButton(node *Node) error {
+	selector, err := e.resolveString(node.Selector)
+	if err != nil {
+		return err
+	}
+	logger.LogInfo("Click: %s", selector)
+
+	if err := e.browser.ClickButton(selector); err != nil {
+		return &ActionError{Action: NodeTypeClickButton, Target: selector, Err: err}
+	}
+	return nil
+}
+
+func (e *Engine) executeSendFile(node *Node) error {
+	selector, err := e.resolveString(node.Selector)
+	if err != nil {
+		return err
+	}
+	filePath, err := e.resolveString(node.FilePath)
+	if err != nil {
+		return err
+	}
+	logger.LogInfo("Upload %s to %s", filePath, selector)
+
+	if err := e.browser.UploadFile(selector, filePath); err != nil {
+		return &ActionError{Action: NodeTypeSendFile, Target: selector, Err: err}
+	}
+	return nil
+}
+
+func (e *Engine) executeConditional(node *Node) error {
+	result, err := e.decide(func() (bool, error) {
+		return e.evaluateCondition(node.ConditionExpression)
+	})
+	if err != nil {
+		return err
+	}
+	logger.LogDebug("Condition %q: %t", node.ConditionExpression, result)
+	e.planNote("%s → %s", node.ConditionExpression, branchName(result))
+	return e.executeBranch(node, result)
+}
+
+func (e *Engine) executeQuestion(node *Node) error {
+	if node.Check == nil {
+		return fmt.Errorf("question node has no check")
+	}
+
+	result, err := e.decide(func() (bool, error) {
+		value, found, err := e.context.lookup(node.Check.DataPath)
+		if err != nil {
+			return false, err
+		}
+		return evaluateCheck(node.Check, value, found)
+	})
+	if err != nil {
+		return err
+	}
+	logger.LogDebug("Check %s: %t", node.Check, result)
+	e.planNote("%s → %s", node.Check, branchName(result))
+	return e.executeBranch(node, result)
+}
+
+func (e *Engine) executeBranch(node *Node, result bool) error {
+	if node.Branches == nil {
+		return nil
+	}
+
+	branch, id := node.Branches.No, node.Branches.NoID
+	if result {
+		branch, id = node.Branches.Yes, node.Branches.YesID
+	}
+
+	// A branch id is a goto: execution continues at the target instead of
+	// returning to this node's continuation.
+	if id != "" {
+		target, err := e.jump(id)
+		if err != nil {
+			return err
+		}
+		e.pendingJump = target
+		return nil
+	}
+	return e.executeNode(branch)
+}
+
+func (e *Engine) executeSequence(node *Node) error {
+	for i := range node.Sequence {
+		logger.LogDebug("Sequence %d/%d", i+1, len(node.Sequence))
+		if err := e.executeNode(&node.Sequence[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Engine) executeForEach(node *Node) error {
+	source, total, name, err := e.openDataSource(node)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	previous := e.context.iterator()
+	defer e.context.restoreIterator(previous)
+	if total >= 0 {
+		e.planNote("iterate %d items of %s", total, name)
+	} else {
+		e.planNote("iterate rows of %s", name)
+	}
+
+	for i := 0; ; i++ {
+		item, err := source.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("data source %s: %w", name, err)
+		}
+		e.context.setItem(i, total, item)
+
+		proceed, err := e.proceed(node)
+		if err != nil {
+			return err
+		}
+		if !proceed {
+			logger.LogInfo("Skipped item %d", i)
+			continue
+		}
+
+		if err := e.executeNode(node.Next); err != nil {
+			return err
+		}
+	}
+}
+
+// proceed decides whether the current forEach item is processed, asking the
+// node's question only under the ask policy.
+func (e *Engine) proceed(node *Node) (bool, error) {
+	switch e.answerPolicy(node) {
+	case AnswerAlways:
+		return true, nil
+	case AnswerNever:
+		return false, nil
+	}
+	if node.QuestionText == "" {
+		return true, nil
+	}
+	return e.decide(func() (bool, error) {
+		question, err := e.resolveString(node.QuestionText)
+		if err != nil {
+			return false, err
+		}
+		return e.ask(question), nil
+	})
+}
+
+// answerPolicy returns the node's answer policy; nodes that ask defer to the engine's policy.
+func (e *Engine) answerPolicy(node *Node) string {
+	if node.Answer == AnswerAlways || node.Answer == AnswerNever {
+		return node.Answer
+	}
+	if e.answer != "" {
+		return e.answer
+	}
+	return AnswerAsk
+}
+
+func (e *Engine) executeWait(node *Node) error {
+	logger.LogDebug("Waiting %d ms", node.Duration)
+	if e.plan != nil {
+		e.planNote("wait %d ms", node.Duration)
+		return nil
+	}
+	time.Sleep(time.Duration(node.Duration) * time.Millisecond)
+	return nil
+}
+
+// ask prints a forEach question and reports whether the user chose to continue.
+// Enter continues; "n" or "no" skips the item.
+func (e *Engine) ask(question string) bool {
+	logger.LogInfo("Question: %s", question)
+	if e.plan != nil {
+		e.planNote("ask %q → continue", question)
+		return true
+	}
+	// Parallel branches share the terminal, so one question is asked at a time.
+	e.prompt.Lock()
+	defer e.prompt.Unlock()
+	fmt.Fprintf(e.output, "%s [Enter = continue, n = skip]: ", question)
+
+	response, _ := e.input.ReadString('\n')
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response != "n" && response != "no"
+}
+
+func branchName(result bool) string {
+	if result {
+		return "yes"
+	}
+	return "no"
+}
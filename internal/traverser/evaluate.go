@@ -0,0 +1,96 @@
+package traverser
+
+import (
+	"errors"
+
+	"rpa-dfs-engine/internal/logger"
+)
+
+// ScriptError is an exception thrown by page JavaScript. Stack is the JavaScript stack trace,
+// starting with the exception itself, when the browser reported one.
+type ScriptError struct {
+	Message string
+	Stack   string
+}
+
+func (e *ScriptError) Error() string {
+	if e.Stack != "" {
+		return "script threw " + e.Stack
+	}
+	return "script threw " + e.Message
+}
+
+// executeEvaluate calls the node's script in the page and stores its result at node.StoreAs.
+// Arguments are resolved like callWorkflow params and passed to the browser as values, so
+// context data never becomes part of the script source.
+func (e *Engine) executeEvaluate(node *Node) error {
+	frame, err := e.resolveString(node.Frame)
+	if err != nil {
+		return err
+	}
+	args := make([]interface{}, len(node.Args))
+	for i, arg := range node.Args {
+		if args[i], err = e.resolveArg(arg); err != nil {
+			return err
+		}
+	}
+
+	target := "page"
+	if frame != "" {
+		target = frame
+	}
+	logger.LogInfo("Evaluate script in %s", target)
+
+	value, err := e.browser.Evaluate(frame, node.Script, args)
+	if err != nil {
+		var scriptErr *ScriptError
+		if errors.As(err, &scriptErr) {
+			return err
+		}
+		return &ActionError{Action: NodeTypeEvaluate, Target: target, Err: err}
+	}
+	if node.StoreAs == "" {
+		return nil
+	}
+
+	if err := e.context.Set(node.StoreAs, value); err != nil {
+		return err
+	}
+	if e.result.Extracted == nil {
+		e.result.Extracted = make(map[string]interface{})
+	}
+	e.result.Extracted[node.StoreAs] = value
+	e.planNote("store %s", node.StoreAs)
+	return nil
+}
+
+// resolveArg resolves the templates in every string of an evaluate argument.
+func (e *Engine) resolveArg(arg interface{}) (interface{}, error) {
+	switch arg := arg.(type) {
+	case string:
+		value, err := e.resolveValue(arg)
+		if err != nil {
+			return nil, err
+		}
+		return e.context.revealAll(value)
+	case []interface{}:
+		resolved := make([]interface{}, len(arg))
+		for i, item := range arg {
+			var err error
+			if resolved[i], err = e.resolveArg(item); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(arg))
+		for key, item := range arg {
+			var err error
+			if resolved[key], err = e.resolveArg(item); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	}
+	return arg, nil
+}
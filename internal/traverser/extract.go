@@ -0,0 +1,66 @@
+package traverser
+
+import (
+	"fmt"
+	"regexp"
+
+	"rpa-dfs-engine/internal/logger"
+)
+
+// executeExtract reads data from the page and stores it in the context at node.StoreAs.
+func (e *Engine) executeExtract(node *Node) error {
+	selector, err := e.resolveString(node.Selector)
+	if err != nil {
+		return err
+	}
+
+	var value interface{}
+	switch node.NodeType {
+	case NodeTypeExtractText:
+		value, err = e.browser.Text(selector)
+	case NodeTypeExtractAttribute:
+		value, err = e.browser.Attribute(selector, node.Attribute)
+	case NodeTypeExtractValue:
+		value, err = e.browser.Value(selector)
+	case NodeTypeExtractCount:
+		value, err = e.browser.Count(selector)
+	}
+	if err != nil {
+		return &ActionError{Action: node.NodeType, Target: selector, Err: err}
+	}
+
+	if text, ok := value.(string); ok && node.Pattern != "" {
+		if value, err = matchPattern(node.Pattern, text); err != nil {
+			return err
+		}
+	}
+
+	if err := e.context.Set(node.StoreAs, value); err != nil {
+		return err
+	}
+	if e.result.Extracted == nil {
+		e.result.Extracted = make(map[string]interface{})
+	}
+	e.result.Extracted[node.StoreAs] = value
+
+	logger.LogInfo("Extracted %s from %s", node.StoreAs, selector)
+	e.planNote("store %s", node.StoreAs)
+	return nil
+}
+
+// matchPattern returns the first capture group of pattern in text, or the whole match
+// when the pattern has no groups.
+func matchPattern(pattern, text string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	match := re.FindStringSubmatch(text)
+	if match == nil {
+		return "", fmt.Errorf("pattern %q does not match %q", pattern, text)
+	}
+	if len(match) > 1 {
+		return match[1], nil
+	}
+	return match[0], nil
+}
@@ -0,0 +1,183 @@
+package traverser
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// templateExpr is the parsed content of a {{ ... }} reference: a path followed by filters.
+type templateExpr struct {
+	path    string
+	filters []filterCall
+}
+
+type filterCall struct {
+	name   string
+	arg    string
+	hasArg bool
+}
+
+// filterFunc transforms a resolved value. found is false when the reference did not resolve;
+// only filters such as default act on missing values, the rest pass them through.
+type filterFunc func(value interface{}, found bool, call filterCall) (interface{}, bool, error)
+
+// templateFilters are the filters available in {{path | filter:"arg"}} references.
+var templateFilters = map[string]filterFunc{
+	"default":   filterDefault,
+	"upper":     stringFilter(strings.ToUpper),
+	"lower":     stringFilter(strings.ToLower),
+	"trim":      stringFilter(strings.TrimSpace),
+	"urlencode": stringFilter(url.QueryEscape),
+	"json":      filterJSON,
+	"date":      filterDate,
+}
+
+// filtersWithArg lists filters whose argument is mandatory.
+var filtersWithArg = map[string]bool{
+	"default": true,
+	"date":    true,
+}
+
+// parseTemplateExpr parses `user.name | default:"x" | upper`.
+func parseTemplateExpr(source string) (templateExpr, error) {
+	parts := splitOutsideQuotes(source, '|')
+	expr := templateExpr{path: strings.TrimSpace(parts[0])}
+	if expr.path == "" {
+		return expr, fmt.Errorf("empty reference in {{%s}}", source)
+	}
+
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		call := filterCall{name: part}
+		if idx := strings.Index(part, ":"); idx >= 0 {
+			call.name = strings.TrimSpace(part[:idx])
+			arg, err := parseFilterArg(strings.TrimSpace(part[idx+1:]))
+			if err != nil {
+				return expr, fmt.Errorf("filter %q: %w", call.name, err)
+			}
+			call.arg = arg
+			call.hasArg = true
+		}
+		if _, ok := templateFilters[call.name]; !ok {
+			return expr, fmt.Errorf("unknown filter %q", call.name)
+		}
+		if filtersWithArg[call.name] && !call.hasArg {
+			return expr, fmt.Errorf("filter %q requires an argument", call.name)
+		}
+		expr.filters = append(expr.filters, call)
+	}
+	return expr, nil
+}
+
+func parseFilterArg(arg string) (string, error) {
+	if len(arg) >= 2 && (arg[0] == '"' || arg[0] == '\'') {
+		if arg[len(arg)-1] != arg[0] {
+			return "", fmt.Errorf("unterminated string %s", arg)
+		}
+		if arg[0] == '"' {
+			return strconv.Unquote(arg)
+		}
+		return arg[1 : len(arg)-1], nil
+	}
+	if arg == "" {
+		return "", fmt.Errorf("missing argument")
+	}
+	return arg, nil
+}
+
+// splitOutsideQuotes splits s on sep, ignoring separators inside quoted strings.
+func splitOutsideQuotes(s string, sep byte) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case quote != 0:
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func filterDefault(value interface{}, found bool, call filterCall) (interface{}, bool, error) {
+	if !found || value == nil || value == "" {
+		return call.arg, true, nil
+	}
+	return value, true, nil
+}
+
+func stringFilter(fn func(string) string) filterFunc {
+	return func(value interface{}, found bool, call filterCall) (interface{}, bool, error) {
+		if !found {
+			return nil, false, nil
+		}
+		return fn(formatValue(value)), true, nil
+	}
+}
+
+func filterJSON(value interface{}, found bool, call filterCall) (interface{}, bool, error) {
+	if !found {
+		return nil, false, nil
+	}
+	if t, ok := value.(time.Time); ok {
+		value = t.Format(time.RFC3339)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false, fmt.Errorf("json filter: %w", err)
+	}
+	return string(data), true, nil
+}
+
+// dateInputLayouts are the string formats accepted by the date filter.
+var dateInputLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// filterDate formats a time with a Go layout. Strings in common layouts and
+// Unix timestamps are parsed first.
+func filterDate(value interface{}, found bool, call filterCall) (interface{}, bool, error) {
+	if !found {
+		return nil, false, nil
+	}
+
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case float64:
+		t = time.Unix(int64(v), 0)
+	case int:
+		t = time.Unix(int64(v), 0)
+	case string:
+		parsed := false
+		for _, layout := range dateInputLayouts {
+			if p, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				t, parsed = p, true
+				break
+			}
+		}
+		if !parsed {
+			return nil, false, fmt.Errorf("date filter: cannot parse %q as a date", v)
+		}
+	default:
+		return nil, false, fmt.Errorf("date filter: cannot format %T", value)
+	}
+	return t.Format(call.arg), true, nil
+}
@@ -0,0 +1,402 @@
+package traverser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diagram formats supported by RenderGraph.
+const (
+	GraphMermaid = "mermaid"
+	GraphDOT     = "dot"
+)
+
+// maxGraphText shortens long properties and errors in diagram labels.
+const maxGraphText = 48
+
+// Overlay colours for nodes of a finished run.
+const (
+	graphExecutedFill  = "#d4edda"
+	graphExecutedLine  = "#28a745"
+	graphRecoveredFill = "#fff3cd"
+	graphRecoveredLine = "#d39e00"
+	graphFailedFill    = "#f8d7da"
+	graphFailedLine    = "#dc3545"
+)
+
+// graphNode is a workflow node drawn in a diagram.
+type graphNode struct {
+	id     string
+	node   *Node
+	status string
+	err    string
+}
+
+// graphEdge connects two diagram nodes. Jumps to a node id and error handlers are dashed.
+type graphEdge struct {
+	from, to string
+	label    string
+	dashed   bool
+	jump     bool
+}
+
+// graphJump is a nextId, yesId or noId edge, drawn once every node has a diagram id.
+type graphJump struct {
+	from  string
+	id    string
+	label string
+}
+
+type graphRenderer struct {
+	workflow *Workflow
+	nodes    []*graphNode
+	edges    []graphEdge
+	jumps    []graphJump
+	ids      map[*Node]string
+	drawn    map[string]*graphNode
+	followed map[string]bool
+}
+
+// RenderGraph draws the workflow as a Mermaid flowchart or a Graphviz DOT digraph. Each
+// node shows its id, nodeType and key property; edges are labelled with the branch they
+// take. When run is given, the nodes and edges it executed are highlighted and failed
+// nodes show their error. Nodes of called workflows are not drawn.
+func (w *Workflow) RenderGraph(format string, run *RunResult) (string, error) {
+	r := &graphRenderer{workflow: w, ids: make(map[*Node]string), drawn: make(map[string]*graphNode)}
+	r.add(w.Graph)
+	for _, jump := range r.jumps {
+		if target, ok := w.NodeByID(jump.id); ok {
+			r.edges = append(r.edges, graphEdge{from: jump.from, to: r.ids[target], label: jump.label, dashed: true, jump: true})
+		}
+	}
+	if run != nil {
+		r.overlay(run)
+	}
+
+	switch format {
+	case GraphMermaid, "":
+		return r.mermaid(), nil
+	case GraphDOT:
+		return r.dot(), nil
+	}
+	return "", fmt.Errorf("unknown graph format %q: use %s or %s", format, GraphMermaid, GraphDOT)
+}
+
+// add draws node and everything reachable from it and returns its diagram id.
+func (r *graphRenderer) add(node *Node) string {
+	id := fmt.Sprintf("n%d", len(r.nodes)+1)
+	r.ids[node] = id
+	r.nodes = append(r.nodes, &graphNode{id: id, node: node})
+	r.drawn[id] = r.nodes[len(r.nodes)-1]
+
+	if node.Branches != nil {
+		r.branch(id, node.Branches.Yes, node.Branches.YesID, "yes")
+		r.branch(id, node.Branches.No, node.Branches.NoID, "no")
+	}
+	for i := range node.Sequence {
+		r.child(id, &node.Sequence[i], fmt.Sprint(i+1), false)
+	}
+	for _, name := range sortedNodeKeys(node.Parallel) {
+		r.child(id, node.Parallel[name], name, false)
+	}
+	if node.OnError != nil {
+		r.child(id, node.OnError, "onError", true)
+	}
+
+	if node.Next != nil {
+		label := ""
+		switch {
+		case node.NodeType == NodeTypeForEach:
+			label = "each"
+		case isLoop(node.NodeType):
+			label = node.NodeType
+		case node.isContainer() || node.NodeType == NodeTypeParallel:
+			label = "then"
+		}
+		r.child(id, node.Next, label, false)
+	}
+	if node.NextID != "" {
+		r.jumps = append(r.jumps, graphJump{from: id, id: node.NextID, label: "nextId"})
+	}
+	return id
+}
+
+func (r *graphRenderer) branch(from string, node *Node, id, label string) {
+	if node != nil {
+		r.child(from, node, label, false)
+	} else if id != "" {
+		r.jumps = append(r.jumps, graphJump{from: from, id: id, label: label})
+	}
+}
+
+// child draws the edge to node before node itself, so edges are listed top-down.
+func (r *graphRenderer) child(from string, node *Node, label string, dashed bool) {
+	index := len(r.edges)
+	r.edges = append(r.edges, graphEdge{from: from, label: label, dashed: dashed})
+	r.edges[index].to = r.add(node)
+}
+
+// overlay marks the nodes run executed. A node that failed anywhere in the run is
+// failed, one that failed and was recovered by onError is recovered. Nodes that ran
+// directly after each other are remembered to tell which jumps were taken.
+func (r *graphRenderer) overlay(run *RunResult) {
+	r.followed = make(map[string]bool)
+	previous := ""
+	r.workflow.index()
+	byPointer := make(map[string]*graphNode)
+	byID := make(map[string]*graphNode)
+	for _, drawn := range r.nodes {
+		byPointer[r.workflow.Pointer(drawn.node)] = drawn
+		if drawn.node.ID != "" {
+			byID[drawn.node.ID] = drawn
+		}
+	}
+
+	for _, result := range run.Nodes {
+		if result.Workflow != r.workflow.Name() {
+			continue
+		}
+		// Results written before pointers were recorded are matched by node id.
+		drawn, ok := byPointer[result.Pointer]
+		if result.Pointer == "" {
+			drawn, ok = byID[result.Node]
+		}
+		if !ok {
+			continue
+		}
+		r.followed[previous+">"+drawn.id] = true
+		previous = drawn.id
+		if statusRank(result.Status) >= statusRank(drawn.status) {
+			drawn.status = result.Status
+			if result.Error != "" {
+				drawn.err = result.Error
+				if result.ErrorKind != "" {
+					drawn.err = result.ErrorKind + ": " + result.Error
+				}
+			}
+		}
+	}
+}
+
+// statusRank orders statuses so the most severe outcome of a node is shown.
+func statusRank(status string) int {
+	switch status {
+	case "":
+		return 0
+	case NodeFailed:
+		return 3
+	case NodeRecovered:
+		return 2
+	}
+	return 1
+}
+
+// executed reports whether the run followed an edge. A node of the tree only runs when
+// its parent does, so tree edges were followed when both ends ran; a jump was taken when
+// its target ran right after its source.
+func (r *graphRenderer) executed(edge graphEdge) bool {
+	if edge.jump {
+		return r.followed[edge.from+">"+edge.to]
+	}
+	return r.drawn[edge.from].status != "" && r.drawn[edge.to].status != ""
+}
+
+// labelLines are the lines shown in a node: id and nodeType, key property and error.
+func (n *graphNode) labelLines() []string {
+	title := n.node.NodeType
+	if n.node.ID != "" {
+		title = n.node.ID + " · " + n.node.NodeType
+	}
+	lines := []string{title}
+	if property := keyProperty(n.node); property != "" {
+		lines = append(lines, shorten(property))
+	}
+	if n.err != "" {
+		lines = append(lines, "❌ "+shorten(n.err))
+	}
+	return lines
+}
+
+// keyProperty is the property that tells nodes of the same type apart.
+func keyProperty(node *Node) string {
+	if nodeType, ok := customNodeType(node.NodeType); ok {
+		if value, ok := node.Properties[nodeType.KeyProperty]; ok {
+			return formatValue(value)
+		}
+		return ""
+	}
+	switch {
+	case node.URL != "":
+		return node.URL
+	case node.Selector != "":
+		return node.Selector
+	case node.ConditionExpression != "":
+		return node.ConditionExpression
+	case node.Check != nil:
+		return node.Check.String()
+	case node.DataSource != "":
+		return node.DataSource
+	case node.Workflow != "":
+		return node.Workflow
+	case node.URLPattern != "":
+		return node.URLPattern
+	case node.Script != "":
+		return node.Script
+	case node.Expected != "":
+		return node.Expected
+	case node.NetworkIdle > 0:
+		return fmt.Sprintf("network idle %d ms", node.NetworkIdle)
+	case node.Duration > 0:
+		return fmt.Sprintf("%d ms", node.Duration)
+	case node.Join != "":
+		return "join " + node.Join
+	}
+	return ""
+}
+
+func shorten(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > maxGraphText {
+		return string(runes[:maxGraphText-1]) + "…"
+	}
+	return text
+}
+
+func (r *graphRenderer) mermaid() string {
+	var out strings.Builder
+	out.WriteString("flowchart TD\n")
+	for _, drawn := range r.nodes {
+		start, end := mermaidShape(drawn.node)
+		fmt.Fprintf(&out, "  %s%s\"%s\"%s\n", drawn.id, start, mermaidText(strings.Join(drawn.labelLines(), "\n")), end)
+	}
+
+	var executed []string
+	for i, edge := range r.edges {
+		arrow := "-->"
+		if edge.dashed {
+			arrow = "-.->"
+		}
+		if edge.label != "" {
+			arrow += "|" + mermaidText(edge.label) + "|"
+		}
+		fmt.Fprintf(&out, "  %s %s %s\n", edge.from, arrow, edge.to)
+		if r.executed(edge) {
+			executed = append(executed, fmt.Sprint(i))
+		}
+	}
+
+	classes := map[string][]string{}
+	for _, drawn := range r.nodes {
+		if class := overlayClass(drawn.status); class != "" {
+			classes[class] = append(classes[class], drawn.id)
+		}
+	}
+	if len(classes) == 0 {
+		return out.String()
+	}
+	fmt.Fprintf(&out, "  classDef executed fill:%s,stroke:%s\n", graphExecutedFill, graphExecutedLine)
+	fmt.Fprintf(&out, "  classDef recovered fill:%s,stroke:%s\n", graphRecoveredFill, graphRecoveredLine)
+	fmt.Fprintf(&out, "  classDef failed fill:%s,stroke:%s,stroke-width:2px\n", graphFailedFill, graphFailedLine)
+	for _, class := range []string{"executed", "recovered", "failed"} {
+		if ids := classes[class]; len(ids) > 0 {
+			fmt.Fprintf(&out, "  class %s %s\n", strings.Join(ids, ","), class)
+		}
+	}
+	if len(executed) > 0 {
+		fmt.Fprintf(&out, "  linkStyle %s stroke:%s,stroke-width:2px\n", strings.Join(executed, ","), graphExecutedLine)
+	}
+	return out.String()
+}
+
+func mermaidShape(node *Node) (string, string) {
+	switch node.NodeType {
+	case NodeTypeConditional, NodeTypeQuestion:
+		return "{", "}"
+	case NodeTypeForEach, NodeTypeWhile, NodeTypeUntil:
+		return "{{", "}}"
+	case NodeTypeSequence, NodeTypeParallel, NodeTypeCall:
+		return "[[", "]]"
+	}
+	if _, ok := customNodeType(node.NodeType); ok {
+		return "[/", "/]"
+	}
+	return "[", "]"
+}
+
+// mermaidText escapes a label for a quoted Mermaid string.
+func mermaidText(text string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", "<br/>", "|", "#124;").Replace(text)
+}
+
+func overlayClass(status string) string {
+	switch status {
+	case "":
+		return ""
+	case NodeFailed:
+		return "failed"
+	case NodeRecovered:
+		return "recovered"
+	}
+	return "executed"
+}
+
+func (r *graphRenderer) dot() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "digraph %s {\n", dotText(r.workflow.Name()))
+	out.WriteString("  node [shape=box, fontname=\"Helvetica\"];\n")
+	out.WriteString("  edge [fontname=\"Helvetica\"];\n")
+	for _, drawn := range r.nodes {
+		attrs := []string{"label=" + dotText(strings.Join(drawn.labelLines(), "\n"))}
+		if shape := dotShape(drawn.node); shape != "" {
+			attrs = append(attrs, "shape="+shape)
+		}
+		switch overlayClass(drawn.status) {
+		case "executed":
+			attrs = append(attrs, "style=filled", "fillcolor="+dotText(graphExecutedFill), "color="+dotText(graphExecutedLine))
+		case "recovered":
+			attrs = append(attrs, "style=filled", "fillcolor="+dotText(graphRecoveredFill), "color="+dotText(graphRecoveredLine))
+		case "failed":
+			attrs = append(attrs, "style=filled", "fillcolor="+dotText(graphFailedFill), "color="+dotText(graphFailedLine), "penwidth=2")
+		}
+		fmt.Fprintf(&out, "  %s [%s];\n", drawn.id, strings.Join(attrs, ", "))
+	}
+	for _, edge := range r.edges {
+		var attrs []string
+		if edge.label != "" {
+			attrs = append(attrs, "label="+dotText(edge.label))
+		}
+		if edge.dashed {
+			attrs = append(attrs, "style=dashed")
+		}
+		if r.executed(edge) {
+			attrs = append(attrs, "color="+dotText(graphExecutedLine), "penwidth=2")
+		}
+		if len(attrs) == 0 {
+			fmt.Fprintf(&out, "  %s -> %s;\n", edge.from, edge.to)
+			continue
+		}
+		fmt.Fprintf(&out, "  %s -> %s [%s];\n", edge.from, edge.to, strings.Join(attrs, ", "))
+	}
+	out.WriteString("}\n")
+	return out.String()
+}
+
+func dotShape(node *Node) string {
+	switch node.NodeType {
+	case NodeTypeConditional, NodeTypeQuestion:
+		return "diamond"
+	case NodeTypeForEach, NodeTypeWhile, NodeTypeUntil:
+		return "hexagon"
+	case NodeTypeSequence, NodeTypeParallel, NodeTypeCall:
+		return "box3d"
+	}
+	if _, ok := customNodeType(node.NodeType); ok {
+		return "parallelogram"
+	}
+	return ""
+}
+
+// dotText quotes a DOT string; newlines become centred line breaks.
+func dotText(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(text) + `"`
+}
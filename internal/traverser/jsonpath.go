@@ -0,0 +1,400 @@
+package traverser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"rpa-dfs-engine/internal/expression"
+)
+
+// jsonPathCurrent replaces "@" in filter expressions, which the expression syntax does not allow.
+const jsonPathCurrent = "$current"
+
+// jsonPath is a compiled JSONPath such as "$.user.orders[?(@.total > 100)].id". The root "$"
+// is the whole context, so paths start with a scope: "$.user", "$.vars" or "$.iterator".
+type jsonPath struct {
+	source string
+	steps  []jsonPathStep
+}
+
+// jsonPathStep selects children of every node matched so far. Exactly one selector is set.
+type jsonPathStep struct {
+	recursive bool
+
+	wildcard bool
+	names    []string
+	indices  []int
+	slice    *jsonPathSlice
+	filter   *expression.Expression
+}
+
+type jsonPathSlice struct {
+	start, end       int
+	hasStart, hasEnd bool
+}
+
+// isJSONPath reports whether a dataPath is written as JSONPath rather than a context path.
+func isJSONPath(path string) bool {
+	return strings.HasPrefix(strings.TrimSpace(path), "$")
+}
+
+// parseJSONPath compiles the supported JSONPath syntax: .name, ['name'], [0], [-1], [*], .*,
+// ..name (recursive descent), [0,2] and ['a','b'] (unions), [1:3] (slices) and
+// [?(@.price > 10)] (filters, written as conditionExpression syntax with @ for the element).
+func parseJSONPath(source string) (*jsonPath, error) {
+	path := strings.TrimSpace(source)
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSONPath %q must start with $", source)
+	}
+
+	p := &jsonPath{source: source}
+	for i := 1; i < len(path); {
+		var step jsonPathStep
+		switch {
+		case strings.HasPrefix(path[i:], ".."):
+			step.recursive = true
+			i += 2
+			if i < len(path) && path[i] == '[' {
+				continue
+			}
+		case path[i] == '.':
+			i++
+		case path[i] == '[':
+		default:
+			return nil, fmt.Errorf("JSONPath %q: unexpected %q at column %d", source, path[i], i+1)
+		}
+
+		if i < len(path) && path[i] == '[' {
+			end := jsonPathBracketEnd(path, i)
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %q: unclosed '['", source)
+			}
+			if err := step.parseBracket(strings.TrimSpace(path[i+1 : end])); err != nil {
+				return nil, fmt.Errorf("JSONPath %q: %w", source, err)
+			}
+			i = end + 1
+		} else {
+			start := i
+			for i < len(path) && path[i] != '.' && path[i] != '[' {
+				i++
+			}
+			name := path[start:i]
+			switch name {
+			case "":
+				return nil, fmt.Errorf("JSONPath %q: missing name at column %d", source, start+1)
+			case "*":
+				step.wildcard = true
+			default:
+				step.names = []string{name}
+			}
+		}
+
+		// "..[0]" carries recursive over to the bracket that follows.
+		if len(p.steps) > 0 && p.steps[len(p.steps)-1].isRecursiveMarker() {
+			p.steps[len(p.steps)-1] = jsonPathStep{}
+			p.steps = p.steps[:len(p.steps)-1]
+			step.recursive = true
+		}
+		p.steps = append(p.steps, step)
+	}
+	if len(p.steps) > 0 && p.steps[len(p.steps)-1].isRecursiveMarker() {
+		return nil, fmt.Errorf("JSONPath %q: .. must be followed by a name or [", source)
+	}
+	return p, nil
+}
+
+// isRecursiveMarker reports whether the step is a bare ".." whose selector follows in brackets.
+func (s *jsonPathStep) isRecursiveMarker() bool {
+	return s.recursive && !s.wildcard && s.names == nil && s.indices == nil && s.slice == nil && s.filter == nil
+}
+
+func (s *jsonPathStep) parseBracket(content string) error {
+	switch {
+	case content == "*":
+		s.wildcard = true
+		return nil
+	case strings.HasPrefix(content, "?"):
+		source := strings.TrimSpace(content[1:])
+		if strings.HasPrefix(source, "(") && strings.HasSuffix(source, ")") {
+			source = source[1 : len(source)-1]
+		}
+		expr, err := expression.Parse(replaceCurrent(source))
+		if err != nil {
+			return fmt.Errorf("filter %q: %w", source, err)
+		}
+		s.filter = expr
+		return nil
+	case strings.HasPrefix(content, "'") || strings.HasPrefix(content, "\""):
+		for _, part := range splitUnion(content) {
+			if len(part) < 2 || part[0] != part[len(part)-1] || (part[0] != '\'' && part[0] != '"') {
+				return fmt.Errorf("invalid name %s", part)
+			}
+			s.names = append(s.names, part[1:len(part)-1])
+		}
+		return nil
+	case strings.Contains(content, ":"):
+		startText, endText, _ := strings.Cut(content, ":")
+		slice := &jsonPathSlice{}
+		var err error
+		if startText = strings.TrimSpace(startText); startText != "" {
+			if slice.start, err = strconv.Atoi(startText); err != nil {
+				return fmt.Errorf("invalid slice start %q", startText)
+			}
+			slice.hasStart = true
+		}
+		if endText = strings.TrimSpace(endText); endText != "" {
+			if slice.end, err = strconv.Atoi(endText); err != nil {
+				return fmt.Errorf("invalid slice end %q", endText)
+			}
+			slice.hasEnd = true
+		}
+		s.slice = slice
+		return nil
+	}
+	for _, part := range splitUnion(content) {
+		index, err := strconv.Atoi(part)
+		if err != nil {
+			return fmt.Errorf("invalid index %q", part)
+		}
+		s.indices = append(s.indices, index)
+	}
+	return nil
+}
+
+// jsonPathBracketEnd returns the index of the "]" closing the bracket at start, skipping
+// quoted strings and nested brackets in filters.
+func jsonPathBracketEnd(path string, start int) int {
+	depth := 0
+	var quote byte
+	for i := start; i < len(path); i++ {
+		switch ch := path[i]; {
+		case quote != 0:
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '[':
+			depth++
+		case ch == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitUnion splits "a, b" at commas outside quotes.
+func splitUnion(content string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(content); i++ {
+		switch ch := content[i]; {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == ',':
+			parts = append(parts, strings.TrimSpace(content[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(content[start:]))
+}
+
+// replaceCurrent replaces @ outside quoted strings with jsonPathCurrent.
+func replaceCurrent(source string) string {
+	var sb strings.Builder
+	var quote byte
+	for i := 0; i < len(source); i++ {
+		switch ch := source[i]; {
+		case quote != 0:
+			if ch == '\\' && i+1 < len(source) {
+				sb.WriteByte(ch)
+				i++
+				ch = source[i]
+			} else if ch == quote {
+				quote = 0
+			}
+			sb.WriteByte(ch)
+		case ch == '\'' || ch == '"':
+			quote = ch
+			sb.WriteByte(ch)
+		case ch == '@':
+			sb.WriteString(jsonPathCurrent)
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+	return sb.String()
+}
+
+// definite reports whether the path selects at most one value.
+func (p *jsonPath) definite() bool {
+	for _, step := range p.steps {
+		if step.recursive || step.wildcard || step.slice != nil || step.filter != nil ||
+			len(step.names)+len(step.indices) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// prefix returns the context path of the leading names, e.g. "user.orders" for
+// "$.user.orders[*].id".
+func (p *jsonPath) prefix() string {
+	var names []string
+	for _, step := range p.steps {
+		if step.recursive || len(step.names) != 1 {
+			break
+		}
+		names = append(names, step.names[0])
+	}
+	return strings.Join(names, ".")
+}
+
+// evaluate returns the values the path matches in root, in document order. Filters may
+// also read context paths through resolver.
+func (p *jsonPath) evaluate(root interface{}, resolver expression.Resolver) []interface{} {
+	nodes := []interface{}{root}
+	for _, step := range p.steps {
+		var next []interface{}
+		for _, node := range nodes {
+			if step.recursive {
+				for _, descendant := range descendants(node) {
+					next = append(next, step.apply(descendant, root, resolver)...)
+				}
+			} else {
+				next = append(next, step.apply(node, root, resolver)...)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func (s *jsonPathStep) apply(node, root interface{}, resolver expression.Resolver) []interface{} {
+	switch {
+	case s.wildcard:
+		return children(node)
+	case s.names != nil:
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		var matches []interface{}
+		for _, name := range s.names {
+			if value, exists := object[name]; exists {
+				matches = append(matches, value)
+			}
+		}
+		return matches
+	case s.indices != nil:
+		array, ok := node.([]interface{})
+		if !ok {
+			return nil
+		}
+		var matches []interface{}
+		for _, index := range s.indices {
+			if index < 0 {
+				index += len(array)
+			}
+			if index >= 0 && index < len(array) {
+				matches = append(matches, array[index])
+			}
+		}
+		return matches
+	case s.slice != nil:
+		array, ok := node.([]interface{})
+		if !ok {
+			return nil
+		}
+		start, end := 0, len(array)
+		if s.slice.hasStart {
+			start = clampIndex(s.slice.start, len(array))
+		}
+		if s.slice.hasEnd {
+			end = clampIndex(s.slice.end, len(array))
+		}
+		if start >= end {
+			return nil
+		}
+		return array[start:end]
+	case s.filter != nil:
+		var matches []interface{}
+		for _, child := range children(node) {
+			keep, err := s.filter.EvaluateBool(jsonPathResolver{current: child, root: root, fallback: resolver})
+			if err == nil && keep {
+				matches = append(matches, child)
+			}
+		}
+		return matches
+	}
+	return nil
+}
+
+func clampIndex(index, length int) int {
+	if index < 0 {
+		index += length
+	}
+	return min(max(index, 0), length)
+}
+
+// children returns the elements of an array or the values of an object, ordered by key.
+func children(node interface{}) []interface{} {
+	switch v := node.(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]interface{}, len(keys))
+		for i, key := range keys {
+			values[i] = v[key]
+		}
+		return values
+	}
+	return nil
+}
+
+// descendants returns node and everything below it, parents before children.
+func descendants(node interface{}) []interface{} {
+	all := []interface{}{node}
+	for _, child := range children(node) {
+		all = append(all, descendants(child)...)
+	}
+	return all
+}
+
+// jsonPathResolver resolves @ to the element being filtered, $ to the root and any other
+// reference through the context.
+type jsonPathResolver struct {
+	current  interface{}
+	root     interface{}
+	fallback expression.Resolver
+}
+
+func (r jsonPathResolver) Get(path string) (interface{}, bool) {
+	switch path {
+	case jsonPathCurrent:
+		return r.current, true
+	case "$":
+		return r.root, true
+	}
+	if r.fallback == nil {
+		return nil, false
+	}
+	return r.fallback.Get(path)
+}
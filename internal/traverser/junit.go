@@ -0,0 +1,99 @@
+package traverser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// JUnit XML as read by common CI servers: one suite per run, one test case per assertion.
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnit renders the result as JUnit XML. Every assertion that ran is a test case;
+// a run that failed for another reason gets an extra test case reporting the error.
+func (r *RunResult) JUnit() ([]byte, error) {
+	suite := junitSuite{
+		Name:      r.Workflow,
+		Time:      fmt.Sprintf("%.3f", r.FinishedAt.Sub(r.StartedAt).Seconds()),
+		Timestamp: r.StartedAt.Format("2006-01-02T15:04:05"),
+	}
+
+	for _, node := range r.Nodes {
+		if !isAssertion(node.NodeType) || node.Status == NodeRunning {
+			continue
+		}
+		testCase := junitCase{Name: node.Node, Classname: node.Workflow}
+		if node.Status == NodeFailed || node.Status == NodeRecovered {
+			testCase.Failure = &junitProblem{
+				Message: node.Error,
+				Type:    node.NodeType,
+				Text:    fmt.Sprintf("expected: %s\nactual: %s", node.Expected, node.Actual),
+			}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	if r.Status == RunFailed && r.ErrorKind != ErrorKindAssertion {
+		suite.Cases = append(suite.Cases, junitCase{
+			Name:      "run",
+			Classname: r.Workflow,
+			Error:     &junitProblem{Message: r.Error, Type: r.ErrorKind, Text: r.Error},
+		})
+		suite.Errors++
+	}
+	suite.Tests = len(suite.Cases)
+
+	data, err := xml.MarshalIndent(junitSuites{Suites: []junitSuite{suite}}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error encoding JUnit report: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// SaveJUnit writes the JUnit report to path, creating the parent directory.
+func (r *RunResult) SaveJUnit(path string) error {
+	data, err := r.JUnit()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating report directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("error writing JUnit report: %w", err)
+	}
+	return nil
+}
+
+func isAssertion(nodeType string) bool {
+	return strings.HasPrefix(nodeType, "assert")
+}
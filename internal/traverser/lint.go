@@ -0,0 +1,601 @@
+package traverser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"rpa-dfs-engine/internal/expression"
+)
+
+// Lint severities. A rule set to LintOff is not checked.
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+	LintInfo    = "info"
+	LintOff     = "off"
+)
+
+var lintSeverities = []string{LintError, LintWarning, LintInfo, LintOff}
+
+// maxChildCombinators is the longest chain of ">" combinators a selector may have before it is
+// considered tied to the page layout.
+const maxChildCombinators = 3
+
+var (
+	positionalSelectorPattern = regexp.MustCompile(`:nth-(last-)?(child|of-type)\(`)
+	selectorNamePattern       = regexp.MustCompile(`[.#](-?[A-Za-z_][A-Za-z0-9_-]*)`)
+	trailingDigitsPattern     = regexp.MustCompile(`[A-Za-z][0-9]{3,}$`)
+)
+
+// generatedClassPrefixes are the prefixes CSS-in-JS libraries give the class names they hash.
+var generatedClassPrefixes = []string{"css-", "sc-", "jsx-", "emotion-", "svelte-"}
+
+// LintRule is a check run by the linter and the severity it reports at by default.
+type LintRule struct {
+	Name        string
+	Severity    string
+	Description string
+
+	check func(l *linter, node lintNode)
+}
+
+// LintRules returns every lint rule in the order they are checked.
+func LintRules() []LintRule {
+	return lintRules
+}
+
+var lintRules = []LintRule{
+	{
+		Name:        "duplicate-id",
+		Severity:    LintError,
+		Description: "two nodes declare the same id, so jumps to it are ambiguous",
+		check:       (*linter).checkDuplicateID,
+	},
+	{
+		Name:        "unreachable-node",
+		Severity:    LintWarning,
+		Description: "a branch or next never runs because of a constant condition or branch jumps",
+		check:       (*linter).checkUnreachable,
+	},
+	{
+		Name:        "missing-no-branch",
+		Severity:    LintWarning,
+		Description: "a conditional or question has no no branch",
+		check:       (*linter).checkMissingNoBranch,
+	},
+	{
+		Name:        "unknown-context-key",
+		Severity:    LintWarning,
+		Description: "a user.* reference that the context does not provide",
+		check:       (*linter).checkContextKeys,
+	},
+	{
+		Name:        "fragile-selector",
+		Severity:    LintWarning,
+		Description: "a selector that depends on element position, deep nesting or generated class names",
+		check:       (*linter).checkSelector,
+	},
+}
+
+// LintConfig overrides the severity of lint rules, e.g. {"rules": {"fragile-selector": "error"}}.
+// Rules that are not listed keep their default severity.
+type LintConfig struct {
+	Rules map[string]string `json:"rules"`
+}
+
+// LoadLintConfig reads a lint configuration file, JSON or YAML depending on its extension.
+func LoadLintConfig(path string) (*LintConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading lint config: %w", err)
+	}
+	if IsYAML(path) {
+		if data, err = YAMLToJSON(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	var config LintConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: error parsing lint config: %w", path, err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &config, nil
+}
+
+func (c *LintConfig) validate() error {
+	for _, name := range sortedStringKeys(c.Rules) {
+		if _, ok := lintRule(name); !ok {
+			return fmt.Errorf("unknown lint rule %q", name)
+		}
+		if !containsString(lintSeverities, c.Rules[name]) {
+			return fmt.Errorf("rule %q: severity must be one of %s", name, strings.Join(lintSeverities, ", "))
+		}
+	}
+	return nil
+}
+
+func (c *LintConfig) severity(rule LintRule) string {
+	if c != nil {
+		if severity, ok := c.Rules[rule.Name]; ok {
+			return severity
+		}
+	}
+	return rule.Severity
+}
+
+func lintRule(name string) (LintRule, bool) {
+	for _, rule := range lintRules {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return LintRule{}, false
+}
+
+// LintIssue is a problem found by a lint rule, located like a ValidationError.
+type LintIssue struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Pointer  string `json:"pointer"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Message  string `json:"message"`
+}
+
+// LintWorkflow checks a workflow JSON document for problems the schema allows: duplicate ids,
+// branches that never run, conditionals without a no branch, references to context keys that
+// are not provided and fragile selectors. user is the user scope of the context the workflow
+// runs with; without it references are not checked. Parts of the document that are not valid
+// are skipped, they are reported by ValidateWorkflow.
+func LintWorkflow(data []byte, config *LintConfig, user map[string]interface{}) ([]LintIssue, error) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return lintDocument(data, newPositionIndex(data), config, user)
+}
+
+// LintWorkflowYAML is LintWorkflow for YAML documents.
+func LintWorkflowYAML(data []byte, config *LintConfig, user map[string]interface{}) ([]LintIssue, error) {
+	converted, positions, err := convertYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	return lintDocument(converted, positions, config, user)
+}
+
+func lintDocument(data []byte, positions positionLookup, config *LintConfig, user map[string]interface{}) ([]LintIssue, error) {
+	if config != nil {
+		if err := config.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if version, err := SchemaVersion(data); err == nil && version != currentSchemaVersion {
+		if version > currentSchemaVersion {
+			return nil, &SchemaVersionError{Version: version}
+		}
+		if data, err = upgradeWorkflow(data); err != nil {
+			return nil, err
+		}
+		if _, ok := positions.(*positionIndex); ok {
+			positions = newPositionIndex(data)
+		}
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("workflow must be a JSON object")
+	}
+
+	l := &linter{positions: positions, user: user, ids: make(map[string]string)}
+	collectLintNodes(doc["graph"], "/graph", &l.nodes)
+	for _, node := range l.nodes {
+		l.collectWrites(node)
+	}
+
+	for _, rule := range lintRules {
+		l.severity = config.severity(rule)
+		if l.severity == LintOff {
+			continue
+		}
+		l.rule = rule.Name
+		for _, node := range l.nodes {
+			rule.check(l, node)
+		}
+	}
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		a, b := l.issues[i], l.issues[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return l.issues, nil
+}
+
+// lintNode is a node object of the document and its JSON pointer.
+type lintNode struct {
+	pointer string
+	fields  map[string]interface{}
+}
+
+func (n lintNode) nodeType() string {
+	nodeType, _ := n.fields["nodeType"].(string)
+	return nodeType
+}
+
+func (n lintNode) label() string {
+	if id, ok := n.fields["id"].(string); ok && id != "" {
+		return id
+	}
+	return n.nodeType()
+}
+
+// collectLintNodes lists the node objects under value, depth first.
+func collectLintNodes(value interface{}, pointer string, nodes *[]lintNode) {
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+	*nodes = append(*nodes, lintNode{pointer: pointer, fields: fields})
+
+	collectLintNodes(fields["onError"], pointer+"/onError", nodes)
+	if branches, ok := fields["branches"].(map[string]interface{}); ok {
+		collectLintNodes(branches["yes"], pointer+"/branches/yes", nodes)
+		collectLintNodes(branches["no"], pointer+"/branches/no", nodes)
+	}
+	if sequence, ok := fields["sequence"].([]interface{}); ok {
+		for i, item := range sequence {
+			collectLintNodes(item, fmt.Sprintf("%s/sequence/%d", pointer, i), nodes)
+		}
+	}
+	if parallel, ok := fields["parallel"].(map[string]interface{}); ok {
+		for _, name := range sortedKeys(parallel) {
+			collectLintNodes(parallel[name], pointerJoin(pointer+"/parallel", name), nodes)
+		}
+	}
+	collectLintNodes(fields["next"], pointer+"/next", nodes)
+}
+
+type linter struct {
+	positions positionLookup
+	user      map[string]interface{}
+	nodes     []lintNode
+	issues    []LintIssue
+
+	// ids maps each node id to the pointer of the node declaring it first.
+	ids map[string]string
+	// writes are the user scope paths the workflow stores values at while it runs.
+	writes []string
+
+	rule     string
+	severity string
+}
+
+func (l *linter) report(pointer, format string, args ...interface{}) {
+	line, column := l.positions.lookup(pointer)
+	l.issues = append(l.issues, LintIssue{
+		Rule:     l.rule,
+		Severity: l.severity,
+		Pointer:  pointer,
+		Line:     line,
+		Column:   column,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) checkDuplicateID(node lintNode) {
+	id, ok := node.fields["id"].(string)
+	if !ok || id == "" {
+		return
+	}
+	first, exists := l.ids[id]
+	if !exists {
+		l.ids[id] = node.pointer
+		return
+	}
+	line, _ := l.positions.lookup(pointerJoin(first, "id"))
+	l.report(pointerJoin(node.pointer, "id"), "duplicate id %q, first declared on line %d", id, line)
+}
+
+// checkUnreachable reports what a conditional or question never runs: the branch its
+// condition never takes when the condition does not depend on the context, and its next
+// when every branch it can take jumps away by id.
+func (l *linter) checkUnreachable(node lintNode) {
+	switch node.nodeType() {
+	case NodeTypeConditional, NodeTypeQuestion:
+	default:
+		return
+	}
+	branches, _ := node.fields["branches"].(map[string]interface{})
+	taken := []string{"yes", "no"}
+
+	if source, result, ok := constantCondition(node); ok {
+		untaken := branchName(!result)
+		if _, ok := branches[untaken].(map[string]interface{}); ok {
+			l.report(pointerJoin(node.pointer+"/branches", untaken),
+				"%s branch of %q never runs: condition %q is always %t", untaken, node.label(), source, result)
+		}
+		taken = []string{branchName(result)}
+	}
+
+	for _, branch := range taken {
+		if id, _ := branches[branch+"Id"].(string); id == "" {
+			return
+		}
+	}
+	for _, key := range []string{"next", "nextId"} {
+		if _, ok := node.fields[key]; ok {
+			l.report(pointerJoin(node.pointer, key),
+				"%s of %q never runs: every branch it takes jumps by id", key, node.label())
+		}
+	}
+}
+
+// constantCondition evaluates a conditional whose condition does not read the context.
+func constantCondition(node lintNode) (string, bool, bool) {
+	if node.nodeType() != NodeTypeConditional {
+		return "", false, false
+	}
+	source, _ := node.fields["conditionExpression"].(string)
+	expr, err := expression.Parse(source)
+	if err != nil || len(expr.References()) > 0 {
+		return "", false, false
+	}
+	result, err := expr.EvaluateBool(nil)
+	if err != nil {
+		return "", false, false
+	}
+	return source, result, true
+}
+
+func (l *linter) checkMissingNoBranch(node lintNode) {
+	switch node.nodeType() {
+	case NodeTypeConditional, NodeTypeQuestion:
+	default:
+		return
+	}
+	branches, _ := node.fields["branches"].(map[string]interface{})
+	if branches["no"] != nil || branches["noId"] != nil {
+		return
+	}
+	pointer := node.pointer
+	if branches != nil {
+		pointer += "/branches"
+	}
+	l.report(pointer, "%s %q has no no branch; when it is false the workflow silently moves on",
+		node.nodeType(), node.label())
+}
+
+// checkContextKeys reports user.* references in templates, conditions and data paths that
+// neither the context nor an earlier storeAs or callWorkflow output provides.
+func (l *linter) checkContextKeys(node lintNode) {
+	if l.user == nil {
+		return
+	}
+	for _, key := range sortedKeys(node.fields) {
+		if childKeys[key] {
+			continue
+		}
+		pointer := pointerJoin(node.pointer, key)
+		switch key {
+		case "conditionExpression":
+			source, _ := node.fields[key].(string)
+			if expr, err := expression.Parse(source); err == nil {
+				l.checkPaths(pointer, expr.References())
+			}
+		case "dataSource":
+			source, _ := node.fields[key].(string)
+			if !isDataFile(source) {
+				l.checkPaths(pointer, []string{trimTemplate(source)})
+			}
+		case "check":
+			check, _ := node.fields[key].(map[string]interface{})
+			path, _ := check["dataPath"].(string)
+			operator, _ := check["operator"].(string)
+			switch {
+			case unaryCheckOperators[operator]:
+				// exists, notExists and isEmpty are written for data that may be absent.
+			case isJSONPath(path):
+				// Only the literal start of a JSONPath is a context path; filters and
+				// wildcards select data that may legitimately be absent.
+				if compiled, err := parseJSONPath(path); err == nil && compiled.prefix() != "" {
+					l.checkPaths(pointerJoin(pointer, "dataPath"), []string{compiled.prefix()})
+				}
+			case path != "":
+				l.checkPaths(pointerJoin(pointer, "dataPath"), []string{trimTemplate(path)})
+			}
+		default:
+			l.checkTemplates(pointer, node.fields[key])
+		}
+	}
+}
+
+// checkTemplates checks the references in every string of value. References with a default
+// filter are optional and not checked.
+func (l *linter) checkTemplates(pointer string, value interface{}) {
+	switch value := value.(type) {
+	case string:
+		var paths []string
+		_, _ = replaceTemplates(value, func(match, source string) (string, error) {
+			expr, err := parseTemplateExpr(source)
+			if err != nil {
+				return match, nil
+			}
+			for _, filter := range expr.filters {
+				if filter.name == "default" {
+					return match, nil
+				}
+			}
+			paths = append(paths, expr.path)
+			return match, nil
+		})
+		l.checkPaths(pointer, paths)
+	case map[string]interface{}:
+		for _, key := range sortedKeys(value) {
+			l.checkTemplates(pointerJoin(pointer, key), value[key])
+		}
+	case []interface{}:
+		for i, item := range value {
+			l.checkTemplates(fmt.Sprintf("%s/%d", pointer, i), item)
+		}
+	}
+}
+
+func (l *linter) checkPaths(pointer string, paths []string) {
+	for _, path := range paths {
+		keys, ok := userKeys(path)
+		if !ok || l.provided(keys) {
+			continue
+		}
+		l.report(pointer, "%s is not provided by the context", path)
+	}
+}
+
+// provided reports whether the context has the user scope keys, or the workflow stores them.
+func (l *linter) provided(keys []string) bool {
+	path := strings.Join(keys, ".")
+	for _, written := range l.writes {
+		if path == written || strings.HasPrefix(path, written+".") || strings.HasPrefix(written, path+".") {
+			return true
+		}
+	}
+
+	var current interface{} = l.user
+	for _, key := range keys[1:] {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			// Past an array or a scalar the value depends on the data; assume it is there.
+			return true
+		}
+		if current, ok = object[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// collectWrites records the user scope paths a node stores values at.
+func (l *linter) collectWrites(node lintNode) {
+	var paths []string
+	if storeAs, ok := node.fields["storeAs"].(string); ok {
+		paths = append(paths, storeAs)
+	}
+	if outputs, ok := node.fields["outputs"].(map[string]interface{}); ok {
+		paths = append(paths, sortedKeys(outputs)...)
+	}
+	for _, path := range paths {
+		if keys, ok := userKeys(path); ok {
+			l.writes = append(l.writes, strings.Join(keys, "."))
+		}
+	}
+}
+
+// userKeys returns the leading key segments of a user scope path, up to the first index.
+func userKeys(path string) ([]string, bool) {
+	segments, err := splitPath(path)
+	if err != nil || len(segments) < 2 || segments[0].isIndex || segments[0].value != "user" {
+		return nil, false
+	}
+	var keys []string
+	for _, segment := range segments {
+		if segment.isIndex {
+			break
+		}
+		keys = append(keys, segment.value)
+	}
+	return keys, true
+}
+
+func (l *linter) checkSelector(node lintNode) {
+	selector, ok := node.fields["selector"].(string)
+	if !ok {
+		return
+	}
+	if reason := fragileSelector(selector); reason != "" {
+		l.report(pointerJoin(node.pointer, "selector"), "selector %q %s", selector, reason)
+	}
+}
+
+// fragileSelector explains why a CSS selector is likely to break when the page changes,
+// or returns "" if it looks stable.
+func fragileSelector(selector string) string {
+	if match := positionalSelectorPattern.FindString(selector); match != "" {
+		return fmt.Sprintf("depends on element position (%s)", strings.TrimSuffix(match, "("))
+	}
+
+	plain := stripSelectorStrings(selector)
+	if count := strings.Count(plain, ">"); count > maxChildCombinators {
+		return fmt.Sprintf("chains %d child combinators and breaks when the layout changes", count)
+	}
+	for _, match := range selectorNamePattern.FindAllStringSubmatch(plain, -1) {
+		if generatedName(match[1]) {
+			return fmt.Sprintf("uses the generated name %q", match[0])
+		}
+	}
+	return ""
+}
+
+// stripSelectorStrings removes attribute selectors, quoted strings and templates, whose text
+// is not selector syntax.
+func stripSelectorStrings(selector string) string {
+	var sb strings.Builder
+	var quote byte
+	depth := 0
+	for i := 0; i < len(selector); i++ {
+		switch ch := selector[i]; {
+		case quote != 0:
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '[' || ch == '{':
+			depth++
+		case ch == ']' || ch == '}':
+			if depth > 0 {
+				depth--
+			}
+		case depth == 0:
+			sb.WriteByte(ch)
+		}
+	}
+	return sb.String()
+}
+
+// generatedName reports whether a class or id looks generated by a build tool: a CSS-in-JS
+// prefix, a hash suffix such as "Button_primary__3xK9a", or a counter such as "ember123".
+func generatedName(name string) bool {
+	for _, prefix := range generatedClassPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	if trailingDigitsPattern.MatchString(name) {
+		return true
+	}
+
+	separator := strings.LastIndexAny(name, "_-")
+	if separator < 0 {
+		return false
+	}
+	suffix := name[separator+1:]
+	digits := 0
+	for _, r := range suffix {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return len(suffix) >= 5 && digits >= 2 && digits < len(suffix)
+}
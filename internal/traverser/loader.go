@@ -0,0 +1,230 @@
+package traverser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultEnvPrefix is the environment variable prefix mapped to the user scope, so
+// USER_EMAIL sets user.email.
+const DefaultEnvPrefix = "USER_"
+
+// ContextSources lists the layers of a context, from lowest to highest precedence: the
+// base context, the environment-specific file, environment variables and --set overrides.
+type ContextSources struct {
+	// Context is the base context: a JSON or YAML file, or inline JSON starting with "{".
+	Context string
+
+	// Env names an environment whose file is merged over the base, e.g. "staging" loads
+	// user.staging.json next to user.json.
+	Env string
+
+	// EnvMap maps environment variable prefixes to context paths, e.g. "USER_" to "user".
+	// Environ is the environment to read, os.Environ() when nil.
+	EnvMap  map[string]string
+	Environ []string
+
+	// Sets are "path=value" overrides such as "user.email=ada@example.com". Values are
+	// parsed as JSON when they can be, otherwise kept as strings.
+	Sets []string
+}
+
+// LoadedContext is a merged user scope and the layer each value came from.
+type LoadedContext struct {
+	User map[string]interface{}
+
+	// Sources maps the path of every value, such as "user.profile.city", to its layer:
+	// a file path, "inline", "env USER_EMAIL" or "--set".
+	Sources map[string]string
+}
+
+// Paths returns the paths of the loaded values, sorted.
+func (c *LoadedContext) Paths() []string {
+	paths := make([]string, 0, len(c.Sources))
+	for path := range c.Sources {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// LoadContext merges the context layers. Objects are merged key by key; any other value,
+// including arrays, replaces what the lower layers set.
+func LoadContext(sources ContextSources) (*LoadedContext, error) {
+	loaded := &LoadedContext{User: make(map[string]interface{}), Sources: make(map[string]string)}
+
+	inline := strings.HasPrefix(strings.TrimSpace(sources.Context), "{")
+	switch {
+	case inline:
+		user, err := ParseContext([]byte(sources.Context))
+		if err != nil {
+			return nil, fmt.Errorf("inline context: %w", err)
+		}
+		loaded.merge(loaded.User, user, "user", "inline")
+	case sources.Context != "":
+		if err := loaded.mergeFile(sources.Context); err != nil {
+			return nil, err
+		}
+	}
+
+	if sources.Env != "" {
+		if sources.Context == "" || inline {
+			return nil, fmt.Errorf("environment %q needs a context file to derive its file name from", sources.Env)
+		}
+		if err := loaded.mergeFile(EnvContextFile(sources.Context, sources.Env)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := loaded.applyEnv(sources.EnvMap, sources.Environ); err != nil {
+		return nil, err
+	}
+
+	for _, set := range sources.Sets {
+		path, raw, ok := strings.Cut(set, "=")
+		if !ok || strings.TrimSpace(path) == "" {
+			return nil, fmt.Errorf("invalid --set %q: expected path=value", set)
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			value = raw
+		}
+		if err := loaded.set(strings.TrimSpace(path), value, "--set"); err != nil {
+			return nil, err
+		}
+	}
+	return loaded, nil
+}
+
+// EnvContextFile returns the file of an environment next to a base context file:
+// "user.json" and "staging" give "user.staging.json".
+func EnvContextFile(base, env string) string {
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "." + env + ext
+}
+
+func (c *LoadedContext) mergeFile(path string) error {
+	user, err := LoadContextFile(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	c.merge(c.User, user, "user", path)
+	return nil
+}
+
+// merge copies src into dst, recursing into objects both sides have.
+func (c *LoadedContext) merge(dst, src map[string]interface{}, path, source string) {
+	for key, value := range src {
+		childPath := path + "." + key
+		existing, isObject := dst[key].(map[string]interface{})
+		incoming, incomingObject := value.(map[string]interface{})
+		if isObject && incomingObject && len(incoming) > 0 {
+			delete(c.Sources, childPath)
+			c.merge(existing, incoming, childPath, source)
+			continue
+		}
+		dst[key] = value
+		c.record(childPath, value, source)
+	}
+}
+
+// applyEnv sets a context value for every variable that starts with a mapped prefix.
+// The rest of the name is split into keys at "__" and each key is camel-cased, so with
+// "USER_" mapped to "user", USER_FIRST_NAME sets user.firstName and USER_ADDRESS__CITY
+// sets user.address.city. Values are kept as strings.
+func (c *LoadedContext) applyEnv(envMap map[string]string, environ []string) error {
+	if len(envMap) == 0 {
+		return nil
+	}
+	if environ == nil {
+		environ = os.Environ()
+	}
+	sorted := append([]string(nil), environ...)
+	sort.Strings(sorted)
+
+	prefixes := make([]string, 0, len(envMap))
+	for prefix := range envMap {
+		prefixes = append(prefixes, prefix)
+	}
+	// Longest first, so USER_BILLING_ can map elsewhere than USER_.
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+
+	for _, variable := range sorted {
+		name, value, _ := strings.Cut(variable, "=")
+		for _, prefix := range prefixes {
+			rest, ok := strings.CutPrefix(name, prefix)
+			if !ok || rest == "" {
+				continue
+			}
+			keys := strings.Split(rest, "__")
+			for i, key := range keys {
+				keys[i] = camelCase(key)
+			}
+			path := strings.TrimSuffix(envMap[prefix], ".") + "." + strings.Join(keys, ".")
+			if err := c.set(path, value, "env "+name); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// set stores a value at a user path, such as "user.email", and records its source.
+func (c *LoadedContext) set(path string, value interface{}, source string) error {
+	if !strings.HasPrefix(path, "user.") {
+		return fmt.Errorf("cannot set %q from %s: only user.* paths can be loaded", path, source)
+	}
+	decoded, err := decodeSecrets(value, path)
+	if err != nil {
+		return err
+	}
+	ctx := &Context{data: map[string]interface{}{"user": c.User}}
+	if err := ctx.Set(path, decoded); err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+	for existing := range c.Sources {
+		if strings.HasPrefix(path, existing+".") || strings.HasPrefix(path, existing+"[") {
+			delete(c.Sources, existing)
+		}
+	}
+	c.record(path, decoded, source)
+	return nil
+}
+
+// record notes source for value and every value below it, replacing what lower layers
+// recorded at and below path.
+func (c *LoadedContext) record(path string, value interface{}, source string) {
+	for existing := range c.Sources {
+		if existing == path || strings.HasPrefix(existing, path+".") || strings.HasPrefix(existing, path+"[") {
+			delete(c.Sources, existing)
+		}
+	}
+	if object, ok := value.(map[string]interface{}); ok && len(object) > 0 {
+		for key, item := range object {
+			c.record(path+"."+key, item, source)
+		}
+		return
+	}
+	c.Sources[path] = source
+}
+
+// camelCase turns an environment variable key such as FIRST_NAME into firstName.
+func camelCase(key string) string {
+	words := strings.Split(strings.ToLower(key), "_")
+	var sb strings.Builder
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		if sb.Len() > 0 {
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+		sb.WriteString(word)
+	}
+	return sb.String()
+}
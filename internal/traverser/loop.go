@@ -0,0 +1,132 @@
+package traverser
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"rpa-dfs-engine/internal/logger"
+)
+
+// loopConditions are the properties of a while or until node of which exactly one is set.
+// A selector is tested for its state, or for its text when expected is set.
+var loopConditions = []string{"conditionExpression", "selector", "urlPattern", "script"}
+
+// LoopLimitError is returned when a while or until loop still has not finished after
+// maxIterations passes.
+type LoopLimitError struct {
+	NodeType      string
+	Condition     string
+	MaxIterations int
+}
+
+func (e *LoopLimitError) Error() string {
+	return fmt.Sprintf("%s %s: still looping after maxIterations (%d)", e.NodeType, e.Condition, e.MaxIterations)
+}
+
+// executeLoop runs the loop body (the node's next) while the condition holds, or for until
+// nodes until it holds. The condition is tested before every pass, with iterator.index and
+// iterator.count already set for that pass, so a loop whose condition is settled up front
+// runs no pass at all. The node's delay follows every pass, before the condition is tested
+// again.
+func (e *Engine) executeLoop(node *Node) error {
+	description, check, err := e.loopCondition(node)
+	if err != nil {
+		return err
+	}
+	if node.MaxIterations <= 0 {
+		return fmt.Errorf("%s needs maxIterations", node.NodeType)
+	}
+
+	previous := e.context.iterator()
+	defer e.context.restoreIterator(previous)
+
+	if e.plan != nil {
+		e.planNote("%s %s (up to %d iterations)", node.NodeType, description, node.MaxIterations)
+		e.context.setPass(0)
+		return e.executeNode(node.Next)
+	}
+
+	for i := 0; ; i++ {
+		e.context.setPass(i)
+		done, err := e.decide(func() (bool, error) {
+			holds, err := check()
+			if err != nil {
+				return false, err
+			}
+			return holds == (node.NodeType == NodeTypeUntil), nil
+		})
+		if err != nil {
+			return err
+		}
+		if done {
+			logger.LogDebug("%s %s: done after %d iterations", node.NodeType, description, i)
+			return nil
+		}
+		if i == node.MaxIterations {
+			return &LoopLimitError{NodeType: node.NodeType, Condition: description, MaxIterations: node.MaxIterations}
+		}
+
+		logger.LogDebug("%s %s: iteration %d/%d", node.NodeType, description, i+1, node.MaxIterations)
+		if err := e.executeNode(node.Next); err != nil {
+			return err
+		}
+
+		// The delay gives the page time to react to the body before the condition is tested
+		// again. A resumed run replays recorded checks and need not wait for them.
+		if node.Delay > 0 && e.decisionIndex >= len(e.decisions) {
+			if e.runCtx.Err() != nil {
+				return errBranchCancelled
+			}
+			time.Sleep(time.Duration(node.Delay) * time.Millisecond)
+		}
+	}
+}
+
+// loopCondition describes the node's condition and returns a function that tests it once.
+func (e *Engine) loopCondition(node *Node) (string, func() (bool, error), error) {
+	switch {
+	case node.ConditionExpression != "":
+		return node.ConditionExpression, func() (bool, error) {
+			return e.evaluateCondition(node.ConditionExpression)
+		}, nil
+
+	case node.Selector != "" && node.Expected != "":
+		selector, err := e.resolveString(node.Selector)
+		if err != nil {
+			return "", nil, err
+		}
+		expected, err := e.resolveString(node.Expected)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("text of %s %s", selector, describeMatch(node.Match, expected)), func() (bool, error) {
+			text, err := e.browser.Text(selector)
+			if ErrorKind(err) == ErrorKindNotFound {
+				return false, nil
+			}
+			if err != nil {
+				return false, &ActionError{Action: node.NodeType, Target: selector, Err: err}
+			}
+			failure, err := compareAssertion(node, selector, expected, strings.TrimSpace(text))
+			return failure == nil, err
+		}, nil
+
+	case node.Selector != "" || node.URLPattern != "" || node.Script != "":
+		description, check, err := e.waitCondition(node)
+		if err != nil {
+			return "", nil, err
+		}
+		return description, func() (bool, error) {
+			ok, err := check()
+			if ErrorKind(err) == ErrorKindNotFound {
+				return false, nil
+			}
+			if err != nil {
+				return false, &ActionError{Action: node.NodeType, Target: description, Err: err}
+			}
+			return ok, nil
+		}, nil
+	}
+	return "", nil, fmt.Errorf("%s needs one of %s", node.NodeType, strings.Join(loopConditions, ", "))
+}
@@ -0,0 +1,308 @@
+package traverser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// CurrentSchemaVersion is the newest workflow schema this build understands.
+// Documents without metadata.schemaVersion predate versioning and are read as version 1.
+const CurrentSchemaVersion = 1
+
+// MigrationStep upgrades a workflow document from schema version From to From+1 by
+// editing it in place.
+type MigrationStep struct {
+	From        int
+	Description string
+	Apply       func(doc *Object) error
+}
+
+// migrations is the upgrade chain, oldest first. When a change to node properties would
+// break existing documents, bump CurrentSchemaVersion and append the step that rewrites them.
+var migrations = []MigrationStep{}
+
+// currentSchemaVersion is the version migrations end at: CurrentSchemaVersion unless a test
+// replaced the chain with SetMigrations.
+var currentSchemaVersion = CurrentSchemaVersion
+
+// SetMigrations replaces the upgrade chain and the current schema version until restore is
+// called. It lets tests run documents through the load and migrate paths with a chain
+// that does not ship yet; the chain must lead from version 1 to current one step at a time.
+func SetMigrations(chain []MigrationStep, current int) (restore func(), err error) {
+	if err := checkMigrations(chain, current); err != nil {
+		return nil, err
+	}
+	previous, previousVersion := migrations, currentSchemaVersion
+	migrations, currentSchemaVersion = chain, current
+	return func() { migrations, currentSchemaVersion = previous, previousVersion }, nil
+}
+
+// checkMigrations reports a chain that does not upgrade version 1 to current one step at a
+// time: every step must start where the previous one ended.
+func checkMigrations(chain []MigrationStep, current int) error {
+	version := 1
+	for _, step := range chain {
+		if step.From != version {
+			return fmt.Errorf("migration %q starts at schema version %d, expected %d", step.Description, step.From, version)
+		}
+		version++
+	}
+	if version != current {
+		return fmt.Errorf("migrations end at schema version %d, current is %d", version, current)
+	}
+	return nil
+}
+
+// applyMigrations runs the steps of chain that upgrade doc from version to current and
+// describes each one.
+func applyMigrations(doc *Object, version, current int, chain []MigrationStep) ([]string, error) {
+	if version > current {
+		return nil, &SchemaVersionError{Version: version}
+	}
+	if err := checkMigrations(chain, current); err != nil {
+		return nil, err
+	}
+	var applied []string
+	for _, step := range chain[version-1:] {
+		if err := step.Apply(doc); err != nil {
+			return nil, fmt.Errorf("migrating from schema version %d: %w", step.From, err)
+		}
+		applied = append(applied, fmt.Sprintf("%d → %d: %s", step.From, step.From+1, step.Description))
+	}
+	return applied, nil
+}
+
+// SchemaVersionError refuses a document written for a newer schema than this build understands.
+type SchemaVersionError struct {
+	Version int
+}
+
+func (e *SchemaVersionError) Error() string {
+	return fmt.Sprintf("workflow schema version %d is newer than this build supports (%d); upgrade rpa-dfs-engine",
+		e.Version, currentSchemaVersion)
+}
+
+// Migration is the outcome of upgrading a workflow document.
+type Migration struct {
+	From    int
+	To      int
+	Applied []string
+	Data    []byte
+
+	original []byte
+}
+
+// Changed reports whether the upgraded document differs from the original.
+func (m *Migration) Changed() bool {
+	return !bytes.Equal(m.original, m.Data)
+}
+
+// SchemaVersion returns the schema version a workflow document declares, or 1 if it declares none.
+func SchemaVersion(data []byte) (int, error) {
+	var doc struct {
+		Metadata struct {
+			SchemaVersion *json.Number `json:"schemaVersion"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return 0, fmt.Errorf("error parsing workflow: %w", err)
+	}
+	if doc.Metadata.SchemaVersion == nil {
+		return 1, nil
+	}
+	version, err := doc.Metadata.SchemaVersion.Int64()
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("metadata.schemaVersion must be a positive integer, got %s", doc.Metadata.SchemaVersion)
+	}
+	return int(version), nil
+}
+
+// MigrateWorkflow upgrades a workflow document to CurrentSchemaVersion and declares that
+// version in its metadata. Key order is kept so only migrated properties change; the result
+// is indented with two spaces. A document that already declares the current version is
+// returned as is.
+func MigrateWorkflow(data []byte) (*Migration, error) {
+	version, err := SchemaVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	if version > currentSchemaVersion {
+		return nil, &SchemaVersionError{Version: version}
+	}
+
+	doc, err := decodeObject(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing workflow: %w", err)
+	}
+	metadata, _ := doc.Get("metadata").(*Object)
+	if version == currentSchemaVersion && metadata != nil && metadata.Has("schemaVersion") {
+		return &Migration{From: version, To: version, Data: data, original: data}, nil
+	}
+
+	result := &Migration{From: version, To: currentSchemaVersion, original: data}
+	if result.Applied, err = applyMigrations(doc, version, currentSchemaVersion, migrations); err != nil {
+		return nil, err
+	}
+
+	if metadata, _ = doc.Get("metadata").(*Object); metadata == nil {
+		metadata = newObject()
+		doc.Set("metadata", metadata)
+	}
+	metadata.Set("schemaVersion", json.Number(fmt.Sprint(currentSchemaVersion)))
+
+	if result.Data, err = encodeObject(doc); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// upgradeWorkflow returns the document migrated to the current schema for loading.
+// Documents that need no migration are returned unchanged so positions in errors stay exact.
+func upgradeWorkflow(data []byte) ([]byte, error) {
+	version, err := SchemaVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	if version > currentSchemaVersion {
+		return nil, &SchemaVersionError{Version: version}
+	}
+	if version == currentSchemaVersion {
+		return data, nil
+	}
+	migrated, err := MigrateWorkflow(data)
+	if err != nil {
+		return nil, err
+	}
+	return migrated.Data, nil
+}
+
+// Object is a JSON object that remembers the order of its keys, so a rewritten file
+// only changes where a migration touched it. Migration steps edit documents through it.
+type Object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newObject() *Object {
+	return &Object{values: make(map[string]interface{})}
+}
+
+// Has reports whether the object has key.
+func (o *Object) Has(key string) bool {
+	_, ok := o.values[key]
+	return ok
+}
+
+// Get returns the value of key, or nil.
+func (o *Object) Get(key string) interface{} {
+	return o.values[key]
+}
+
+// Set replaces the value of key in place, or appends the key when it is new.
+func (o *Object) Set(key string, value interface{}) {
+	if !o.Has(key) {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// MarshalJSON writes the keys in their original order without escaping HTML characters,
+// which are common in selectors.
+func (o *Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := writeJSON(&buf, key); err != nil {
+			return nil, err
+		}
+		buf.WriteByte(':')
+		if err := writeJSON(&buf, o.values[key]); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func writeJSON(buf *bytes.Buffer, value interface{}) error {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return err
+	}
+	buf.Truncate(buf.Len() - 1) // Encode appends a newline
+	return nil
+}
+
+func encodeObject(doc *Object) ([]byte, error) {
+	var compact bytes.Buffer
+	if err := writeJSON(&compact, doc); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, compact.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// decodeObject reads a JSON object keeping key order; numbers stay json.Number so they are
+// written back exactly as they were.
+func decodeObject(data []byte) (*Object, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	value, err := decodeValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the workflow object")
+	}
+	doc, ok := value.(*Object)
+	if !ok {
+		return nil, fmt.Errorf("workflow must be a JSON object")
+	}
+	return doc, nil
+}
+
+func decodeValue(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		obj := newObject()
+		for dec.More() {
+			keyToken, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(keyToken.(string), value)
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []interface{}{}
+		for dec.More() {
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err := dec.Token()
+		return arr, err
+	}
+	return token, nil
+}
@@ -0,0 +1,277 @@
+package traverser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"rpa-dfs-engine/internal/logger"
+)
+
+// Join policies of a parallel node: all branches must succeed, any one must succeed,
+// or the first to succeed wins and the others are cancelled.
+const (
+	JoinAll          = "all"
+	JoinAny          = "any"
+	JoinFirstSuccess = "first-success"
+)
+
+var joinPolicies = []string{JoinAll, JoinAny, JoinFirstSuccess}
+
+// branchNamePattern keeps branch names usable as context keys (vars.<branch>).
+var branchNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// errBranchCancelled stops a branch once a first-success join has a winner.
+var errBranchCancelled = errors.New("branch cancelled")
+
+// Tab is a browser tab opened for a parallel branch.
+type Tab interface {
+	Browser
+	Close()
+}
+
+// tabOpener is implemented by browsers that can open more tabs, such as browser.Session.
+// An isolated tab has its own cookies and storage.
+type tabOpener interface {
+	OpenTab(isolated bool) (Tab, error)
+}
+
+// BranchError is the failure of one parallel branch.
+type BranchError struct {
+	Branch string
+	Err    error
+}
+
+func (e BranchError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Branch, e.Err)
+}
+
+// ParallelError is returned when the failed branches of a parallel node break its join policy.
+type ParallelError struct {
+	Join     string
+	Total    int
+	Failures []BranchError
+}
+
+func (e *ParallelError) Error() string {
+	messages := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		messages[i] = failure.Error()
+	}
+	return fmt.Sprintf("parallel join %s: %d of %d branches failed:\n  %s",
+		e.Join, len(e.Failures), e.Total, strings.Join(messages, "\n  "))
+}
+
+// Unwrap returns the cause of each branch failure, without the branch's call stack,
+// so error kinds are classified while the parallel node keeps its own stack frame.
+func (e *ParallelError) Unwrap() []error {
+	causes := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		causes[i] = failure.Err
+		var wfErr *WorkflowError
+		if errors.As(failure.Err, &wfErr) {
+			causes[i] = wfErr.Err
+		}
+	}
+	return causes
+}
+
+// branch is one running branch of a parallel node.
+type branch struct {
+	name      string
+	start     *Node
+	engine    *Engine
+	err       error
+	cancelled bool
+}
+
+// executeParallel runs each branch in its own tab with a copy of the context, at most
+// maxConcurrency at a time. The vars of every branch that succeeded are merged into
+// the parent context as vars.<branch>.
+func (e *Engine) executeParallel(node *Node) error {
+	join := node.Join
+	if join == "" {
+		join = JoinAll
+	}
+	names := sortedNodeKeys(node.Parallel)
+	if e.plan != nil {
+		return e.planParallel(node, names)
+	}
+
+	opener, ok := e.browser.(tabOpener)
+	if !ok {
+		return fmt.Errorf("the browser cannot open tabs for parallel branches")
+	}
+	// Branches share the workflow, so its lazy indexes are built before they start.
+	e.workflow.index()
+
+	limit := node.MaxConcurrency
+	if limit <= 0 || limit > len(names) {
+		limit = len(names)
+	}
+	runCtx, cancel := context.WithCancel(e.runCtx)
+	defer cancel()
+
+	// Branches start in name order; a slot is taken before a branch starts.
+	slots := make(chan struct{}, limit)
+	branches := make([]*branch, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		b := &branch{name: name, start: node.Parallel[name], engine: e.fork(runCtx)}
+		branches[i] = b
+		slots <- struct{}{}
+		if runCtx.Err() != nil {
+			b.cancelled = true
+			<-slots
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			b.err = b.run(opener, node.Isolate)
+			if errors.Is(b.err, errBranchCancelled) {
+				b.cancelled, b.err = true, nil
+			}
+			if b.err == nil && !b.cancelled && join == JoinFirstSuccess {
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return e.joinBranches(join, branches)
+}
+
+// run performs the branch in a new tab.
+func (b *branch) run(opener tabOpener, isolated bool) error {
+	tab, err := opener.OpenTab(isolated)
+	if err != nil {
+		return &ActionError{Action: NodeTypeParallel, Target: b.name, Err: err}
+	}
+	defer tab.Close()
+
+	b.engine.browser = tab
+	logger.LogInfo("Branch %s started", b.name)
+	err = b.engine.executeNode(b.start)
+	b.engine.result.FinishedAt = time.Now()
+	return err
+}
+
+// joinBranches merges the branches into this engine and applies the join policy.
+func (e *Engine) joinBranches(join string, branches []*branch) error {
+	var failures []BranchError
+	succeeded := 0
+	for _, b := range branches {
+		for _, nodeResult := range b.engine.result.Nodes {
+			nodeResult.Branch = joinBranchName(b.name, nodeResult.Branch)
+			e.result.Nodes = append(e.result.Nodes, nodeResult)
+		}
+		e.assertionFailures = append(e.assertionFailures, b.engine.assertionFailures...)
+
+		switch {
+		case b.cancelled:
+			logger.LogInfo("Branch %s cancelled", b.name)
+		case b.err != nil:
+			logger.LogError("Branch %s failed: %v", b.name, b.err)
+			failures = append(failures, BranchError{Branch: b.name, Err: b.err})
+		default:
+			logger.LogSuccess("Branch %s completed", b.name)
+			succeeded++
+			if err := e.mergeBranch(b.name, b.engine); err != nil {
+				return err
+			}
+		}
+	}
+
+	if (join == JoinAll && len(failures) > 0) || (join != JoinAll && succeeded == 0) {
+		return &ParallelError{Join: join, Total: len(branches), Failures: failures}
+	}
+	return nil
+}
+
+// mergeBranch stores the branch's vars under vars.<name> and reports its extracted values there.
+func (e *Engine) mergeBranch(name string, child *Engine) error {
+	vars, _ := child.context.Get("vars")
+	if err := e.context.Set("vars."+name, vars); err != nil {
+		return err
+	}
+	for path, value := range child.result.Extracted {
+		if !strings.HasPrefix(path, "vars.") {
+			continue
+		}
+		if e.result.Extracted == nil {
+			e.result.Extracted = make(map[string]interface{})
+		}
+		e.result.Extracted["vars."+name+"."+strings.TrimPrefix(path, "vars.")] = value
+	}
+	return nil
+}
+
+// planParallel walks the branches one after another in a dry run.
+func (e *Engine) planParallel(node *Node, names []string) error {
+	parent := e.context
+	defer func() { e.context = parent }()
+
+	for _, name := range names {
+		e.planNote("branch %s", name)
+		e.context = parent.clone()
+		if err := e.executeNode(node.Parallel[name]); err != nil {
+			return err
+		}
+		vars, _ := e.context.Get("vars")
+		if err := parent.Set("vars."+name, vars); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fork returns an engine for a branch: same workflow and settings, a copy of the
+// context, its own run state and no checkpoints or debugger.
+func (e *Engine) fork(runCtx context.Context) *Engine {
+	ctx := e.context.clone()
+	return &Engine{
+		workflow:          e.workflow,
+		context:           ctx,
+		input:             e.input,
+		output:            e.output,
+		prompt:            e.prompt,
+		strict:            e.strict,
+		maxVisits:         e.maxVisits,
+		visits:            make(map[*Node]int),
+		catalogDir:        e.catalogDir,
+		maxCallDepth:      e.maxCallDepth,
+		callDepth:         e.callDepth,
+		depth:             e.depth,
+		loaded:            make(map[string]*Workflow),
+		runCtx:            runCtx,
+		result:            &RunResult{RunID: e.result.RunID, Workflow: e.workflow.Name(), StartedAt: time.Now()},
+		rootWorkflow:      e.rootWorkflow,
+		rootContext:       ctx,
+		failures:          make(map[int]string),
+		answer:            e.answer,
+		collectAssertions: e.collectAssertions,
+	}
+}
+
+func joinBranchName(parent, child string) string {
+	if child == "" {
+		return parent
+	}
+	return parent + "/" + child
+}
+
+func sortedNodeKeys(m map[string]*Node) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,229 @@
+package traverser
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// secretPattern matches context keys whose values are never shown in a plan.
+var secretPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key|otp)`)
+
+// secretMask replaces secret values in plan output.
+const secretMask = "********"
+
+// Plan is what a dry run would have done, one step per node in execution order.
+type Plan struct {
+	Steps []PlanStep `json:"steps"`
+
+	secrets map[string]bool
+	running []int
+}
+
+// PlanStep is one node of a dry run: the resolved actions it would perform,
+// the branch it would take, and anything that would go wrong.
+type PlanStep struct {
+	Depth    int      `json:"depth"`
+	Workflow string   `json:"workflow"`
+	Node     string   `json:"node"`
+	NodeType string   `json:"nodeType"`
+	Actions  []string `json:"actions,omitempty"`
+	Issues   []string `json:"issues,omitempty"`
+}
+
+// IssueCount returns how many problems the dry run found.
+func (p *Plan) IssueCount() int {
+	count := 0
+	for _, step := range p.Steps {
+		count += len(step.Issues)
+	}
+	return count
+}
+
+// SetDryRun makes Execute walk the workflow without a browser and record a Plan instead.
+// Questions are answered "continue", waits do not sleep and no checkpoints are written.
+func (e *Engine) SetDryRun(dryRun bool) {
+	e.dryRun = dryRun
+}
+
+// Plan returns the plan recorded by the last dry run, or nil.
+func (e *Engine) Plan() *Plan {
+	return e.plan
+}
+
+// beginPlanStep adds a step for node. Until endPlanStep, notes and issues are attached to it.
+func (e *Engine) beginPlanStep(node *Node, depth int) {
+	if e.plan == nil {
+		return
+	}
+	e.plan.Steps = append(e.plan.Steps, PlanStep{
+		Depth:    depth,
+		Workflow: e.workflow.Name(),
+		Node:     node.Label(),
+		NodeType: node.NodeType,
+	})
+	e.plan.running = append(e.plan.running, len(e.plan.Steps)-1)
+}
+
+func (e *Engine) endPlanStep() {
+	if e.plan != nil && len(e.plan.running) > 0 {
+		e.plan.running = e.plan.running[:len(e.plan.running)-1]
+	}
+}
+
+// current returns the step of the innermost node still running.
+func (p *Plan) current() *PlanStep {
+	if len(p.running) == 0 {
+		return nil
+	}
+	return &p.Steps[p.running[len(p.running)-1]]
+}
+
+// planNote adds a line to the current plan step; secret values are masked.
+func (e *Engine) planNote(format string, args ...interface{}) {
+	if e.plan == nil || e.plan.current() == nil {
+		return
+	}
+	step := e.plan.current()
+	step.Actions = append(step.Actions, e.plan.mask(fmt.Sprintf(format, args...)))
+}
+
+func (e *Engine) planIssue(format string, args ...interface{}) {
+	if e.plan == nil || e.plan.current() == nil {
+		return
+	}
+	step := e.plan.current()
+	step.Issues = append(step.Issues, e.plan.mask(fmt.Sprintf(format, args...)))
+}
+
+// resolve expands a template for the plan. Unresolved references become issues instead of
+// errors, and values of secret-looking keys are remembered so they can be masked.
+func (p *Plan) resolve(ctx *Context, template string, strict bool) (string, error) {
+	result, unresolved, err := expandTemplate(ctx, template, func(path, value string) {
+		if value != "" && IsSecretPath(path) {
+			p.secrets[value] = true
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	if step := p.current(); len(unresolved) > 0 && step != nil {
+		severity := "warning"
+		if strict {
+			severity = "error"
+		}
+		step.Issues = append(step.Issues,
+			fmt.Sprintf("%s: unresolved %s", severity, strings.Join(unresolved, ", ")))
+	}
+	return result, nil
+}
+
+func (p *Plan) mask(text string) string {
+	secrets := make([]string, 0, len(p.secrets))
+	for secret := range p.secrets {
+		secrets = append(secrets, secret)
+	}
+	// Longest first, so a secret containing another is masked whole.
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	for _, secret := range secrets {
+		text = strings.ReplaceAll(text, secret, secretMask)
+	}
+	return text
+}
+
+// IsSecretPath reports whether the last key of path looks like it holds a password or token.
+func IsSecretPath(path string) bool {
+	segments, err := splitPath(path)
+	if err != nil || len(segments) == 0 {
+		return false
+	}
+	return secretPattern.MatchString(segments[len(segments)-1].value)
+}
+
+// dryRunBrowser stands in for the browser during a dry run and describes each action on the plan.
+type dryRunBrowser struct {
+	engine *Engine
+	url    string
+}
+
+func (b *dryRunBrowser) NavigateTo(url string) error {
+	b.url = url
+	b.engine.planNote("navigate %s", url)
+	return nil
+}
+
+func (b *dryRunBrowser) FillField(selector, value string) error {
+	b.engine.planNote("fill %s = %q", selector, value)
+	return nil
+}
+
+func (b *dryRunBrowser) ClickButton(selector string) error {
+	b.engine.planNote("click %s", selector)
+	return nil
+}
+
+func (b *dryRunBrowser) UploadFile(selector, filePath string) error {
+	b.engine.planNote("upload %s to %s", filePath, selector)
+	if _, err := os.Stat(filePath); err != nil {
+		b.engine.planIssue("error: file not found: %s", filePath)
+	}
+	return nil
+}
+
+// The dry run cannot read the page, so extractions store a placeholder describing the read.
+func (b *dryRunBrowser) Text(selector string) (string, error) {
+	b.engine.planNote("read text of %s", selector)
+	return fmt.Sprintf("<text of %s>", selector), nil
+}
+
+func (b *dryRunBrowser) Attribute(selector, name string) (string, error) {
+	b.engine.planNote("read %s of %s", name, selector)
+	return fmt.Sprintf("<%s of %s>", name, selector), nil
+}
+
+func (b *dryRunBrowser) Value(selector string) (string, error) {
+	b.engine.planNote("read value of %s", selector)
+	return fmt.Sprintf("<value of %s>", selector), nil
+}
+
+func (b *dryRunBrowser) Count(selector string) (int, error) {
+	b.engine.planNote("count %s", selector)
+	return 0, nil
+}
+
+func (b *dryRunBrowser) Visible(selector string) (bool, error) {
+	return true, nil
+}
+
+func (b *dryRunBrowser) Enabled(selector string) (bool, error) {
+	return true, nil
+}
+
+func (b *dryRunBrowser) Truthy(script string, args []interface{}) (bool, error) {
+	return true, nil
+}
+
+func (b *dryRunBrowser) NetworkIdle(quiet time.Duration) (bool, error) {
+	return true, nil
+}
+
+// Scripts are not run; arguments are left out of the plan as they may hold secrets.
+func (b *dryRunBrowser) Evaluate(frame, script string, args []interface{}) (interface{}, error) {
+	target := "page"
+	if frame != "" {
+		target = "frame " + frame
+	}
+	b.engine.planNote("evaluate script in %s with %d args", target, len(args))
+	return "<result of script>", nil
+}
+
+func (b *dryRunBrowser) CurrentURL() (string, error) {
+	return b.url, nil
+}
+
+func (b *dryRunBrowser) Title() (string, error) {
+	return "", nil
+}
@@ -0,0 +1,167 @@
+package traverser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"rpa-dfs-engine/internal/logger"
+)
+
+// Error kinds a retry policy can select with "on".
+const (
+	ErrorKindTimeout   = "timeout"
+	ErrorKindNotFound  = "notFound"
+	ErrorKindBrowser   = "browser"
+	ErrorKindData      = "data"
+	ErrorKindAssertion = "assertion"
+	ErrorKindScript    = "script"
+)
+
+var errorKinds = []string{ErrorKindTimeout, ErrorKindNotFound, ErrorKindBrowser, ErrorKindData, ErrorKindAssertion, ErrorKindScript}
+
+// ErrElementNotFound is wrapped by browsers when a selector matches nothing in time.
+var ErrElementNotFound = errors.New("element not found")
+
+// RetryPolicy re-runs a failing node. Backoff is the delay in milliseconds before the
+// first retry and is multiplied by Factor for each further one. On limits retries to
+// the listed error kinds; empty means every kind.
+type RetryPolicy struct {
+	Count   int      `json:"count"`
+	Backoff int      `json:"backoff,omitempty"`
+	Factor  float64  `json:"factor,omitempty"`
+	On      []string `json:"on,omitempty"`
+}
+
+// ActionError is a failure reported by the browser while performing a node's action.
+type ActionError struct {
+	Action string
+	Target string
+	Err    error
+}
+
+func (e *ActionError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Action, e.Target, e.Err)
+}
+
+func (e *ActionError) Unwrap() error {
+	return e.Err
+}
+
+// timeoutBrowser is implemented by browsers whose per-action timeout can be changed,
+// such as browser.Session.
+type timeoutBrowser interface {
+	ActionTimeout() time.Duration
+	SetActionTimeout(timeout time.Duration)
+}
+
+// ErrorKind classifies err for retry policies and run results.
+func ErrorKind(err error) string {
+	var actionErr *ActionError
+	var replayed *replayedError
+	var assertionErr *AssertionError
+	var assertionFailures *AssertionFailures
+	var scriptErr *ScriptError
+	switch {
+	case errors.As(err, &replayed):
+		return replayed.kind
+	case errors.As(err, &assertionErr), errors.As(err, &assertionFailures):
+		return ErrorKindAssertion
+	case errors.As(err, &scriptErr):
+		return ErrorKindScript
+	case errors.Is(err, ErrElementNotFound):
+		return ErrorKindNotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorKindTimeout
+	case errors.As(err, &actionErr):
+		return ErrorKindBrowser
+	default:
+		return ErrorKindData
+	}
+}
+
+// retries reports whether err should be retried after the given attempt.
+func (p *RetryPolicy) retries(attempt int, err error) bool {
+	if p == nil || attempt > p.Count {
+		return false
+	}
+	if len(p.On) == 0 {
+		return true
+	}
+	return containsString(p.On, ErrorKind(err))
+}
+
+// delay returns how long to wait before the retry that follows attempt.
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	delay := float64(p.Backoff)
+	for i := 1; i < attempt && p.Factor > 0; i++ {
+		delay *= p.Factor
+	}
+	return time.Duration(delay) * time.Millisecond
+}
+
+// runWithPolicy performs the node's action under its timeout, retry and onError policies
+// and records the outcome in the run result.
+func (e *Engine) runWithPolicy(node *Node) error {
+	index := e.beginNode(node)
+	step := e.beginStep()
+	replay := e.replaying(step) && !node.isContainer()
+
+	if browser, ok := e.browser.(timeoutBrowser); ok && node.Timeout > 0 {
+		previous := browser.ActionTimeout()
+		browser.SetActionTimeout(time.Duration(node.Timeout) * time.Millisecond)
+		defer browser.SetActionTimeout(previous)
+		e.applyPolicy(index, "timeout")
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		e.result.Nodes[index].Attempts = attempt
+		if replay {
+			err = e.replayAction(node, step)
+		} else {
+			err = e.executeAction(node)
+		}
+		if err == nil || !node.Retry.retries(attempt, err) {
+			break
+		}
+		e.applyPolicy(index, "retry")
+		if e.replaying(e.step) {
+			continue
+		}
+
+		delay := node.Retry.delay(attempt)
+		logger.LogWarning("%s failed (attempt %d/%d), retrying in %v: %v",
+			node.Label(), attempt, node.Retry.Count+1, delay, err)
+		time.Sleep(delay)
+	}
+
+	switch {
+	case err == nil && replay:
+		e.finishNode(index, NodeReplayed, nil)
+		return nil
+	case err == nil:
+		e.finishNode(index, NodeSucceeded, nil)
+		if !node.isContainer() {
+			e.saveCheckpoint(node, step)
+		}
+		return nil
+	}
+	if !node.isContainer() {
+		e.recordFailure(step, err)
+	}
+	if node.OnError == nil {
+		e.finishNode(index, NodeFailed, err)
+		if e.collectAssertion(node, err) {
+			e.saveCheckpoint(node, step)
+			return nil
+		}
+		return err
+	}
+
+	logger.LogWarning("%s failed, running onError: %v", node.Label(), err)
+	e.applyPolicy(index, "onError")
+	e.finishNode(index, NodeRecovered, err)
+	return e.executeNode(node.OnError)
+}
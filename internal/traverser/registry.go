@@ -0,0 +1,191 @@
+package traverser
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"rpa-dfs-engine/internal/logger"
+)
+
+// CustomNodeType is a node type added by a Go extension, such as "selectDatePicker".
+// Like built-in nodes it performs a single action.
+type CustomNodeType struct {
+	Name string
+
+	// Required and Optional list the properties the node accepts besides the common ones
+	// (id, next, nextId, retry, timeout, onError...). Their values are in Node.Properties.
+	Required map[string]PropertyKind
+	Optional map[string]PropertyKind
+
+	// KeyProperty is shown under the node in graphs, e.g. "selector".
+	KeyProperty string
+
+	// Validate, if set, checks the properties after their kinds have been accepted.
+	// The error is reported at the node.
+	Validate func(properties map[string]interface{}) error
+
+	// Execute performs the node. Browser failures should be returned as *ActionError so
+	// retry policies classify them; wrap ErrElementNotFound when nothing matches.
+	Execute func(ctx *NodeContext) error
+}
+
+var (
+	customNodeTypes   = make(map[string]*CustomNodeType)
+	customNodeTypesMu sync.RWMutex
+)
+
+// RegisterNodeType adds a node type to validation, execution and graphs. It is meant to be
+// called from an init function, before any workflow is loaded.
+func RegisterNodeType(nodeType CustomNodeType) error {
+	switch {
+	case nodeType.Name == "":
+		return fmt.Errorf("custom node type needs a name")
+	case nodeType.Execute == nil:
+		return fmt.Errorf("custom node type %q needs an Execute function", nodeType.Name)
+	case forbiddenNodeTypes[nodeType.Name] || multiActionPattern.MatchString(nodeType.Name):
+		return fmt.Errorf("custom node type %q performs multiple actions; register one type per action", nodeType.Name)
+	}
+	if _, builtIn := nodeSchemas[nodeType.Name]; builtIn {
+		return fmt.Errorf("node type %q is built in", nodeType.Name)
+	}
+	for _, properties := range []map[string]PropertyKind{nodeType.Required, nodeType.Optional} {
+		for name := range properties {
+			if _, common := commonProperties[name]; common || childKeys[name] {
+				return fmt.Errorf("custom node type %q: property %q is reserved", nodeType.Name, name)
+			}
+		}
+	}
+
+	customNodeTypesMu.Lock()
+	defer customNodeTypesMu.Unlock()
+	if _, exists := customNodeTypes[nodeType.Name]; exists {
+		return fmt.Errorf("node type %q is already registered", nodeType.Name)
+	}
+	customNodeTypes[nodeType.Name] = &nodeType
+	return nil
+}
+
+// UnregisterNodeType removes a node type added with RegisterNodeType.
+func UnregisterNodeType(name string) {
+	customNodeTypesMu.Lock()
+	defer customNodeTypesMu.Unlock()
+	delete(customNodeTypes, name)
+}
+
+// RegisteredNodeTypes returns the names of the custom node types, sorted.
+func RegisteredNodeTypes() []string {
+	customNodeTypesMu.RLock()
+	defer customNodeTypesMu.RUnlock()
+	names := make([]string, 0, len(customNodeTypes))
+	for name := range customNodeTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func customNodeType(name string) (*CustomNodeType, bool) {
+	customNodeTypesMu.RLock()
+	defer customNodeTypesMu.RUnlock()
+	nodeType, ok := customNodeTypes[name]
+	return nodeType, ok
+}
+
+// schema returns the properties of the custom type in the form the validator checks.
+func (t *CustomNodeType) schema() nodeSchema {
+	return nodeSchema{required: t.Required, optional: t.Optional}
+}
+
+// UnmarshalJSON decodes a node and, for custom node types, keeps the properties the type
+// declares in Properties.
+func (n *Node) UnmarshalJSON(data []byte) error {
+	type plainNode Node
+	if err := json.Unmarshal(data, (*plainNode)(n)); err != nil {
+		return err
+	}
+	nodeType, ok := customNodeType(n.NodeType)
+	if !ok {
+		return nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	n.Properties = make(map[string]interface{})
+	for name, value := range fields {
+		_, required := nodeType.Required[name]
+		_, optional := nodeType.Optional[name]
+		if required || optional {
+			n.Properties[name] = value
+		}
+	}
+	return nil
+}
+
+// NodeContext gives a custom node's executor the node, the browser, the run context and
+// the log. During a dry run Browser describes actions on the plan instead of performing them.
+type NodeContext struct {
+	Node *Node
+
+	engine *Engine
+}
+
+// Browser returns the browser of the tab the node runs in.
+func (c *NodeContext) Browser() Browser {
+	return c.engine.browser
+}
+
+// DryRun reports whether the workflow is being planned rather than run. Executors should
+// not cause side effects outside the browser during a dry run.
+func (c *NodeContext) DryRun() bool {
+	return c.engine.dryRun
+}
+
+// Property returns a property of the node as decoded from JSON: strings, float64 numbers,
+// booleans, []interface{} and map[string]interface{}.
+func (c *NodeContext) Property(name string) (interface{}, bool) {
+	value, ok := c.Node.Properties[name]
+	return value, ok
+}
+
+// String returns a string property with its {{templates}} resolved, or "" if it is not set.
+func (c *NodeContext) String(name string) (string, error) {
+	value, ok := c.Node.Properties[name].(string)
+	if !ok {
+		return "", nil
+	}
+	return c.engine.resolveString(value)
+}
+
+// Get resolves a context path such as "user.profile.email". Encrypted values are returned
+// as Secret; String decrypts the templates it resolves.
+func (c *NodeContext) Get(path string) (interface{}, bool) {
+	return c.engine.context.Get(path)
+}
+
+// Set stores a value at a context path such as "vars.ticket".
+func (c *NodeContext) Set(path string, value interface{}) error {
+	return c.engine.context.Set(path, value)
+}
+
+// LogInfo writes to the engine log, prefixed with the node label.
+func (c *NodeContext) LogInfo(format string, args ...interface{}) {
+	logger.LogInfo("%s: %s", c.Node.Label(), fmt.Sprintf(format, args...))
+}
+
+// LogWarning writes a warning to the engine log, prefixed with the node label.
+func (c *NodeContext) LogWarning(format string, args ...interface{}) {
+	logger.LogWarning("%s: %s", c.Node.Label(), fmt.Sprintf(format, args...))
+}
+
+// LogDebug writes a debug line to the engine log, prefixed with the node label.
+func (c *NodeContext) LogDebug(format string, args ...interface{}) {
+	logger.LogDebug("%s: %s", c.Node.Label(), fmt.Sprintf(format, args...))
+}
+
+func (e *Engine) executeCustom(nodeType *CustomNodeType, node *Node) error {
+	return nodeType.Execute(&NodeContext{Node: node, engine: e})
+}
@@ -0,0 +1,122 @@
+package traverser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Run and node statuses reported in a RunResult.
+const (
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+
+	NodeRunning   = "running"
+	NodeSucceeded = "succeeded"
+	NodeFailed    = "failed"
+	NodeRecovered = "recovered"
+	NodeReplayed  = "replayed"
+)
+
+// RunResult describes one execution of a workflow.
+type RunResult struct {
+	RunID      string       `json:"runId"`
+	Workflow   string       `json:"workflow"`
+	Status     string       `json:"status"`
+	Error      string       `json:"error,omitempty"`
+	ErrorKind  string       `json:"errorKind,omitempty"`
+	StartedAt  time.Time    `json:"startedAt"`
+	FinishedAt time.Time    `json:"finishedAt"`
+	Nodes      []NodeResult `json:"nodes"`
+
+	// Extracted holds the last value stored by each extraction node, by context path.
+	Extracted map[string]interface{} `json:"extracted,omitempty"`
+}
+
+// NodeResult is one executed node, in execution order. Policies lists the policies
+// that took effect: "timeout", "retry" and "onError". Actions skipped while
+// resuming from a checkpoint are reported as "replayed". Failed assertions also
+// report the expected and actual values. Nodes run by parallel branches name their
+// branch, nested branches joined by "/". Pointer locates the node in its workflow document.
+type NodeResult struct {
+	Workflow  string   `json:"workflow"`
+	Branch    string   `json:"branch,omitempty"`
+	Node      string   `json:"node"`
+	Pointer   string   `json:"pointer,omitempty"`
+	NodeType  string   `json:"nodeType"`
+	Status    string   `json:"status"`
+	Attempts  int      `json:"attempts"`
+	Policies  []string `json:"policies,omitempty"`
+	Error     string   `json:"error,omitempty"`
+	ErrorKind string   `json:"errorKind,omitempty"`
+	Expected  string   `json:"expected,omitempty"`
+	Actual    string   `json:"actual,omitempty"`
+}
+
+// Save writes the result as indented JSON, creating the parent directory.
+func (r *RunResult) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating result directory: %w", err)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding run result: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("error writing run result: %w", err)
+	}
+	return nil
+}
+
+// LoadRunResult reads a result file written by Save.
+func LoadRunResult(path string) (*RunResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading run result: %w", err)
+	}
+	var result RunResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("error parsing run result: %w", err)
+	}
+	return &result, nil
+}
+
+// newRunID returns a sortable id for a run started at t.
+func newRunID(t time.Time) string {
+	return t.Format("20060102-150405.000")
+}
+
+func (e *Engine) beginNode(node *Node) int {
+	e.result.Nodes = append(e.result.Nodes, NodeResult{
+		Workflow: e.workflow.Name(),
+		Node:     node.Label(),
+		Pointer:  e.workflow.Pointer(node),
+		NodeType: node.NodeType,
+		Status:   NodeRunning,
+	})
+	return len(e.result.Nodes) - 1
+}
+
+func (e *Engine) applyPolicy(index int, policy string) {
+	nodeResult := &e.result.Nodes[index]
+	if !containsString(nodeResult.Policies, policy) {
+		nodeResult.Policies = append(nodeResult.Policies, policy)
+	}
+}
+
+func (e *Engine) finishNode(index int, status string, err error) {
+	nodeResult := &e.result.Nodes[index]
+	nodeResult.Status = status
+	if err != nil {
+		nodeResult.Error = err.Error()
+		nodeResult.ErrorKind = ErrorKind(err)
+	}
+	var assertionErr *AssertionError
+	if errors.As(err, &assertionErr) {
+		nodeResult.Expected = assertionErr.Expected
+		nodeResult.Actual = assertionErr.Actual
+	}
+}
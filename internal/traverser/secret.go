@@ -0,0 +1,426 @@
+package traverser
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"rpa-dfs-engine/internal/logger"
+)
+
+// SecretKeyEnv is the environment variable holding the secret key when no key file is given.
+const SecretKeyEnv = "RPA_SECRET_KEY"
+
+const (
+	secretField   = "$secret"
+	secretPrefix  = "enc:"
+	secretKeySize = 32
+)
+
+// ErrNoSecretKey is returned when an encrypted value is read and no key was configured.
+var ErrNoSecretKey = errors.New("context has encrypted values but no secret key: set " + SecretKeyEnv + " or pass a key file")
+
+// secretValuePattern matches the ciphertext of a $secret entry in a JSON or YAML context file.
+var secretValuePattern = regexp.MustCompile(`("?\$secret"?\s*:\s*["']?)(enc:[A-Za-z0-9+/]+=*)`)
+
+// Secret is an encrypted context value, written {"$secret": "enc:..."} in context files.
+// It stays encrypted in the context and is decrypted when a template, condition or check
+// reads it. Copies such as callWorkflow params and checkpoints keep it encrypted.
+type Secret struct {
+	Ciphertext string
+}
+
+// String never shows the value, not even encrypted.
+func (s Secret) String() string {
+	return secretMask
+}
+
+// MarshalJSON writes the secret back in its context file form.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{secretField: s.Ciphertext})
+}
+
+// GenerateSecretKey returns a new random key, base64 encoded as read by ParseSecretKey.
+func GenerateSecretKey() (string, error) {
+	key := make([]byte, secretKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("error generating secret key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ParseSecretKey decodes a base64 encoded 256-bit key.
+func ParseSecretKey(text string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+	if err != nil {
+		return nil, fmt.Errorf("secret key is not valid base64: %w", err)
+	}
+	if len(key) != secretKeySize {
+		return nil, fmt.Errorf("secret key must be %d bytes, got %d", secretKeySize, len(key))
+	}
+	return key, nil
+}
+
+// LoadSecretKey reads the key from the file at path or, when path is empty, from the
+// SecretKeyEnv environment variable. It returns nil when neither is set.
+func LoadSecretKey(path string) ([]byte, error) {
+	if path == "" {
+		text := os.Getenv(SecretKeyEnv)
+		if text == "" {
+			return nil, nil
+		}
+		key, err := ParseSecretKey(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", SecretKeyEnv, err)
+		}
+		return key, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading secret key file: %w", err)
+	}
+	key, err := ParseSecretKey(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+// EncryptSecret encrypts plaintext with AES-256-GCM and returns the "enc:..." form used
+// in context files.
+func EncryptSecret(key []byte, plaintext string) (string, error) {
+	aead, err := newSecretCipher(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("error encrypting secret: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return secretPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret decrypts a value produced by EncryptSecret.
+func DecryptSecret(key []byte, value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, secretPrefix)
+	if !ok {
+		return "", fmt.Errorf("secret must start with %q", secretPrefix)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("secret is not valid base64: %w", err)
+	}
+	aead, err := newSecretCipher(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("secret is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt secret: wrong key or corrupted value")
+	}
+	return string(plaintext), nil
+}
+
+func newSecretCipher(key []byte) (cipher.AEAD, error) {
+	if len(key) != secretKeySize {
+		return nil, fmt.Errorf("secret key must be %d bytes, got %d", secretKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// RotateSecrets re-encrypts every $secret value of a JSON or YAML context file from oldKey
+// to newKey and returns the new file and the number of values rotated. The rest of the
+// file, including formatting and comments, is left as it was.
+func RotateSecrets(data []byte, oldKey, newKey []byte) ([]byte, int, error) {
+	var rotateErr error
+	count := 0
+	rotated := secretValuePattern.ReplaceAllFunc(data, func(match []byte) []byte {
+		if rotateErr != nil {
+			return match
+		}
+		groups := secretValuePattern.FindSubmatch(match)
+		plaintext, err := DecryptSecret(oldKey, string(groups[2]))
+		if err != nil {
+			rotateErr = fmt.Errorf("secret %d: %w", count+1, err)
+			return match
+		}
+		value, err := EncryptSecret(newKey, plaintext)
+		if err != nil {
+			rotateErr = err
+			return match
+		}
+		count++
+		return append(groups[1], value...)
+	})
+	if rotateErr != nil {
+		return nil, 0, rotateErr
+	}
+	return rotated, count, nil
+}
+
+// decodeSecrets replaces every {"$secret": "enc:..."} object below value with a Secret.
+// path names value in errors.
+func decodeSecrets(value interface{}, path string) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if raw, ok := v[secretField]; ok {
+			text, isString := raw.(string)
+			if len(v) != 1 || !isString || !strings.HasPrefix(text, secretPrefix) {
+				return nil, fmt.Errorf("%s: %s must be the only key and hold an %q value; create one with \"secret encrypt\"",
+					path, secretField, secretPrefix)
+			}
+			return Secret{Ciphertext: text}, nil
+		}
+		for key, item := range v {
+			decoded, err := decodeSecrets(item, joinPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			v[key] = decoded
+		}
+	case []interface{}:
+		for i, item := range v {
+			decoded, err := decodeSecrets(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = decoded
+		}
+	}
+	return value, nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// secretStore decrypts the secrets of a run and remembers the values it revealed so they
+// can be masked in results. In a dry run (masked) secrets are never decrypted.
+type secretStore struct {
+	key    []byte
+	masked bool
+
+	mu       sync.Mutex
+	revealed []string
+}
+
+func (s *secretStore) reveal(secret Secret) (string, error) {
+	if s == nil || (s.key == nil && !s.masked) {
+		return "", ErrNoSecretKey
+	}
+	if s.masked {
+		return secretMask, nil
+	}
+	plaintext, err := DecryptSecret(s.key, secret.Ciphertext)
+	if err != nil {
+		return "", err
+	}
+	s.sensitive(plaintext)
+	return plaintext, nil
+}
+
+// sensitive masks text in logs and in the run result from now on. Besides decrypted
+// secrets this covers values derived from them, such as {{user.password | urlencode}}.
+func (s *secretStore) sensitive(text string) {
+	if s == nil || s.masked || text == "" {
+		return
+	}
+	logger.Redact(text)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !containsString(s.revealed, text) {
+		s.revealed = append(s.revealed, text)
+		// Longest first, so a secret containing another is masked whole.
+		sort.Slice(s.revealed, func(i, j int) bool { return len(s.revealed[i]) > len(s.revealed[j]) })
+	}
+}
+
+// mask replaces every secret revealed so far in text.
+func (s *secretStore) mask(text string) string {
+	if s == nil {
+		return text
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, secret := range s.revealed {
+		text = strings.ReplaceAll(text, secret, secretMask)
+	}
+	return text
+}
+
+// seal returns value with every string holding a revealed secret encrypted again, copying
+// the objects and arrays that hold one. Values derived from a secret, such as a
+// {{user.password | trim}} callWorkflow param, thus stay encrypted in copies of the context.
+func (s *secretStore) seal(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if s == nil || s.key == nil || s.mask(v) == v {
+			return v, nil
+		}
+		ciphertext, err := EncryptSecret(s.key, v)
+		if err != nil {
+			return nil, err
+		}
+		return Secret{Ciphertext: ciphertext}, nil
+	case map[string]interface{}:
+		sealed := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			var err error
+			if sealed[key], err = s.seal(item); err != nil {
+				return nil, err
+			}
+		}
+		return sealed, nil
+	case []interface{}:
+		sealed := make([]interface{}, len(v))
+		for i, item := range v {
+			var err error
+			if sealed[i], err = s.seal(item); err != nil {
+				return nil, err
+			}
+		}
+		return sealed, nil
+	}
+	return value, nil
+}
+
+// check decrypts every secret below value without keeping the results, so a missing or
+// wrong key fails the run before its first action.
+func (s *secretStore) check(value interface{}, path string) error {
+	switch v := value.(type) {
+	case Secret:
+		if s.key == nil {
+			return ErrNoSecretKey
+		}
+		if _, err := DecryptSecret(s.key, v.Ciphertext); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := s.check(v[key], joinPath(path, key)); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if err := s.check(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reveal decrypts value if it is a Secret.
+func (c *Context) reveal(value interface{}) (interface{}, error) {
+	if secret, ok := value.(Secret); ok {
+		return c.secrets.reveal(secret)
+	}
+	return value, nil
+}
+
+// revealAll returns value with every Secret below it decrypted, copying the objects and
+// arrays that hold one.
+func (c *Context) revealAll(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case Secret:
+		return c.secrets.reveal(v)
+	case map[string]interface{}:
+		revealed := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			var err error
+			if revealed[key], err = c.revealAll(item); err != nil {
+				return nil, err
+			}
+		}
+		return revealed, nil
+	case []interface{}:
+		revealed := make([]interface{}, len(v))
+		for i, item := range v {
+			var err error
+			if revealed[i], err = c.revealAll(item); err != nil {
+				return nil, err
+			}
+		}
+		return revealed, nil
+	}
+	return value, nil
+}
+
+// revealingResolver lets expressions compare secrets by their decrypted values.
+type revealingResolver struct {
+	ctx *Context
+}
+
+func (r revealingResolver) Get(path string) (interface{}, bool) {
+	value, ok := r.ctx.Get(path)
+	if !ok {
+		return nil, false
+	}
+	revealed, err := r.ctx.revealAll(value)
+	if err != nil {
+		return nil, false
+	}
+	return revealed, true
+}
+
+// redact masks the revealed secrets in every message and extracted value of the result.
+func (r *RunResult) redact(secrets *secretStore) {
+	r.Error = secrets.mask(r.Error)
+	for i := range r.Nodes {
+		node := &r.Nodes[i]
+		node.Error = secrets.mask(node.Error)
+		node.Expected = secrets.mask(node.Expected)
+		node.Actual = secrets.mask(node.Actual)
+	}
+	for path, value := range r.Extracted {
+		r.Extracted[path] = redactValue(value, secrets)
+	}
+}
+
+func redactValue(value interface{}, secrets *secretStore) interface{} {
+	switch v := value.(type) {
+	case string:
+		return secrets.mask(v)
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			redacted[key] = redactValue(item, secrets)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = redactValue(item, secrets)
+		}
+		return redacted
+	}
+	return value
+}
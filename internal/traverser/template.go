@@ -0,0 +1,209 @@
+package traverser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"rpa-dfs-engine/internal/expression"
+	"rpa-dfs-engine/internal/logger"
+)
+
+// UnresolvedReferenceError is returned in strict mode when a template references
+// context data that does not exist and has no default.
+type UnresolvedReferenceError struct {
+	References []string
+}
+
+func (e *UnresolvedReferenceError) Error() string {
+	return fmt.Sprintf("unresolved template references: %s", strings.Join(e.References, ", "))
+}
+
+// resolveString replaces {{path | filter}} references with values from the context.
+// Unresolved references are left in place, or fail the run in strict mode.
+// In dry-run mode they are reported on the plan instead.
+func (e *Engine) resolveString(template string) (string, error) {
+	if e.plan != nil {
+		return e.plan.resolve(e.context, template, e.strict)
+	}
+	return resolveTemplate(e.context, template, e.strict)
+}
+
+func resolveTemplate(ctx *Context, template string, strict bool) (string, error) {
+	result, unresolved, err := expandTemplate(ctx, template, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if len(unresolved) > 0 {
+		if strict {
+			return "", &UnresolvedReferenceError{References: unresolved}
+		}
+		logger.LogWarning("Unresolved template references: %s", strings.Join(unresolved, ", "))
+	}
+	return result, nil
+}
+
+// expandTemplate replaces every reference it can resolve and returns the references it could not.
+// visit, if set, is called with the path and formatted value of each resolved reference.
+func expandTemplate(ctx *Context, template string, visit func(path, value string)) (string, []string, error) {
+	var unresolved []string
+	result, err := replaceTemplates(template, func(match, source string) (string, error) {
+		expr, err := parseTemplateExpr(source)
+		if err != nil {
+			return "", err
+		}
+
+		value, found, err := evaluateTemplateExpr(ctx, expr)
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			unresolved = append(unresolved, match)
+			return match, nil
+		}
+		if value, err = ctx.reveal(value); err != nil {
+			return "", err
+		}
+		formatted := formatValue(value)
+		if visit != nil {
+			visit(expr.path, formatted)
+		}
+		return formatted, nil
+	})
+	return result, unresolved, err
+}
+
+func evaluateTemplateExpr(ctx *Context, expr templateExpr) (interface{}, bool, error) {
+	var value interface{}
+	var found bool
+	if expr.path == "now" {
+		value, found = time.Now(), true
+	} else {
+		value, found = ctx.Get(expr.path)
+	}
+	_, secret := value.(Secret)
+	if found && len(expr.filters) > 0 {
+		var err error
+		if value, err = ctx.reveal(value); err != nil {
+			return nil, false, err
+		}
+	}
+
+	for _, call := range expr.filters {
+		var err error
+		value, found, err = templateFilters[call.name](value, found, call)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	// Filtered secrets no longer match the plaintext the logs and result are masked with.
+	if secret && found && len(expr.filters) > 0 {
+		ctx.secrets.sensitive(formatValue(value))
+	}
+	return value, found, nil
+}
+
+// replaceTemplates calls fn for every {{...}} in s with the full match and its inner source.
+// Braces inside quoted filter arguments do not end a reference.
+func replaceTemplates(s string, fn func(match, source string) (string, error)) (string, error) {
+	var sb strings.Builder
+	for {
+		start := strings.Index(s, "{{")
+		if start < 0 {
+			sb.WriteString(s)
+			return sb.String(), nil
+		}
+		end := findTemplateEnd(s, start+2)
+		if end < 0 {
+			sb.WriteString(s)
+			return sb.String(), nil
+		}
+
+		replacement, err := fn(s[start:end+2], strings.TrimSpace(s[start+2:end]))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(s[:start])
+		sb.WriteString(replacement)
+		s = s[end+2:]
+	}
+}
+
+func findTemplateEnd(s string, from int) int {
+	var quote byte
+	for i := from; i < len(s)-1; i++ {
+		switch ch := s[i]; {
+		case quote != 0:
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '}' && s[i+1] == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+// templateErrors parses every reference in s and returns the first syntax error.
+func templateErrors(s string) error {
+	_, err := replaceTemplates(s, func(match, source string) (string, error) {
+		_, err := parseTemplateExpr(source)
+		return match, err
+	})
+	return err
+}
+
+// evaluateCondition evaluates a conditionExpression against the context.
+// References are looked up as typed values, never spliced into the expression text.
+// Secrets are compared by their decrypted values.
+func (e *Engine) evaluateCondition(source string) (bool, error) {
+	expr, err := expression.Parse(source)
+	if err != nil {
+		return false, fmt.Errorf("invalid condition %q: %w", source, err)
+	}
+	result, err := expr.EvaluateBool(revealingResolver{e.context})
+	if err != nil {
+		return false, fmt.Errorf("condition %q: %w", source, err)
+	}
+	return result, nil
+}
+
+// formatValue renders a context value for use inside a string.
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,916 @@
+package traverser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"rpa-dfs-engine/internal/expression"
+)
+
+// ValidationError is a single schema violation in a workflow document.
+type ValidationError struct {
+	Pointer string `json:"pointer"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	pointer := e.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return fmt.Sprintf("%s (line %d, column %d): %s", pointer, e.Line, e.Column, e.Message)
+}
+
+// ValidationErrors is the list of every violation found in a document.
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("workflow is invalid (%d errors):\n  %s", len(errs), strings.Join(messages, "\n  "))
+}
+
+// PropertyKind is the kind of value a node property accepts.
+type PropertyKind int
+
+const (
+	propString PropertyKind = iota
+	propInteger
+	propNode
+	propBranches
+	propCheck
+	propSequence
+	propExpression
+	propNodeRef
+	propObject
+	propStringMap
+	propRetry
+	propContextPath
+	propPattern
+	propMatch
+	propState
+	propAnswer
+	propParallel
+	propJoin
+	propBoolean
+	propArray
+)
+
+// Property kinds available to node types added with RegisterNodeType. Strings may contain
+// {{templates}}; context paths are written like storeAs, e.g. "vars.orderId".
+const (
+	PropertyString      = propString
+	PropertyInteger     = propInteger
+	PropertyBoolean     = propBoolean
+	PropertyObject      = propObject
+	PropertyStringMap   = propStringMap
+	PropertyContextPath = propContextPath
+	PropertyExpression  = propExpression
+	PropertyPattern     = propPattern
+	PropertyArray       = propArray
+)
+
+// nodeSchema lists the properties a node type accepts besides the common ones.
+type nodeSchema struct {
+	required map[string]PropertyKind
+	optional map[string]PropertyKind
+}
+
+// commonProperties are accepted on every node.
+var commonProperties = map[string]PropertyKind{
+	"nodeType":  propString,
+	"id":        propString,
+	"next":      propNode,
+	"nextId":    propNodeRef,
+	"maxVisits": propInteger,
+	"retry":     propRetry,
+	"timeout":   propInteger,
+	"onError":   propNode,
+}
+
+// nodeSchemas mirrors docs/traverser/03_JSON_SCHEMA.md.
+var nodeSchemas = map[string]nodeSchema{
+	NodeTypeMoveToPage: {
+		required: map[string]PropertyKind{"url": propString},
+	},
+	NodeTypeFillField: {
+		required: map[string]PropertyKind{"selector": propString, "value": propString},
+	},
+	NodeTypeClickButton: {
+		required: map[string]PropertyKind{"selector": propString},
+	},
+	NodeTypeSendFile: {
+		required: map[string]PropertyKind{"selector": propString, "filePath": propString},
+	},
+	NodeTypeWait: {
+		required: map[string]PropertyKind{"duration": propInteger},
+	},
+	NodeTypeWaitFor: {
+		optional: map[string]PropertyKind{
+			"selector":    propString,
+			"state":       propState,
+			"urlPattern":  propPattern,
+			"networkIdle": propInteger,
+			"script":      propString,
+			"interval":    propInteger,
+		},
+	},
+	NodeTypeConditional: {
+		required: map[string]PropertyKind{"conditionExpression": propExpression, "branches": propBranches},
+	},
+	NodeTypeQuestion: {
+		required: map[string]PropertyKind{"check": propCheck, "branches": propBranches},
+	},
+	NodeTypeSequence: {
+		required: map[string]PropertyKind{"sequence": propSequence},
+	},
+	NodeTypeForEach: {
+		required: map[string]PropertyKind{"dataSource": propString},
+		optional: map[string]PropertyKind{
+			"questionText": propString,
+			"columns":      propStringMap,
+			"sheet":        propString,
+			"answer":       propAnswer,
+		},
+	},
+	NodeTypeWhile: loopSchema,
+	NodeTypeUntil: loopSchema,
+	NodeTypeExtractText: {
+		required: map[string]PropertyKind{"selector": propString, "storeAs": propContextPath},
+		optional: map[string]PropertyKind{"pattern": propPattern},
+	},
+	NodeTypeExtractAttribute: {
+		required: map[string]PropertyKind{"selector": propString, "attribute": propString, "storeAs": propContextPath},
+		optional: map[string]PropertyKind{"pattern": propPattern},
+	},
+	NodeTypeExtractValue: {
+		required: map[string]PropertyKind{"selector": propString, "storeAs": propContextPath},
+		optional: map[string]PropertyKind{"pattern": propPattern},
+	},
+	NodeTypeExtractCount: {
+		required: map[string]PropertyKind{"selector": propString, "storeAs": propContextPath},
+	},
+	NodeTypeAssertVisible: {
+		required: map[string]PropertyKind{"selector": propString},
+	},
+	NodeTypeAssertNotVisible: {
+		required: map[string]PropertyKind{"selector": propString},
+	},
+	NodeTypeAssertText: {
+		required: map[string]PropertyKind{"selector": propString, "expected": propString},
+		optional: map[string]PropertyKind{"match": propMatch},
+	},
+	NodeTypeAssertURL: {
+		required: map[string]PropertyKind{"expected": propString},
+		optional: map[string]PropertyKind{"match": propMatch},
+	},
+	NodeTypeAssertTitle: {
+		required: map[string]PropertyKind{"expected": propString},
+		optional: map[string]PropertyKind{"match": propMatch},
+	},
+	NodeTypeEvaluate: {
+		required: map[string]PropertyKind{"script": propString},
+		optional: map[string]PropertyKind{"args": propArray, "frame": propString, "storeAs": propContextPath},
+	},
+	NodeTypeParallel: {
+		required: map[string]PropertyKind{"parallel": propParallel},
+		optional: map[string]PropertyKind{"join": propJoin, "maxConcurrency": propInteger, "isolate": propBoolean},
+	},
+	NodeTypeCall: {
+		required: map[string]PropertyKind{"workflow": propString},
+		optional: map[string]PropertyKind{"params": propObject, "outputs": propStringMap},
+	},
+}
+
+// loopSchema is the schema of while and until nodes.
+var loopSchema = nodeSchema{
+	required: map[string]PropertyKind{"maxIterations": propInteger},
+	optional: map[string]PropertyKind{
+		"conditionExpression": propExpression,
+		"selector":            propString,
+		"state":               propState,
+		"expected":            propString,
+		"match":               propMatch,
+		"urlPattern":          propPattern,
+		"script":              propString,
+		"delay":               propInteger,
+	},
+}
+
+var checkOperators = []string{
+	"equals", "notEquals", "greaterThan", "lessThan", "between", "in", "contains", "matches",
+	"exists", "notExists", "isEmpty", "isType",
+}
+
+// forbiddenNodeTypes are multi-action nodes that must be written as a sequence instead.
+var forbiddenNodeTypes = map[string]bool{
+	"fillForm":  true,
+	"loginForm": true,
+}
+
+var multiActionPattern = regexp.MustCompile(`[a-z]And[A-Z]`)
+
+// ValidateWorkflow checks a workflow JSON document against the node schema
+// and returns every violation found, or nil if the document is valid.
+func ValidateWorkflow(data []byte) ValidationErrors {
+	positions := newPositionIndex(data)
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		offset := int64(0)
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			offset = syntaxErr.Offset
+		}
+		line, column := positions.lineColumn(offset)
+		return ValidationErrors{{Line: line, Column: column, Message: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+	return validateDocument(data, positions)
+}
+
+// validateDocument checks a well-formed workflow JSON document. Positions locate errors in
+// the source the document was read from, which is not necessarily data itself.
+func validateDocument(data []byte, positions positionLookup) ValidationErrors {
+	v := &validator{positions: positions, ids: make(map[string]string)}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		v.addError("", "%v", err)
+		return v.errs
+	}
+
+	// Older documents are checked as they will run, after migrating them to the current schema.
+	if version, err := SchemaVersion(data); err == nil && version != currentSchemaVersion {
+		if version > currentSchemaVersion {
+			v.addError("/metadata/schemaVersion", "%v", &SchemaVersionError{Version: version})
+			return v.errs
+		}
+		migrated, err := upgradeWorkflow(data)
+		if err != nil {
+			v.addError("", "%v", err)
+			return v.errs
+		}
+		if _, ok := positions.(*positionIndex); ok {
+			v.positions = newPositionIndex(migrated)
+		}
+		doc = nil
+		_ = json.Unmarshal(migrated, &doc)
+	}
+
+	v.validateRoot(doc)
+	v.validateRefs()
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+type validator struct {
+	positions positionLookup
+	errs      ValidationErrors
+	ids       map[string]string
+	refs      []nodeRef
+}
+
+// nodeRef is a nextId/yesId/noId reference checked once every id is known.
+type nodeRef struct {
+	pointer string
+	id      string
+}
+
+func (v *validator) addError(pointer, format string, args ...interface{}) {
+	line, column := v.positions.lookup(pointer)
+	v.errs = append(v.errs, ValidationError{
+		Pointer: pointer,
+		Line:    line,
+		Column:  column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) validateRoot(doc interface{}) {
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		v.addError("", "workflow must be a JSON object")
+		return
+	}
+
+	for _, key := range sortedKeys(root) {
+		switch key {
+		case "graph":
+			if root[key] == nil {
+				v.addError("/graph", "graph must be a node object")
+				continue
+			}
+			v.validateNode("/graph", root[key])
+		case "metadata":
+			v.validateMetadata("/metadata", root[key])
+		default:
+			// Extension keys are ignored; YAML documents use them to hold anchors.
+			if strings.HasPrefix(key, "x-") {
+				continue
+			}
+			v.addError(pointerJoin("", key), "unknown property %q", key)
+		}
+	}
+
+	if _, ok := root["graph"]; !ok {
+		v.addError("", "missing required property \"graph\"")
+	}
+}
+
+func (v *validator) validateMetadata(pointer string, value interface{}) {
+	metadata, ok := value.(map[string]interface{})
+	if !ok {
+		v.addError(pointer, "metadata must be an object")
+		return
+	}
+	for _, key := range sortedKeys(metadata) {
+		switch key {
+		case "name", "version", "description":
+			if _, ok := metadata[key].(string); !ok {
+				v.addError(pointerJoin(pointer, key), "%s must be a string", key)
+			}
+		case "schemaVersion":
+			number, ok := metadata[key].(float64)
+			if !ok || number != float64(int64(number)) || number < 1 {
+				v.addError(pointerJoin(pointer, key), "schemaVersion must be a positive integer")
+			}
+		default:
+			v.addError(pointerJoin(pointer, key), "unknown property %q", key)
+		}
+	}
+}
+
+func (v *validator) validateNode(pointer string, value interface{}) {
+	node, ok := value.(map[string]interface{})
+	if !ok {
+		v.addError(pointer, "node must be an object")
+		return
+	}
+
+	rawType, exists := node["nodeType"]
+	if !exists {
+		v.addError(pointer, "missing required property \"nodeType\"")
+		return
+	}
+	nodeType, ok := rawType.(string)
+	if !ok {
+		v.addError(pointerJoin(pointer, "nodeType"), "nodeType must be a string")
+		return
+	}
+
+	errorsBefore := len(v.errs)
+	schema, known := nodeSchemas[nodeType]
+	custom, isCustom := customNodeType(nodeType)
+	if isCustom {
+		schema, known = custom.schema(), true
+	}
+	if !known {
+		if forbiddenNodeTypes[nodeType] || multiActionPattern.MatchString(nodeType) {
+			v.addError(pointerJoin(pointer, "nodeType"),
+				"nodeType %q performs multiple actions; use a sequence of single-action nodes", nodeType)
+		} else {
+			v.addError(pointerJoin(pointer, "nodeType"), "unknown nodeType %q", nodeType)
+		}
+		return
+	}
+
+	if id, ok := node["id"].(string); ok && id != "" {
+		if first, exists := v.ids[id]; exists {
+			v.addError(pointerJoin(pointer, "id"), "duplicate id %q, first declared at %s", id, first)
+		} else {
+			v.ids[id] = pointer
+		}
+	}
+
+	for _, key := range sortedKeys(node) {
+		kind, ok := commonProperties[key]
+		if !ok {
+			kind, ok = schema.required[key]
+		}
+		if !ok {
+			kind, ok = schema.optional[key]
+		}
+		if !ok {
+			v.addError(pointerJoin(pointer, key), "unknown property %q for nodeType %q", key, nodeType)
+			continue
+		}
+		v.validateProperty(pointerJoin(pointer, key), key, kind, node[key])
+	}
+
+	for _, key := range sortedKindKeys(schema.required) {
+		if _, ok := node[key]; !ok {
+			v.addError(pointer, "nodeType %q requires property %q", nodeType, key)
+		}
+	}
+
+	switch nodeType {
+	case NodeTypeWaitFor:
+		v.validateWaitFor(pointer, node)
+	case NodeTypeWhile, NodeTypeUntil:
+		v.validateLoop(pointer, nodeType, node)
+	}
+	if isCustom && custom.Validate != nil && !v.nodeErrors(pointer, errorsBefore) {
+		if err := custom.Validate(node); err != nil {
+			v.addError(pointer, "%s: %v", nodeType, err)
+		}
+	}
+
+	if next, ok := node["next"]; ok && next != nil && !isLoop(nodeType) {
+		if _, hasJump := node["nextId"]; hasJump {
+			v.addError(pointerJoin(pointer, "nextId"), "next and nextId cannot both be set")
+		}
+	}
+}
+
+// nodeErrors reports whether errors added since from concern the node at pointer itself
+// rather than the nodes it links to.
+func (v *validator) nodeErrors(pointer string, from int) bool {
+	for _, err := range v.errs[from:] {
+		if err.Pointer == pointer {
+			return true
+		}
+		if rest, ok := strings.CutPrefix(err.Pointer, pointer+"/"); ok && !childKeys[strings.SplitN(rest, "/", 2)[0]] {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) validateRefs() {
+	for _, ref := range v.refs {
+		if _, ok := v.ids[ref.id]; !ok {
+			v.addError(ref.pointer, "reference to unknown node id %q", ref.id)
+		}
+	}
+}
+
+func (v *validator) validateProperty(pointer, key string, kind PropertyKind, value interface{}) {
+	switch kind {
+	case propString:
+		text, ok := value.(string)
+		if !ok {
+			v.addError(pointer, "%s must be a string", key)
+			return
+		}
+		if err := templateErrors(text); err != nil {
+			v.addError(pointer, "invalid template: %v", err)
+		}
+	case propExpression:
+		source, ok := value.(string)
+		if !ok {
+			v.addError(pointer, "%s must be a string", key)
+			return
+		}
+		if _, err := expression.Parse(source); err != nil {
+			v.addError(pointer, "invalid expression %q: %v", source, err)
+		}
+	case propContextPath:
+		path, ok := value.(string)
+		if !ok {
+			v.addError(pointer, "%s must be a string", key)
+			return
+		}
+		segments, err := splitPath(path)
+		switch {
+		case err != nil:
+			v.addError(pointer, "invalid path: %v", err)
+		case len(segments) < 2 || segments[0].isIndex:
+			v.addError(pointer, "%s must name a scope and a key, e.g. vars.orderId", key)
+		case segments[0].value == "iterator":
+			v.addError(pointer, "%s cannot write to the read-only iterator scope", key)
+		}
+	case propPattern:
+		pattern, ok := value.(string)
+		if !ok {
+			v.addError(pointer, "%s must be a string", key)
+			return
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			v.addError(pointer, "invalid pattern: %v", err)
+		}
+	case propMatch:
+		match, ok := value.(string)
+		if !ok || !containsString(matchModes, match) {
+			v.addError(pointer, "%s must be one of %s", key, strings.Join(matchModes, ", "))
+		}
+	case propState:
+		state, ok := value.(string)
+		if !ok || !containsString(waitStates, state) {
+			v.addError(pointer, "%s must be one of %s", key, strings.Join(waitStates, ", "))
+		}
+	case propAnswer:
+		answer, ok := value.(string)
+		if !ok || !containsString(answerPolicies, answer) {
+			v.addError(pointer, "%s must be one of %s", key, strings.Join(answerPolicies, ", "))
+		}
+	case propJoin:
+		join, ok := value.(string)
+		if !ok || !containsString(joinPolicies, join) {
+			v.addError(pointer, "%s must be one of %s", key, strings.Join(joinPolicies, ", "))
+		}
+	case propBoolean:
+		if _, ok := value.(bool); !ok {
+			v.addError(pointer, "%s must be a boolean", key)
+		}
+	case propParallel:
+		branches, ok := value.(map[string]interface{})
+		if !ok || len(branches) == 0 {
+			v.addError(pointer, "parallel must be an object of named branches")
+			return
+		}
+		for _, name := range sortedKeys(branches) {
+			if !branchNamePattern.MatchString(name) {
+				v.addError(pointerJoin(pointer, name), "branch name %q must be letters, digits and underscores", name)
+			}
+			v.validateNode(pointerJoin(pointer, name), branches[name])
+		}
+	case propObject:
+		if _, ok := value.(map[string]interface{}); !ok {
+			v.addError(pointer, "%s must be an object", key)
+		}
+	case propArray:
+		if _, ok := value.([]interface{}); !ok {
+			v.addError(pointer, "%s must be an array", key)
+		}
+	case propStringMap:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			v.addError(pointer, "%s must be an object", key)
+			return
+		}
+		for _, name := range sortedKeys(obj) {
+			if _, ok := obj[name].(string); !ok {
+				v.addError(pointerJoin(pointer, name), "%s values must be strings", key)
+			}
+		}
+	case propNodeRef:
+		id, ok := value.(string)
+		if !ok || id == "" {
+			v.addError(pointer, "%s must be a non-empty node id", key)
+			return
+		}
+		v.refs = append(v.refs, nodeRef{pointer: pointer, id: id})
+	case propInteger:
+		number, ok := value.(float64)
+		if !ok || number != float64(int64(number)) || number < 0 {
+			v.addError(pointer, "%s must be a non-negative integer", key)
+		}
+	case propNode:
+		if value != nil {
+			v.validateNode(pointer, value)
+		}
+	case propRetry:
+		v.validateRetry(pointer, value)
+	case propBranches:
+		v.validateBranches(pointer, value)
+	case propCheck:
+		v.validateCheck(pointer, value)
+	case propSequence:
+		items, ok := value.([]interface{})
+		if !ok {
+			v.addError(pointer, "sequence must be an array of nodes")
+			return
+		}
+		if len(items) == 0 {
+			v.addError(pointer, "sequence must contain at least one node")
+		}
+		for i, item := range items {
+			v.validateNode(fmt.Sprintf("%s/%d", pointer, i), item)
+		}
+	}
+}
+
+// validateWaitFor requires exactly one condition, and state only together with a selector.
+func (v *validator) validateWaitFor(pointer string, node map[string]interface{}) {
+	var conditions []string
+	for _, key := range waitConditions {
+		if _, ok := node[key]; ok {
+			conditions = append(conditions, key)
+		}
+	}
+	switch len(conditions) {
+	case 0:
+		v.addError(pointer, "waitFor needs one of %s", strings.Join(waitConditions, ", "))
+	case 1:
+	default:
+		v.addError(pointer, "waitFor takes only one condition, found %s", strings.Join(conditions, ", "))
+	}
+	if _, ok := node["state"]; ok {
+		if _, ok := node["selector"]; !ok {
+			v.addError(pointerJoin(pointer, "state"), "state requires a selector")
+		}
+	}
+}
+
+// validateLoop requires exactly one condition, state or expected only together with a
+// selector, and at least one iteration.
+func (v *validator) validateLoop(pointer, nodeType string, node map[string]interface{}) {
+	var conditions []string
+	for _, key := range loopConditions {
+		if _, ok := node[key]; ok {
+			conditions = append(conditions, key)
+		}
+	}
+	switch len(conditions) {
+	case 0:
+		v.addError(pointer, "%s needs one of %s", nodeType, strings.Join(loopConditions, ", "))
+	case 1:
+	default:
+		v.addError(pointer, "%s takes only one condition, found %s", nodeType, strings.Join(conditions, ", "))
+	}
+	_, hasSelector := node["selector"]
+	for _, key := range []string{"state", "expected"} {
+		if _, ok := node[key]; ok && !hasSelector {
+			v.addError(pointerJoin(pointer, key), "%s requires a selector", key)
+		}
+	}
+	if _, ok := node["state"]; ok {
+		if _, ok := node["expected"]; ok {
+			v.addError(pointerJoin(pointer, "expected"), "state and expected cannot both be set")
+		}
+	}
+	if _, ok := node["match"]; ok {
+		if _, ok := node["expected"]; !ok {
+			v.addError(pointerJoin(pointer, "match"), "match requires expected")
+		}
+	}
+	if limit, ok := node["maxIterations"].(float64); ok && limit == 0 {
+		v.addError(pointerJoin(pointer, "maxIterations"), "maxIterations must be at least 1")
+	}
+}
+
+func (v *validator) validateBranches(pointer string, value interface{}) {
+	branches, ok := value.(map[string]interface{})
+	if !ok {
+		v.addError(pointer, "branches must be an object")
+		return
+	}
+	for _, key := range sortedKeys(branches) {
+		switch key {
+		case "yes", "no":
+			if branches[key] != nil {
+				v.validateNode(pointerJoin(pointer, key), branches[key])
+			}
+			if _, hasJump := branches[key+"Id"]; hasJump && branches[key] != nil {
+				v.addError(pointerJoin(pointer, key+"Id"), "%s and %sId cannot both be set", key, key)
+			}
+		case "yesId", "noId":
+			v.validateProperty(pointerJoin(pointer, key), key, propNodeRef, branches[key])
+		default:
+			v.addError(pointerJoin(pointer, key), "unknown branch %q", key)
+		}
+	}
+}
+
+func (v *validator) validateRetry(pointer string, value interface{}) {
+	retry, ok := value.(map[string]interface{})
+	if !ok {
+		v.addError(pointer, "retry must be an object")
+		return
+	}
+	for _, key := range sortedKeys(retry) {
+		switch key {
+		case "count", "backoff":
+			v.validateProperty(pointerJoin(pointer, key), key, propInteger, retry[key])
+		case "factor":
+			if factor, ok := retry[key].(float64); !ok || factor < 1 {
+				v.addError(pointerJoin(pointer, key), "factor must be a number of at least 1")
+			}
+		case "on":
+			kinds, ok := retry[key].([]interface{})
+			if !ok {
+				v.addError(pointerJoin(pointer, key), "on must be an array of error kinds")
+				continue
+			}
+			for i, kind := range kinds {
+				if name, _ := kind.(string); !containsString(errorKinds, name) {
+					v.addError(fmt.Sprintf("%s/%d", pointerJoin(pointer, key), i),
+						"error kind must be one of %s", strings.Join(errorKinds, ", "))
+				}
+			}
+		default:
+			v.addError(pointerJoin(pointer, key), "unknown property %q", key)
+		}
+	}
+	if _, ok := retry["count"]; !ok {
+		v.addError(pointer, "retry requires property \"count\"")
+	}
+}
+
+func (v *validator) validateCheck(pointer string, value interface{}) {
+	check, ok := value.(map[string]interface{})
+	if !ok {
+		v.addError(pointer, "check must be an object")
+		return
+	}
+	operator, _ := check["operator"].(string)
+	for _, key := range sortedKeys(check) {
+		switch key {
+		case "dataPath":
+			path, ok := check[key].(string)
+			if !ok {
+				v.addError(pointerJoin(pointer, key), "dataPath must be a string")
+			} else if isJSONPath(path) {
+				if _, err := parseJSONPath(path); err != nil {
+					v.addError(pointerJoin(pointer, key), "%v", err)
+				}
+			}
+		case "operator":
+			if !containsString(checkOperators, operator) {
+				v.addError(pointerJoin(pointer, key), "operator must be one of %s", strings.Join(checkOperators, ", "))
+			}
+		case "expectedValue":
+			v.validateExpectedValue(pointerJoin(pointer, key), operator, check[key])
+		default:
+			v.addError(pointerJoin(pointer, key), "unknown property %q", key)
+		}
+	}
+	required := []string{"dataPath", "operator", "expectedValue"}
+	if unaryCheckOperators[operator] {
+		required = required[:2]
+	}
+	for _, key := range required {
+		if _, ok := check[key]; !ok {
+			v.addError(pointer, "check requires property %q", key)
+		}
+	}
+}
+
+// validateExpectedValue checks that a check's expectedValue has the shape its operator needs.
+func (v *validator) validateExpectedValue(pointer, operator string, value interface{}) {
+	switch {
+	case unaryCheckOperators[operator]:
+		if value != nil {
+			v.addError(pointer, "operator %s takes no expectedValue", operator)
+		}
+	case operator == "between":
+		if bounds, ok := value.([]interface{}); !ok || len(bounds) != 2 {
+			v.addError(pointer, "expectedValue of between must be an array [min, max]")
+		}
+	case operator == "in":
+		if _, ok := value.([]interface{}); !ok {
+			v.addError(pointer, "expectedValue of in must be an array")
+		}
+	case operator == "matches":
+		pattern, ok := value.(string)
+		if !ok {
+			v.addError(pointer, "expectedValue of matches must be a regular expression string")
+		} else if _, err := regexp.Compile(pattern); err != nil {
+			v.addError(pointer, "invalid regular expression: %v", err)
+		}
+	case operator == "isType":
+		if name, _ := value.(string); !containsString(checkTypes, name) {
+			v.addError(pointer, "expectedValue of isType must be one of %s", strings.Join(checkTypes, ", "))
+		}
+	}
+}
+
+// positionLookup finds the line and column where the value at a JSON pointer is written.
+type positionLookup interface {
+	lookup(pointer string) (int, int)
+}
+
+// positionIndex maps JSON pointers to byte offsets of their values in the source document.
+type positionIndex struct {
+	data    []byte
+	offsets map[string]int64
+}
+
+func newPositionIndex(data []byte) *positionIndex {
+	index := &positionIndex{data: data, offsets: make(map[string]int64)}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	index.walk(dec, "")
+	return index
+}
+
+// walk records the start offset of the value at pointer and of everything nested in it.
+func (p *positionIndex) walk(dec *json.Decoder, pointer string) bool {
+	start := p.skipSeparators(dec.InputOffset())
+	token, err := dec.Token()
+	if err != nil {
+		return false
+	}
+	p.offsets[pointer] = start
+
+	switch token {
+	case json.Delim('{'):
+		for dec.More() {
+			keyStart := p.skipSeparators(dec.InputOffset())
+			keyToken, err := dec.Token()
+			if err != nil {
+				return false
+			}
+			key, _ := keyToken.(string)
+			child := pointerJoin(pointer, key)
+			if !p.walk(dec, child) {
+				return false
+			}
+			p.offsets[child+"#key"] = keyStart
+		}
+		_, err = dec.Token()
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if !p.walk(dec, fmt.Sprintf("%s/%d", pointer, i)) {
+				return false
+			}
+		}
+		_, err = dec.Token()
+	}
+	return err == nil || err == io.EOF
+}
+
+func (p *positionIndex) skipSeparators(offset int64) int64 {
+	for offset < int64(len(p.data)) {
+		switch p.data[offset] {
+		case ' ', '\t', '\r', '\n', ':', ',':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// lookup returns the line and column of pointer, falling back to its closest known parent.
+func (p *positionIndex) lookup(pointer string) (int, int) {
+	for {
+		if offset, ok := p.offsets[pointer+"#key"]; ok {
+			return p.lineColumn(offset)
+		}
+		if offset, ok := p.offsets[pointer]; ok {
+			return p.lineColumn(offset)
+		}
+		if pointer == "" {
+			return 1, 1
+		}
+		pointer = pointer[:strings.LastIndex(pointer, "/")]
+	}
+}
+
+func (p *positionIndex) lineColumn(offset int64) (int, int) {
+	line, column := 1, 1
+	for i := int64(0); i < offset && i < int64(len(p.data)); i++ {
+		if p.data[i] == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
+
+// pointerJoin appends an escaped RFC 6901 reference token to a JSON pointer.
+func pointerJoin(pointer, key string) string {
+	key = strings.ReplaceAll(key, "~", "~0")
+	key = strings.ReplaceAll(key, "/", "~1")
+	return pointer + "/" + key
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedKindKeys(m map[string]PropertyKind) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package traverser
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"rpa-dfs-engine/internal/logger"
+)
+
+// Defaults for waitFor nodes that set no timeout or interval.
+const (
+	DefaultWaitTimeout  = 30 * time.Second
+	DefaultWaitInterval = 250 * time.Millisecond
+)
+
+// Element states a waitFor node can wait for.
+const (
+	StateVisible = "visible"
+	StateHidden  = "hidden"
+	StateEnabled = "enabled"
+)
+
+var waitStates = []string{StateVisible, StateHidden, StateEnabled}
+
+// waitConditions are the properties of a waitFor node of which exactly one is set.
+var waitConditions = []string{"selector", "urlPattern", "networkIdle", "script"}
+
+// executeWaitFor polls the node's condition every interval until it holds or the
+// node's timeout runs out, which fails the node with a timeout error.
+func (e *Engine) executeWaitFor(node *Node) error {
+	description, check, err := e.waitCondition(node)
+	if err != nil {
+		return err
+	}
+
+	timeout := DefaultWaitTimeout
+	if node.Timeout > 0 {
+		timeout = time.Duration(node.Timeout) * time.Millisecond
+	}
+	interval := DefaultWaitInterval
+	if node.Interval > 0 {
+		interval = time.Duration(node.Interval) * time.Millisecond
+	}
+
+	if e.plan != nil {
+		e.planNote("wait until %s (up to %v)", description, timeout)
+		return nil
+	}
+
+	logger.LogDebug("Waiting until %s", description)
+	started := time.Now()
+	deadline := started.Add(timeout)
+	for {
+		ok, err := check()
+		if err != nil && ErrorKind(err) != ErrorKindNotFound {
+			return &ActionError{Action: NodeTypeWaitFor, Target: description, Err: err}
+		}
+		if ok {
+			logger.LogDebug("%s after %v", description, time.Since(started).Round(time.Millisecond))
+			return nil
+		}
+		if e.runCtx.Err() != nil {
+			return errBranchCancelled
+		}
+		if !time.Now().Add(interval).Before(deadline) {
+			return &ActionError{
+				Action: NodeTypeWaitFor,
+				Target: description,
+				Err:    fmt.Errorf("not reached within %v: %w", timeout, context.DeadlineExceeded),
+			}
+		}
+		time.Sleep(interval)
+	}
+}
+
+// waitCondition describes the node's condition and returns a function that checks it once.
+func (e *Engine) waitCondition(node *Node) (string, func() (bool, error), error) {
+	switch {
+	case node.Selector != "":
+		selector, err := e.resolveString(node.Selector)
+		if err != nil {
+			return "", nil, err
+		}
+		state := node.State
+		if state == "" {
+			state = StateVisible
+		}
+		description := fmt.Sprintf("%s is %s", selector, state)
+		switch state {
+		case StateVisible:
+			return description, func() (bool, error) { return e.browser.Visible(selector) }, nil
+		case StateHidden:
+			return description, func() (bool, error) {
+				visible, err := e.browser.Visible(selector)
+				return !visible, err
+			}, nil
+		case StateEnabled:
+			return description, func() (bool, error) { return e.browser.Enabled(selector) }, nil
+		}
+		return "", nil, fmt.Errorf("unknown state %q", node.State)
+
+	case node.URLPattern != "":
+		pattern, err := e.resolveString(node.URLPattern)
+		if err != nil {
+			return "", nil, err
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid urlPattern %q: %w", pattern, err)
+		}
+		return fmt.Sprintf("url matches /%s/", pattern), func() (bool, error) {
+			url, err := e.browser.CurrentURL()
+			return re.MatchString(url), err
+		}, nil
+
+	case node.NetworkIdle > 0:
+		quiet := time.Duration(node.NetworkIdle) * time.Millisecond
+		return fmt.Sprintf("network idle for %v", quiet), func() (bool, error) {
+			return e.browser.NetworkIdle(quiet)
+		}, nil
+
+	case node.Script != "":
+		script, args, err := e.scriptArgs(node.Script)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("script %q is true", node.Script), func() (bool, error) {
+			return e.browser.Truthy(script, args)
+		}, nil
+	}
+	return "", nil, fmt.Errorf("waitFor needs one of %s", strings.Join(waitConditions, ", "))
+}
+
+// scriptArgs replaces every {{...}} reference in a script expression with args[i] and
+// resolves the values, which the browser passes as arguments. Context values never become
+// part of the source, so a quote or parenthesis in a CSV row cannot break or change the
+// script. A reference is a value, not text: write document.title === {{user.title}}.
+func (e *Engine) scriptArgs(script string) (string, []interface{}, error) {
+	var args []interface{}
+	source, err := replaceTemplates(script, func(match, _ string) (string, error) {
+		value, err := e.resolveArg(match)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, value)
+		return fmt.Sprintf("args[%d]", len(args)-1), nil
+	})
+	return source, args, err
+}
@@ -0,0 +1,339 @@
+package traverser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Node types supported by the engine. One node performs exactly one action.
+const (
+	NodeTypeMoveToPage  = "moveToPage"
+	NodeTypeFillField   = "fillField"
+	NodeTypeClickButton = "clickButton"
+	NodeTypeSendFile    = "sendFile"
+	NodeTypeWait        = "wait"
+	NodeTypeWaitFor     = "waitFor"
+	NodeTypeConditional = "conditional"
+	NodeTypeQuestion    = "question"
+	NodeTypeSequence    = "sequence"
+	NodeTypeForEach     = "forEach"
+	NodeTypeWhile       = "while"
+	NodeTypeUntil       = "until"
+	NodeTypeCall        = "callWorkflow"
+	NodeTypeParallel    = "parallel"
+	NodeTypeEvaluate    = "evaluate"
+
+	NodeTypeExtractText      = "extractText"
+	NodeTypeExtractAttribute = "extractAttribute"
+	NodeTypeExtractValue     = "extractValue"
+	NodeTypeExtractCount     = "extractCount"
+
+	NodeTypeAssertVisible    = "assertVisible"
+	NodeTypeAssertNotVisible = "assertNotVisible"
+	NodeTypeAssertText       = "assertText"
+	NodeTypeAssertURL        = "assertURL"
+	NodeTypeAssertTitle      = "assertTitle"
+)
+
+// Workflow is a parsed workflow document: the root node of the graph and its metadata.
+type Workflow struct {
+	Graph    *Node            `json:"graph"`
+	Metadata WorkflowMetadata `json:"metadata"`
+
+	nodes    map[string]*Node
+	pointers map[*Node]string
+	path     string
+}
+
+// WorkflowMetadata describes a workflow document.
+type WorkflowMetadata struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+
+	// SchemaVersion is the workflow schema the document was written for. Older documents are
+	// migrated when loaded, so a parsed workflow always reports CurrentSchemaVersion.
+	SchemaVersion int `json:"schemaVersion,omitempty"`
+}
+
+// Node is a single step of a workflow.
+type Node struct {
+	NodeType string `json:"nodeType"`
+	ID       string `json:"id,omitempty"`
+	Next     *Node  `json:"next,omitempty"`
+
+	// Graph jumps: NextID continues at the node with that id instead of Next.
+	// MaxVisits limits how often the node may be entered by a jump.
+	NextID    string `json:"nextId,omitempty"`
+	MaxVisits int    `json:"maxVisits,omitempty"`
+
+	// Failure policies: Retry re-runs the node, Timeout (ms) bounds each browser
+	// action, and OnError runs instead of failing once retries are exhausted.
+	Retry   *RetryPolicy `json:"retry,omitempty"`
+	Timeout int          `json:"timeout,omitempty"`
+	OnError *Node        `json:"onError,omitempty"`
+
+	// Navigation
+	URL string `json:"url,omitempty"`
+
+	// Single field
+	Selector string `json:"selector,omitempty"`
+	Value    string `json:"value,omitempty"`
+	FilePath string `json:"filePath,omitempty"`
+
+	// Conditional
+	ConditionExpression string    `json:"conditionExpression,omitempty"`
+	Branches            *Branches `json:"branches,omitempty"`
+
+	// Question
+	Check *DataCheck `json:"check,omitempty"`
+
+	// Sequence
+	Sequence []Node `json:"sequence,omitempty"`
+
+	// ForEach: DataSource is a context path to an array or a .csv, .jsonl or .xlsx file.
+	// Columns renames file columns, Sheet selects a worksheet and Answer ("ask", "always"
+	// or "never") decides whether QuestionText is asked.
+	DataSource   string            `json:"dataSource,omitempty"`
+	QuestionText string            `json:"questionText,omitempty"`
+	Columns      map[string]string `json:"columns,omitempty"`
+	Sheet        string            `json:"sheet,omitempty"`
+	Answer       string            `json:"answer,omitempty"`
+
+	// Wait
+	Duration int `json:"duration,omitempty"`
+
+	// While/Until: the loop body (Next) runs while, or until, a condition holds: a
+	// ConditionExpression, or a page condition written as for waitFor, or Selector with
+	// Expected and Match for its text. MaxIterations is required; Delay (ms) follows each pass.
+	MaxIterations int `json:"maxIterations,omitempty"`
+	Delay         int `json:"delay,omitempty"`
+
+	// Parallel: named branches run in their own tabs (Isolate gives each its own
+	// cookies). Join is "all", "any" or "first-success"; MaxConcurrency limits how
+	// many branches run at once.
+	Parallel       map[string]*Node `json:"parallel,omitempty"`
+	Join           string           `json:"join,omitempty"`
+	MaxConcurrency int              `json:"maxConcurrency,omitempty"`
+	Isolate        bool             `json:"isolate,omitempty"`
+
+	// WaitFor: exactly one condition is set. State applies to Selector ("visible",
+	// "hidden" or "enabled"); NetworkIdle is the quiet period in ms. Interval is the
+	// polling interval in ms.
+	State       string `json:"state,omitempty"`
+	URLPattern  string `json:"urlPattern,omitempty"`
+	NetworkIdle int    `json:"networkIdle,omitempty"`
+	Script      string `json:"script,omitempty"`
+	Interval    int    `json:"interval,omitempty"`
+
+	// Evaluate: Script is a JavaScript function called with Args in the page, or in the
+	// iframe matched by Frame. Its result is stored at StoreAs.
+	Args  []interface{} `json:"args,omitempty"`
+	Frame string        `json:"frame,omitempty"`
+
+	// CallWorkflow: Workflow is a catalog name or a path relative to the calling file.
+	// Params become the callee's user scope; Outputs map caller paths to callee paths.
+	Workflow string                 `json:"workflow,omitempty"`
+	Params   map[string]interface{} `json:"params,omitempty"`
+	Outputs  map[string]string      `json:"outputs,omitempty"`
+
+	// Extraction: the result is stored at the context path StoreAs (e.g. "vars.orderId").
+	// Pattern optionally keeps only the first capture group of a regular expression.
+	StoreAs   string `json:"storeAs,omitempty"`
+	Attribute string `json:"attribute,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+
+	// Assertion: Expected is compared with the page according to Match
+	// ("equals", "contains" or "matches" for a regular expression).
+	Expected string `json:"expected,omitempty"`
+	Match    string `json:"match,omitempty"`
+
+	// Properties holds the settings of node types added with RegisterNodeType, as decoded
+	// from JSON. Built-in node types leave it nil.
+	Properties map[string]interface{} `json:"-"`
+}
+
+// Branches holds the yes/no paths of conditional and question nodes.
+type Branches struct {
+	Yes   *Node  `json:"yes,omitempty"`
+	No    *Node  `json:"no,omitempty"`
+	YesID string `json:"yesId,omitempty"`
+	NoID  string `json:"noId,omitempty"`
+}
+
+// DataCheck is the comparison performed by a question node.
+type DataCheck struct {
+	DataPath      string      `json:"dataPath"`
+	Operator      string      `json:"operator"`
+	ExpectedValue interface{} `json:"expectedValue"`
+}
+
+// continuation returns the node executed after this node has finished when no jump is set.
+// For loops the next pointer is the loop body, so only nextId continues after the loop.
+func (n *Node) continuation() *Node {
+	if isLoop(n.NodeType) {
+		return nil
+	}
+	return n.Next
+}
+
+// isLoop reports whether next is the body of the node type rather than what follows it.
+func isLoop(nodeType string) bool {
+	return nodeType == NodeTypeForEach || nodeType == NodeTypeWhile || nodeType == NodeTypeUntil
+}
+
+// isContainer reports whether the node runs other nodes rather than performing a page action.
+// Parallel nodes count as actions: their branches run in other tabs, and a resumed run
+// replays them as a whole.
+func (n *Node) isContainer() bool {
+	switch n.NodeType {
+	case NodeTypeConditional, NodeTypeQuestion, NodeTypeSequence, NodeTypeForEach, NodeTypeWhile, NodeTypeUntil, NodeTypeCall:
+		return true
+	}
+	return false
+}
+
+// Label identifies the node in logs and error paths: its id, or its nodeType when it has none.
+func (n *Node) Label() string {
+	if n.ID != "" {
+		return n.ID
+	}
+	return n.NodeType
+}
+
+// NodeByID returns the node declared with the given id anywhere in the graph.
+func (w *Workflow) NodeByID(id string) (*Node, bool) {
+	w.index()
+	node, ok := w.nodes[id]
+	return node, ok
+}
+
+// index builds the id and pointer lookups on first use.
+func (w *Workflow) index() {
+	if w.nodes == nil {
+		w.nodes = make(map[string]*Node)
+		walkNodes(w.Graph, func(node *Node) {
+			if node.ID != "" {
+				if _, exists := w.nodes[node.ID]; !exists {
+					w.nodes[node.ID] = node
+				}
+			}
+		})
+	}
+	if w.pointers == nil {
+		w.pointers = make(map[*Node]string)
+		walkPointers(w.Graph, "/graph", w.pointers)
+	}
+}
+
+// childKeys are the node properties holding other nodes rather than the node's own settings.
+var childKeys = map[string]bool{"next": true, "onError": true, "branches": true, "sequence": true, "parallel": true}
+
+// walkNodes calls fn for every node reachable through next, branches, sequences,
+// parallel branches and onError.
+func walkNodes(node *Node, fn func(node *Node)) {
+	for ; node != nil; node = node.Next {
+		fn(node)
+		walkNodes(node.OnError, fn)
+		if node.Branches != nil {
+			walkNodes(node.Branches.Yes, fn)
+			walkNodes(node.Branches.No, fn)
+		}
+		for i := range node.Sequence {
+			walkNodes(&node.Sequence[i], fn)
+		}
+		for _, name := range sortedNodeKeys(node.Parallel) {
+			walkNodes(node.Parallel[name], fn)
+		}
+	}
+}
+
+// Pointer returns the JSON pointer of node in the workflow document, e.g. "/graph/sequence/1".
+func (w *Workflow) Pointer(node *Node) string {
+	w.index()
+	return w.pointers[node]
+}
+
+func walkPointers(node *Node, pointer string, pointers map[*Node]string) {
+	for ; node != nil; node, pointer = node.Next, pointer+"/next" {
+		pointers[node] = pointer
+		walkPointers(node.OnError, pointer+"/onError", pointers)
+		if node.Branches != nil {
+			walkPointers(node.Branches.Yes, pointer+"/branches/yes", pointers)
+			walkPointers(node.Branches.No, pointer+"/branches/no", pointers)
+		}
+		for i := range node.Sequence {
+			walkPointers(&node.Sequence[i], fmt.Sprintf("%s/sequence/%d", pointer, i), pointers)
+		}
+		for _, name := range sortedNodeKeys(node.Parallel) {
+			walkPointers(node.Parallel[name], pointerJoin(pointer+"/parallel", name), pointers)
+		}
+	}
+}
+
+// Name returns the metadata name, falling back to the file name.
+func (w *Workflow) Name() string {
+	if w.Metadata.Name != "" {
+		return w.Metadata.Name
+	}
+	if w.path != "" {
+		return filepath.Base(w.path)
+	}
+	return "workflow"
+}
+
+// Path returns the file the workflow was loaded from, if any.
+func (w *Workflow) Path() string {
+	return w.path
+}
+
+// LoadWorkflow reads and parses a workflow file, JSON or YAML depending on its extension.
+func LoadWorkflow(path string) (*Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading workflow file: %w", err)
+	}
+	parse := ParseWorkflow
+	if IsYAML(path) {
+		parse = ParseWorkflowYAML
+	}
+	workflow, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	workflow.path = path
+	return workflow, nil
+}
+
+// ParseWorkflow validates and parses a workflow JSON document, migrating it to the current
+// schema first. Schema violations are returned as ValidationErrors; documents declaring a
+// newer schema are refused with a SchemaVersionError.
+func ParseWorkflow(data []byte) (*Workflow, error) {
+	return parseWorkflow(data, func() ValidationErrors { return ValidateWorkflow(data) })
+}
+
+// parseWorkflow parses a workflow JSON document after validate has accepted it.
+func parseWorkflow(data []byte, validate func() ValidationErrors) (*Workflow, error) {
+	if version, err := SchemaVersion(data); err == nil && version > currentSchemaVersion {
+		return nil, &SchemaVersionError{Version: version}
+	}
+	if errs := validate(); errs != nil {
+		return nil, errs
+	}
+	data, err := upgradeWorkflow(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var workflow Workflow
+	if err := json.Unmarshal(data, &workflow); err != nil {
+		return nil, fmt.Errorf("error parsing workflow: %w", err)
+	}
+	if workflow.Graph == nil {
+		return nil, fmt.Errorf("workflow has no graph")
+	}
+	workflow.Metadata.SchemaVersion = currentSchemaVersion
+	return &workflow, nil
+}
@@ -0,0 +1,349 @@
+package traverser
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// xlsxSource streams the rows of one worksheet. Only what forEach needs is read:
+// the sheet list, shared strings, the number format of each cell style and cell
+// values. Formulas are ignored; numbers arrive as their stored text and cells with
+// a date or time format as "2006-01-02", "2006-01-02 15:04:05" or "15:04:05".
+type xlsxSource struct {
+	archive *zip.ReadCloser
+	sheet   io.ReadCloser
+	decoder *xml.Decoder
+	strings []string
+	header  []string
+	// dates holds the layout for each cell style with a date or time format.
+	dates map[int]string
+	epoch time.Time
+}
+
+type xlsxWorkbook struct {
+	Properties struct {
+		Date1904 bool `xml:"date1904,attr"`
+	} `xml:"workbookPr"`
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedString struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+type xlsxStyles struct {
+	NumFmts []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+type xlsxRow struct {
+	Cells []struct {
+		Ref    string `xml:"r,attr"`
+		Type   string `xml:"t,attr"`
+		Style  int    `xml:"s,attr"`
+		Value  string `xml:"v"`
+		Inline struct {
+			T string `xml:"t"`
+		} `xml:"is"`
+	} `xml:"c"`
+}
+
+func openXLSX(file, sheetName string, columns map[string]string) (*xlsxSource, error) {
+	archive, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, err
+	}
+	source := &xlsxSource{archive: archive}
+	if err := source.open(sheetName); err != nil {
+		source.Close()
+		return nil, err
+	}
+
+	header, err := source.row()
+	if errors.Is(err, io.EOF) {
+		source.Close()
+		return nil, fmt.Errorf("missing header row")
+	}
+	if err != nil {
+		source.Close()
+		return nil, err
+	}
+	source.header = mapColumns(header, columns)
+	return source, nil
+}
+
+// open locates the worksheet, loads the shared strings and date styles and starts
+// decoding the sheet.
+func (s *xlsxSource) open(sheetName string) error {
+	var workbook xlsxWorkbook
+	if err := s.decodePart("xl/workbook.xml", &workbook); err != nil {
+		return err
+	}
+	var rels xlsxRelationships
+	if err := s.decodePart("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return err
+	}
+
+	rid := ""
+	for _, sheet := range workbook.Sheets {
+		if sheetName == "" || sheet.Name == sheetName {
+			rid = sheet.RID
+			break
+		}
+	}
+	if rid == "" {
+		return fmt.Errorf("sheet %q not found", sheetName)
+	}
+	s.epoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if workbook.Properties.Date1904 {
+		s.epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	target := ""
+	for _, rel := range rels.Relationships {
+		if rel.ID == rid {
+			target = rel.Target
+		}
+	}
+	if strings.HasPrefix(target, "/") {
+		target = strings.TrimPrefix(target, "/")
+	} else {
+		target = path.Join("xl", target)
+	}
+
+	var shared struct {
+		Items []xlsxSharedString `xml:"si"`
+	}
+	if err := s.decodePart("xl/sharedStrings.xml", &shared); err != nil && !errors.Is(err, errPartMissing) {
+		return err
+	}
+	for _, item := range shared.Items {
+		text := item.T
+		for _, run := range item.Runs {
+			text += run.T
+		}
+		s.strings = append(s.strings, text)
+	}
+
+	var styles xlsxStyles
+	if err := s.decodePart("xl/styles.xml", &styles); err != nil && !errors.Is(err, errPartMissing) {
+		return err
+	}
+	s.dates = dateStyles(styles)
+
+	sheet, err := s.openPart(target)
+	if err != nil {
+		return err
+	}
+	s.sheet = sheet
+	s.decoder = xml.NewDecoder(sheet)
+	return nil
+}
+
+// errPartMissing is wrapped when the archive has no file of the requested name.
+var errPartMissing = errors.New("missing part")
+
+func (s *xlsxSource) openPart(name string) (io.ReadCloser, error) {
+	for _, f := range s.archive.File {
+		if f.Name == name {
+			return f.Open()
+		}
+	}
+	return nil, fmt.Errorf("%w %s", errPartMissing, name)
+}
+
+func (s *xlsxSource) decodePart(name string, v interface{}) error {
+	part, err := s.openPart(name)
+	if err != nil {
+		return err
+	}
+	defer part.Close()
+	if err := xml.NewDecoder(part).Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// row decodes the next <row> element into cell texts, placed by their column reference.
+func (s *xlsxSource) row() ([]string, error) {
+	for {
+		token, err := s.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		var row xlsxRow
+		if err := s.decoder.DecodeElement(&row, &start); err != nil {
+			return nil, err
+		}
+
+		var cells []string
+		for i, cell := range row.Cells {
+			column := i
+			if cell.Ref != "" {
+				if column, err = columnIndex(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			for len(cells) <= column {
+				cells = append(cells, "")
+			}
+			cells[column] = s.cellText(cell.Type, cell.Style, cell.Value, cell.Inline.T)
+		}
+		return cells, nil
+	}
+}
+
+func (s *xlsxSource) cellText(kind string, style int, value, inline string) string {
+	switch kind {
+	case "s":
+		index, err := strconv.Atoi(value)
+		if err != nil || index < 0 || index >= len(s.strings) {
+			return ""
+		}
+		return s.strings[index]
+	case "inlineStr":
+		return inline
+	case "b":
+		return strconv.FormatBool(value == "1")
+	case "", "n":
+		if layout, ok := s.dates[style]; ok {
+			if serial, err := strconv.ParseFloat(value, 64); err == nil {
+				seconds := time.Duration(serial*86400+0.5) * time.Second
+				return s.epoch.Add(seconds).Format(layout)
+			}
+		}
+	}
+	return value
+}
+
+// dateStyles maps each cell style whose number format shows a date or time to the
+// layout its serial values are formatted with.
+func dateStyles(styles xlsxStyles) map[int]string {
+	codes := map[int]string{}
+	for _, format := range styles.NumFmts {
+		codes[format.ID] = format.Code
+	}
+	dates := map[int]string{}
+	for i, xf := range styles.CellXfs {
+		date, clock := false, false
+		switch id := xf.NumFmtID; {
+		case id >= 14 && id <= 17:
+			date = true
+		case id >= 18 && id <= 21, id >= 45 && id <= 47:
+			clock = true
+		case id == 22:
+			date, clock = true, true
+		default:
+			if code, ok := codes[id]; ok {
+				date, clock = dateFormatParts(code)
+			}
+		}
+		switch {
+		case date && clock:
+			dates[i] = "2006-01-02 15:04:05"
+		case date:
+			dates[i] = "2006-01-02"
+		case clock:
+			dates[i] = "15:04:05"
+		}
+	}
+	return dates
+}
+
+// dateFormatParts reports whether a custom number format code shows a date and a
+// time of day. Quoted text, escaped characters and bracketed colours are skipped;
+// "m" alone is ambiguous between months and minutes and does not count.
+func dateFormatParts(code string) (date, clock bool) {
+	code = strings.ToLower(code)
+	for i := 0; i < len(code); i++ {
+		switch c := code[i]; c {
+		case '"':
+			if end := strings.IndexByte(code[i+1:], '"'); end >= 0 {
+				i += end + 1
+			}
+		case '\\', '_', '*':
+			i++
+		case '[':
+			end := strings.IndexByte(code[i:], ']')
+			if end < 0 {
+				return date, clock
+			}
+			if inner := code[i+1 : i+end]; strings.Trim(inner, "hms") == "" {
+				clock = true
+			}
+			i += end
+		case 'a':
+			if strings.HasPrefix(code[i:], "am/pm") || strings.HasPrefix(code[i:], "a/p") {
+				clock = true
+			}
+		case 'd', 'y':
+			date = true
+		case 'h', 's':
+			clock = true
+		}
+	}
+	return date, clock
+}
+
+func (s *xlsxSource) Next() (interface{}, error) {
+	cells, err := s.row()
+	if err != nil {
+		return nil, err
+	}
+	return rowItem(s.header, cells), nil
+}
+
+func (s *xlsxSource) Close() error {
+	if s.sheet != nil {
+		s.sheet.Close()
+	}
+	return s.archive.Close()
+}
+
+// maxXLSXColumns is the number of columns in a worksheet, A to XFD.
+const maxXLSXColumns = 16384
+
+// columnIndex converts a cell reference such as "AB12" to its zero-based column.
+func columnIndex(ref string) (int, error) {
+	column, letters := 0, 0
+	for letters < len(ref) && ref[letters] >= 'A' && ref[letters] <= 'Z' {
+		column = column*26 + int(ref[letters]-'A') + 1
+		letters++
+		if column > maxXLSXColumns {
+			return 0, fmt.Errorf("invalid cell reference %q: column beyond XFD", ref)
+		}
+	}
+	row := ref[letters:]
+	if letters == 0 || row == "" || strings.Trim(row, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid cell reference %q: expected a column and row such as B2", ref)
+	}
+	return column - 1, nil
+}
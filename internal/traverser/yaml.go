@@ -0,0 +1,317 @@
+package traverser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// jsonNumberPattern matches number literals that JSON accepts as they are written.
+var jsonNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// yamlLinePattern finds the line number in a yaml.v3 syntax error.
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// IsYAML reports whether path names a YAML document.
+func IsYAML(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// ValidateWorkflowYAML is ValidateWorkflow for YAML documents. Anchors and merge keys are
+// expanded first; errors are reported at the YAML line and column of the offending value,
+// which for reused content is where its anchor is defined.
+func ValidateWorkflowYAML(data []byte) ValidationErrors {
+	converted, positions, err := convertYAML(data)
+	if err != nil {
+		return yamlSyntaxError(err)
+	}
+	return validateDocument(converted, positions)
+}
+
+// ParseWorkflowYAML is ParseWorkflow for YAML documents.
+func ParseWorkflowYAML(data []byte) (*Workflow, error) {
+	converted, positions, err := convertYAML(data)
+	if err != nil {
+		return nil, yamlSyntaxError(err)
+	}
+	return parseWorkflow(converted, func() ValidationErrors { return validateDocument(converted, positions) })
+}
+
+// ParseContextYAML is ParseContext for YAML documents.
+func ParseContextYAML(data []byte) (map[string]interface{}, error) {
+	converted, _, err := convertYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing context: %w", err)
+	}
+	return ParseContext(converted)
+}
+
+// YAMLToJSON converts a YAML document to indented JSON, keeping key order. Anchors, aliases
+// and merge keys are expanded; comments are dropped.
+func YAMLToJSON(data []byte) ([]byte, error) {
+	converted, _, err := convertYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, converted, "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// JSONToYAML converts a JSON object to YAML, keeping key order. Strings that would read
+// as another type are quoted and numbers are written exactly as they were, so converting
+// the result back gives the same document.
+func JSONToYAML(data []byte) ([]byte, error) {
+	doc, err := decodeObject(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing JSON: %w", err)
+	}
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(yamlNode(doc)); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func yamlNode(value interface{}) *yaml.Node {
+	switch value := value.(type) {
+	case *Object:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range value.keys {
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+				yamlNode(value.values[key]))
+		}
+		if len(node.Content) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		return node
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range value {
+			node.Content = append(node.Content, yamlNode(item))
+		}
+		if len(node.Content) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		return node
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(value.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value.String()}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(value)}
+	case string:
+		node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+		if strings.Contains(value, "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+		return node
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+}
+
+// yamlPositions maps JSON pointers to where their values are written in a YAML document.
+type yamlPositions map[string][2]int
+
+func (p yamlPositions) lookup(pointer string) (int, int) {
+	for {
+		if position, ok := p[pointer+"#key"]; ok {
+			return position[0], position[1]
+		}
+		if position, ok := p[pointer]; ok {
+			return position[0], position[1]
+		}
+		if pointer == "" {
+			return 1, 1
+		}
+		pointer = pointer[:strings.LastIndex(pointer, "/")]
+	}
+}
+
+// convertYAML converts the first document of a YAML stream to compact JSON and records
+// the position of every value.
+func convertYAML(data []byte) ([]byte, yamlPositions, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, err
+	}
+	converter := &yamlConverter{positions: make(yamlPositions)}
+	var doc interface{}
+	if len(root.Content) > 0 {
+		var err error
+		if doc, err = converter.convert(root.Content[0], ""); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	if err := writeJSON(&out, doc); err != nil {
+		return nil, nil, err
+	}
+	return out.Bytes(), converter.positions, nil
+}
+
+type yamlConverter struct {
+	positions yamlPositions
+	depth     int
+	values    int
+}
+
+// maxAliasDepth stops aliases that refer to themselves.
+const maxAliasDepth = 1000
+
+// maxYAMLValues caps the values a document expands to. Aliases of aliases multiply: nine
+// levels of nine aliases are a small file that expands to a billion values.
+const maxYAMLValues = 1000000
+
+func (c *yamlConverter) convert(node *yaml.Node, pointer string) (interface{}, error) {
+	if c.values++; c.values > maxYAMLValues {
+		return nil, fmt.Errorf("line %d: document expands to more than %d values; check for aliases of aliases",
+			node.Line, maxYAMLValues)
+	}
+	c.positions[pointer] = [2]int{node.Line, node.Column}
+
+	switch node.Kind {
+	case yaml.AliasNode:
+		if c.depth++; c.depth > maxAliasDepth {
+			return nil, fmt.Errorf("line %d: alias *%s is nested too deeply", node.Line, node.Value)
+		}
+		defer func() { c.depth-- }()
+		return c.convert(node.Alias, pointer)
+	case yaml.MappingNode:
+		return c.convertMapping(node, pointer)
+	case yaml.SequenceNode:
+		items := []interface{}{}
+		for i, item := range node.Content {
+			value, err := c.convert(item, fmt.Sprintf("%s/%d", pointer, i))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	case yaml.ScalarNode:
+		return c.convertScalar(node)
+	}
+	return nil, fmt.Errorf("line %d: unsupported YAML node", node.Line)
+}
+
+// convertMapping converts a mapping in key order. Keys merged in with "<<" take the place
+// of the merge key, and keys written in the mapping itself override them.
+func (c *yamlConverter) convertMapping(node *yaml.Node, pointer string) (*Object, error) {
+	explicit := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if key := node.Content[i]; key.ShortTag() != "!!merge" {
+			explicit[key.Value] = true
+		}
+	}
+
+	result := newObject()
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.ShortTag() == "!!merge" {
+			if err := c.merge(result, value, pointer, explicit); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		child := pointerJoin(pointer, key.Value)
+		converted, err := c.convert(value, child)
+		if err != nil {
+			return nil, err
+		}
+		c.positions[child+"#key"] = [2]int{key.Line, key.Column}
+		result.Set(key.Value, converted)
+	}
+	return result, nil
+}
+
+// merge adds the keys of a "<<" value, a mapping or a list of mappings, that the mapping
+// does not set itself. Earlier mappings in a list win.
+func (c *yamlConverter) merge(result *Object, value *yaml.Node, pointer string, explicit map[string]bool) error {
+	sources := []*yaml.Node{value}
+	if value.Kind == yaml.SequenceNode {
+		sources = value.Content
+	}
+	for _, source := range sources {
+		converted, err := c.convert(source, pointer)
+		if err != nil {
+			return err
+		}
+		merged, ok := converted.(*Object)
+		if !ok {
+			return fmt.Errorf("line %d: merge key << needs a mapping", source.Line)
+		}
+		for _, key := range merged.keys {
+			if !explicit[key] && !result.Has(key) {
+				result.Set(key, merged.values[key])
+			}
+		}
+	}
+	return nil
+}
+
+func (c *yamlConverter) convertScalar(node *yaml.Node) (interface{}, error) {
+	switch node.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var value bool
+		err := node.Decode(&value)
+		return value, err
+	case "!!int":
+		if jsonNumberPattern.MatchString(node.Value) {
+			return json.Number(node.Value), nil
+		}
+		var value int64
+		if err := node.Decode(&value); err != nil {
+			return nil, err
+		}
+		return json.Number(strconv.FormatInt(value, 10)), nil
+	case "!!float":
+		if jsonNumberPattern.MatchString(node.Value) {
+			return json.Number(node.Value), nil
+		}
+		var value float64
+		if err := node.Decode(&value); err != nil {
+			return nil, err
+		}
+		if math.IsInf(value, 0) || math.IsNaN(value) {
+			return nil, fmt.Errorf("line %d: %s cannot be represented in JSON", node.Line, node.Value)
+		}
+		return json.Number(strconv.FormatFloat(value, 'g', -1, 64)), nil
+	}
+	return node.Value, nil
+}
+
+// yamlSyntaxError reports a YAML parse error at the line yaml.v3 names in it.
+func yamlSyntaxError(err error) ValidationErrors {
+	line := 1
+	if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+		line, _ = strconv.Atoi(match[1])
+	}
+	message := strings.TrimPrefix(err.Error(), "yaml: ")
+	return ValidationErrors{{Line: line, Column: 1, Message: fmt.Sprintf("invalid YAML: %s", message)}}
+}
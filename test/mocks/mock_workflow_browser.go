@@ -0,0 +1,242 @@
+package mocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"rpa-dfs-engine/internal/traverser"
+)
+
+// MockWorkflowBrowser records the actions a traverser engine performs.
+// FailSelector errors are returned on every call; FailTimes limits how many
+// calls fail before the selector starts working. Extraction reads from Elements
+// (text, count, visibility and enabled state), Values, and Attributes keyed by
+// "selector@name". Scripts holds the result of each waitFor script, ScriptArgs the
+// arguments it was last checked with, and NetworkIdle
+// reports busy until BusyPolls calls have been made. Evaluate returns the entry of Results
+// for the script and fails with FailSelector keyed by the script. OpenTab returns a copy of the
+// browser's configuration as a new mock, listed in Tabs.
+type MockWorkflowBrowser struct {
+	Actions      []string
+	FailSelector map[string]error
+	FailTimes    map[string]int
+	Timeouts     []time.Duration
+	URL          string
+	PageTitle    string
+	Scripts      map[string]bool
+	ScriptArgs   map[string][]interface{}
+	Results      map[string]interface{}
+	BusyPolls    int
+	Elements     map[string]traverser.SelectorInfo
+	Attributes   map[string]string
+	Values       map[string]string
+	Tabs         []*MockWorkflowBrowser
+	Isolated     bool
+	Closed       bool
+
+	timeout time.Duration
+	mu      sync.Mutex
+}
+
+func NewMockWorkflowBrowser() *MockWorkflowBrowser {
+	return &MockWorkflowBrowser{
+		FailSelector: make(map[string]error),
+		FailTimes:    make(map[string]int),
+		Elements:     make(map[string]traverser.SelectorInfo),
+		Attributes:   make(map[string]string),
+		Values:       make(map[string]string),
+		Scripts:      make(map[string]bool),
+		ScriptArgs:   make(map[string][]interface{}),
+		Results:      make(map[string]interface{}),
+		timeout:      30 * time.Second,
+	}
+}
+
+func (m *MockWorkflowBrowser) fail(selector string) error {
+	err := m.FailSelector[selector]
+	if err == nil {
+		return nil
+	}
+	if remaining, limited := m.FailTimes[selector]; limited {
+		if remaining == 0 {
+			return nil
+		}
+		m.FailTimes[selector] = remaining - 1
+	}
+	return err
+}
+
+func (m *MockWorkflowBrowser) ActionTimeout() time.Duration {
+	return m.timeout
+}
+
+func (m *MockWorkflowBrowser) SetActionTimeout(timeout time.Duration) {
+	m.timeout = timeout
+	m.Timeouts = append(m.Timeouts, timeout)
+}
+
+func (m *MockWorkflowBrowser) NavigateTo(url string) error {
+	m.Actions = append(m.Actions, "navigate "+url)
+	m.URL = url
+	return nil
+}
+
+func (m *MockWorkflowBrowser) CurrentURL() (string, error) {
+	return m.URL, nil
+}
+
+func (m *MockWorkflowBrowser) FillField(selector, value string) error {
+	if err := m.fail(selector); err != nil {
+		return err
+	}
+	m.Actions = append(m.Actions, fmt.Sprintf("fill %s=%s", selector, value))
+	return nil
+}
+
+func (m *MockWorkflowBrowser) ClickButton(selector string) error {
+	if err := m.fail(selector); err != nil {
+		return err
+	}
+	m.Actions = append(m.Actions, "click "+selector)
+	return nil
+}
+
+func (m *MockWorkflowBrowser) UploadFile(selector, filePath string) error {
+	if err := m.fail(selector); err != nil {
+		return err
+	}
+	m.Actions = append(m.Actions, fmt.Sprintf("upload %s=%s", selector, filePath))
+	return nil
+}
+
+func (m *MockWorkflowBrowser) Text(selector string) (string, error) {
+	if err := m.fail(selector); err != nil {
+		return "", err
+	}
+	return m.Elements[selector].Text, nil
+}
+
+func (m *MockWorkflowBrowser) Attribute(selector, name string) (string, error) {
+	if err := m.fail(selector); err != nil {
+		return "", err
+	}
+	value, ok := m.Attributes[selector+"@"+name]
+	if !ok {
+		return "", fmt.Errorf("attribute %q not set on %s", name, selector)
+	}
+	return value, nil
+}
+
+func (m *MockWorkflowBrowser) Value(selector string) (string, error) {
+	if err := m.fail(selector); err != nil {
+		return "", err
+	}
+	return m.Values[selector], nil
+}
+
+func (m *MockWorkflowBrowser) Count(selector string) (int, error) {
+	return m.Elements[selector].Count, nil
+}
+
+func (m *MockWorkflowBrowser) Visible(selector string) (bool, error) {
+	if err := m.fail(selector); err != nil {
+		return false, err
+	}
+	return m.Elements[selector].Visible, nil
+}
+
+func (m *MockWorkflowBrowser) Enabled(selector string) (bool, error) {
+	if err := m.fail(selector); err != nil {
+		return false, err
+	}
+	return m.Elements[selector].Enabled, nil
+}
+
+func (m *MockWorkflowBrowser) Truthy(script string, args []interface{}) (bool, error) {
+	m.ScriptArgs[script] = args
+	return m.Scripts[script], nil
+}
+
+func (m *MockWorkflowBrowser) NetworkIdle(quiet time.Duration) (bool, error) {
+	if m.BusyPolls > 0 {
+		m.BusyPolls--
+		return false, nil
+	}
+	return true, nil
+}
+
+func (m *MockWorkflowBrowser) Evaluate(frame, script string, args []interface{}) (interface{}, error) {
+	if err := m.fail(script); err != nil {
+		return nil, err
+	}
+	encoded, _ := json.Marshal(args)
+	action := fmt.Sprintf("evaluate %s %s", script, encoded)
+	if frame != "" {
+		action = fmt.Sprintf("evaluate in %s %s %s", frame, script, encoded)
+	}
+	m.Actions = append(m.Actions, action)
+	return m.Results[script], nil
+}
+
+func (m *MockWorkflowBrowser) Title() (string, error) {
+	return m.PageTitle, nil
+}
+
+func (m *MockWorkflowBrowser) InspectSelector(selector string) (traverser.SelectorInfo, error) {
+	return m.Elements[selector], nil
+}
+
+func (m *MockWorkflowBrowser) OpenTab(isolated bool) (traverser.Tab, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tab := NewMockWorkflowBrowser()
+	tab.Isolated = isolated
+	tab.URL = m.URL
+	tab.PageTitle = m.PageTitle
+	tab.timeout = m.timeout
+	for selector, err := range m.FailSelector {
+		tab.FailSelector[selector] = err
+	}
+	for selector, times := range m.FailTimes {
+		tab.FailTimes[selector] = times
+	}
+	for selector, info := range m.Elements {
+		tab.Elements[selector] = info
+	}
+	for key, value := range m.Attributes {
+		tab.Attributes[key] = value
+	}
+	for selector, value := range m.Values {
+		tab.Values[selector] = value
+	}
+	for script, value := range m.Scripts {
+		tab.Scripts[script] = value
+	}
+	for script, value := range m.Results {
+		tab.Results[script] = value
+	}
+	m.Tabs = append(m.Tabs, tab)
+	return tab, nil
+}
+
+func (m *MockWorkflowBrowser) Close() {
+	m.Closed = true
+}
+
+func (m *MockWorkflowBrowser) Reset() {
+	m.Actions = nil
+	m.FailSelector = make(map[string]error)
+	m.FailTimes = make(map[string]int)
+	m.Timeouts = nil
+	m.URL = ""
+	m.PageTitle = ""
+	m.Scripts = make(map[string]bool)
+	m.BusyPolls = 0
+	m.Tabs = nil
+	m.Elements = make(map[string]traverser.SelectorInfo)
+	m.Attributes = make(map[string]string)
+	m.Values = make(map[string]string)
+}
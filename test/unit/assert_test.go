@@ -0,0 +1,144 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const assertionWorkflow = `{
+	"metadata": {"name": "checkout"},
+	"graph": {
+		"nodeType": "assertVisible",
+		"id": "banner-shown",
+		"selector": ".banner",
+		"next": {
+			"nodeType": "assertText",
+			"id": "greeting",
+			"selector": "h1",
+			"expected": "Welcome, {{user.name}}",
+			"next": {
+				"nodeType": "assertURL",
+				"id": "on-dashboard",
+				"expected": "/dashboard",
+				"match": "contains",
+				"next": {
+					"nodeType": "assertTitle",
+					"id": "title",
+					"expected": "^Dashboard",
+					"match": "matches",
+					"next": {"nodeType": "clickButton", "selector": "#logout"}
+				}
+			}
+		}
+	}
+}`
+
+func TestEngineExecute_WithPassingAssertions_Succeeds(t *testing.T) {
+	engine, browser := newTestEngine(t, assertionWorkflow, map[string]interface{}{"name": "Ada"})
+	browser.Elements[".banner"] = traverser.SelectorInfo{Count: 1, Visible: true}
+	browser.Elements["h1"] = traverser.SelectorInfo{Count: 1, Text: " Welcome, Ada "}
+	browser.URL = "https://example.com/dashboard?tab=1"
+	browser.PageTitle = "Dashboard - Example"
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"click #logout"}, browser.Actions)
+}
+
+func TestEngineExecute_WithFailedAssertionInStopMode_FailsWithExpectedAndActual(t *testing.T) {
+	engine, browser := newTestEngine(t, assertionWorkflow, map[string]interface{}{"name": "Ada"})
+	browser.Elements[".banner"] = traverser.SelectorInfo{Count: 1, Visible: true}
+	browser.Elements["h1"] = traverser.SelectorInfo{Count: 1, Text: "Welcome, Bob"}
+
+	err := engine.Execute()
+
+	var assertionErr *traverser.AssertionError
+	require.True(t, errors.As(err, &assertionErr))
+	assert.Equal(t, "h1", assertionErr.Target)
+	assert.Equal(t, `"Welcome, Ada"`, assertionErr.Expected)
+	assert.Equal(t, `"Welcome, Bob"`, assertionErr.Actual)
+	assert.Equal(t, traverser.ErrorKindAssertion, engine.Result().ErrorKind)
+	assert.Empty(t, browser.Actions)
+
+	nodes := engine.Result().Nodes
+	assert.Equal(t, traverser.NodeFailed, nodes[len(nodes)-1].Status)
+	assert.Equal(t, `"Welcome, Bob"`, nodes[len(nodes)-1].Actual)
+}
+
+func TestEngineExecute_WithFailedAssertionsInCollectMode_ContinuesAndFailsAtEnd(t *testing.T) {
+	engine, browser := newTestEngine(t, assertionWorkflow, map[string]interface{}{"name": "Ada"})
+	require.NoError(t, engine.SetAssertionMode(traverser.AssertCollect))
+	browser.Elements["h1"] = traverser.SelectorInfo{Count: 1, Text: "Welcome, Ada"}
+	browser.URL = "https://example.com/login"
+	browser.PageTitle = "Dashboard"
+
+	err := engine.Execute()
+
+	var failures *traverser.AssertionFailures
+	require.True(t, errors.As(err, &failures))
+	require.Len(t, failures.Failures, 2)
+	assert.Contains(t, failures.Failures[0].Error(), "checkout#banner-shown: assertVisible .banner: expected visible, got not visible")
+	assert.Contains(t, failures.Failures[1].Error(), `expected containing "/dashboard", got "https://example.com/login"`)
+	assert.Equal(t, []string{"click #logout"}, browser.Actions)
+	assert.Equal(t, traverser.RunFailed, engine.Result().Status)
+}
+
+func TestEngineExecute_WithAssertionRetry_RechecksUntilAttemptsRunOut(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "assertNotVisible",
+			"selector": ".spinner",
+			"retry": {"count": 2, "on": ["assertion"]}
+		}
+	}`, nil)
+	browser.Elements[".spinner"] = traverser.SelectorInfo{Count: 1, Visible: true}
+
+	err := engine.Execute()
+
+	assert.Equal(t, traverser.ErrorKindAssertion, traverser.ErrorKind(err))
+	assert.Equal(t, 3, engine.Result().Nodes[0].Attempts)
+	assert.Equal(t, []string{"retry"}, engine.Result().Nodes[0].Policies)
+}
+
+func TestRunResultJUnit_WithAssertionsAndRunError_ReportsCases(t *testing.T) {
+	engine, browser := newTestEngine(t, assertionWorkflow, map[string]interface{}{"name": "Ada"})
+	require.NoError(t, engine.SetAssertionMode(traverser.AssertCollect))
+	browser.Elements[".banner"] = traverser.SelectorInfo{Count: 1, Visible: true}
+	browser.Elements["h1"] = traverser.SelectorInfo{Count: 1, Text: "Hello"}
+	browser.URL = "https://example.com/dashboard"
+	browser.PageTitle = "Dashboard"
+	browser.FailSelector["#logout"] = errors.New("detached")
+
+	require.Error(t, engine.Execute())
+	report, err := engine.Result().JUnit()
+
+	require.NoError(t, err)
+	xml := string(report)
+	assert.Contains(t, xml, `<testsuite name="checkout" tests="5" failures="1" errors="1"`)
+	assert.Contains(t, xml, `<testcase name="banner-shown" classname="checkout"></testcase>`)
+	assert.Contains(t, xml, `<failure message="assertText h1: expected &#34;Welcome, Ada&#34;, got &#34;Hello&#34;" type="assertText">`)
+	assert.Contains(t, xml, `<testcase name="run" classname="checkout">`)
+}
+
+func TestValidateWorkflow_WithInvalidAssertion_ReportsErrors(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "assertText",
+			"selector": "h1",
+			"match": "startsWith",
+			"next": {"nodeType": "assertURL", "selector": "#x", "expected": "/home"}
+		}
+	}`))
+
+	require.Len(t, errs, 3)
+	assert.Equal(t, "/graph/match", errs[0].Pointer)
+	assert.Contains(t, errs[0].Message, "match must be one of equals, contains, matches")
+	assert.Contains(t, errs[1].Message, `unknown property "selector" for nodeType "assertURL"`)
+	assert.Contains(t, errs[2].Message, `requires property "expected"`)
+}
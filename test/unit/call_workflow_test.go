@@ -0,0 +1,161 @@
+package unit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rpa-dfs-engine/internal/traverser"
+	"rpa-dfs-engine/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeWorkflowFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func newFileEngine(t *testing.T, path string, user map[string]interface{}) (*traverser.Engine, *mocks.MockWorkflowBrowser) {
+	t.Helper()
+
+	browser := mocks.NewMockWorkflowBrowser()
+	engine := traverser.NewEngine(browser)
+	require.NoError(t, engine.LoadWorkflow(path))
+	engine.SetContext(user)
+	engine.SetIO(strings.NewReader(""), &bytes.Buffer{})
+	return engine, browser
+}
+
+func TestEngineExecute_WithCallWorkflow_PassesParamsAndCopiesOutputs(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkflowFile(t, dir, "login.json", `{
+		"graph": {
+			"nodeType": "fillField",
+			"selector": "#email",
+			"value": "{{user.email}}",
+			"next": {"nodeType": "clickButton", "selector": "#login"}
+		},
+		"metadata": {"name": "login"}
+	}`)
+	main := writeWorkflowFile(t, dir, "main.json", `{
+		"graph": {
+			"nodeType": "callWorkflow",
+			"workflow": "login.json",
+			"params": {"email": "{{user.account.email}}", "retries": 2},
+			"outputs": {"user.session.email": "user.email"},
+			"next": {"nodeType": "moveToPage", "url": "https://example.com/{{user.session.email}}"}
+		},
+		"metadata": {"name": "main"}
+	}`)
+	engine, browser := newFileEngine(t, main, map[string]interface{}{
+		"account": map[string]interface{}{"email": "john@example.com"},
+	})
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"fill #email=john@example.com",
+		"click #login",
+		"navigate https://example.com/john@example.com",
+	}, browser.Actions)
+}
+
+func TestEngineExecute_WithCatalogName_LoadsWorkflowFromCatalogDir(t *testing.T) {
+	catalog := t.TempDir()
+	writeWorkflowFile(t, catalog, "upload.json", `{
+		"graph": {"nodeType": "sendFile", "selector": "#file", "filePath": "{{user.files[1]}}"}
+	}`)
+	main := writeWorkflowFile(t, t.TempDir(), "main.json", `{
+		"graph": {"nodeType": "callWorkflow", "workflow": "upload", "params": {"files": "{{user.documents}}"}}
+	}`)
+	engine, browser := newFileEngine(t, main, map[string]interface{}{
+		"documents": []interface{}{"a.pdf", "b.pdf"},
+	})
+	engine.SetCatalogDir(catalog)
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"upload #file=b.pdf"}, browser.Actions)
+}
+
+func TestEngineExecute_WithFailingCallee_ReturnsErrorWithCallStack(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkflowFile(t, dir, "login.json", `{
+		"graph": {"id": "fill-email", "nodeType": "fillField", "selector": "#email", "value": "x"},
+		"metadata": {"name": "login"}
+	}`)
+	main := writeWorkflowFile(t, dir, "main.json", `{
+		"graph": {"id": "call-login", "nodeType": "callWorkflow", "workflow": "login.json"},
+		"metadata": {"name": "main"}
+	}`)
+	engine, browser := newFileEngine(t, main, nil)
+	browser.FailSelector["#email"] = assert.AnError
+
+	err := engine.Execute()
+
+	var wfErr *traverser.WorkflowError
+	require.ErrorAs(t, err, &wfErr)
+	assert.Equal(t, []traverser.StackFrame{
+		{Workflow: "main", Node: "call-login"},
+		{Workflow: "login", Node: "fill-email"},
+	}, wfErr.Stack)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Contains(t, err.Error(), "main#call-login > login#fill-email")
+}
+
+func TestEngineExecute_WithRecursiveCall_StopsAtDepthLimit(t *testing.T) {
+	path := writeWorkflowFile(t, t.TempDir(), "loop.json", `{
+		"graph": {
+			"nodeType": "clickButton",
+			"selector": "#again",
+			"next": {"id": "recurse", "nodeType": "callWorkflow", "workflow": "loop.json"}
+		},
+		"metadata": {"name": "loop"}
+	}`)
+	engine, browser := newFileEngine(t, path, nil)
+	engine.SetMaxCallDepth(3)
+
+	err := engine.Execute()
+
+	var wfErr *traverser.WorkflowError
+	require.ErrorAs(t, err, &wfErr)
+	assert.Len(t, wfErr.Stack, 4)
+	assert.Contains(t, err.Error(), "call depth limit (3) exceeded")
+	assert.Len(t, browser.Actions, 4)
+}
+
+func TestValidateWorkflow_WithCallWorkflowMissingWorkflow_ReportsError(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+		"graph": {"nodeType": "callWorkflow", "outputs": {"user.id": 5}}
+	}`))
+
+	require.Len(t, errs, 2)
+	assert.Equal(t, "/graph/outputs/user.id", errs[0].Pointer)
+	assert.Equal(t, "/graph", errs[1].Pointer)
+	assert.Contains(t, errs[1].Message, "workflow")
+}
+
+func TestCatalogPath_WithPathInsteadOfName_Fails(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkflowFile(t, dir, "checkout.yaml", `{"graph": {"nodeType": "clickButton", "selector": "#buy"}}`)
+
+	path, err := traverser.CatalogPath(dir, "checkout")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "checkout.yaml"), path)
+
+	for _, name := range []string{"../secrets", "/etc/passwd", "sub/checkout", `..\checkout`, ".hidden"} {
+		_, err := traverser.CatalogPath(dir, name)
+		assert.Error(t, err, name)
+	}
+	_, err = traverser.CatalogPath("", "checkout")
+	assert.Error(t, err)
+}
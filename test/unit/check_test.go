@@ -0,0 +1,141 @@
+package unit
+
+import (
+	"testing"
+
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCheckUser() map[string]interface{} {
+	return map[string]interface{}{
+		"age":        float64(30),
+		"zip":        "10115",
+		"account":    "007",
+		"scraped":    "42",
+		"birthDate":  "1994-05-17",
+		"middleName": "",
+		"nickname":   nil,
+		"active":     true,
+		"country":    "DE",
+		"tags":       []interface{}{"vip", "beta"},
+		"orders": []interface{}{
+			map[string]interface{}{"id": "A1", "total": float64(80), "status": "shipped"},
+			map[string]interface{}{"id": "B2", "total": float64(250), "status": "open"},
+			map[string]interface{}{"id": "C3", "total": float64(120), "status": "cancelled"},
+		},
+	}
+}
+
+// checkBranch runs a question node with check and returns the branch it took.
+func checkBranch(t *testing.T, check string) string {
+	t.Helper()
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "question",
+			"check": `+check+`,
+			"branches": {
+				"yes": {"nodeType": "fillField", "selector": "#branch", "value": "yes"},
+				"no": {"nodeType": "fillField", "selector": "#branch", "value": "no"}
+			}
+		}
+	}`, newCheckUser())
+	require.NoError(t, engine.Execute())
+	require.Len(t, browser.Actions, 1)
+	return browser.Actions[0][len("fill #branch="):]
+}
+
+func TestQuestionCheck_WithComparisonOperators_ComparesByType(t *testing.T) {
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.scraped", "operator": "equals", "expectedValue": 42}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.scraped", "operator": "greaterThan", "expectedValue": 9}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.age", "operator": "notEquals", "expectedValue": 31}`))
+	assert.Equal(t, "no", checkBranch(t, `{"dataPath": "user.age", "operator": "lessThan", "expectedValue": 30}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.birthDate", "operator": "lessThan", "expectedValue": "2000-01-01T00:00:00Z"}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.birthDate", "operator": "between", "expectedValue": ["1960-01-01", "2005-12-31"]}`))
+	assert.Equal(t, "no", checkBranch(t, `{"dataPath": "user.age", "operator": "between", "expectedValue": [31, 65]}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.country", "operator": "in", "expectedValue": ["DE", "AT", "CH"]}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.zip", "operator": "matches", "expectedValue": "^[0-9]{5}$"}`))
+}
+
+func TestQuestionCheck_WithEquals_DoesNotConvertBetweenTypes(t *testing.T) {
+	assert.Equal(t, "no", checkBranch(t, `{"dataPath": "user.nickname", "operator": "equals", "expectedValue": ""}`))
+	assert.Equal(t, "no", checkBranch(t, `{"dataPath": "user.middleName", "operator": "equals", "expectedValue": null}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.nickname", "operator": "equals", "expectedValue": null}`))
+	assert.Equal(t, "no", checkBranch(t, `{"dataPath": "user.active", "operator": "equals", "expectedValue": "true"}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.active", "operator": "notEquals", "expectedValue": "true"}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.active", "operator": "equals", "expectedValue": true}`))
+	assert.Equal(t, "no", checkBranch(t, `{"dataPath": "user.active", "operator": "in", "expectedValue": ["true", 1]}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.tags", "operator": "equals", "expectedValue": ["vip", "beta"]}`))
+	assert.Equal(t, "no", checkBranch(t, `{"dataPath": "user.tags", "operator": "equals", "expectedValue": "[vip beta]"}`))
+}
+
+func TestQuestionCheck_WithNumericStrings_ComparesThemAsText(t *testing.T) {
+	assert.Equal(t, "no", checkBranch(t, `{"dataPath": "user.account", "operator": "equals", "expectedValue": "7"}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.account", "operator": "equals", "expectedValue": "007"}`))
+	assert.Equal(t, "no", checkBranch(t, `{"dataPath": "user.account", "operator": "in", "expectedValue": ["7", "07"]}`))
+	assert.Equal(t, "no", checkBranch(t, `{"dataPath": "user.zip", "operator": "equals", "expectedValue": "10115.0"}`))
+	assert.Equal(t, "no", checkBranch(t, `{"dataPath": "user.scraped", "operator": "equals", "expectedValue": "4.2e1"}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.account", "operator": "equals", "expectedValue": 7}`))
+}
+
+func TestQuestionCheck_WithPresenceAndTypeOperators_AcceptsMissingData(t *testing.T) {
+	assert.Equal(t, "no", checkBranch(t, `{"dataPath": "user.phone", "operator": "exists"}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.phone", "operator": "notExists"}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.phone", "operator": "isEmpty"}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.middleName", "operator": "isEmpty"}`))
+	assert.Equal(t, "no", checkBranch(t, `{"dataPath": "user.tags", "operator": "isEmpty"}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.tags", "operator": "isType", "expectedValue": "array"}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "user.birthDate", "operator": "isType", "expectedValue": "date"}`))
+	assert.Equal(t, "no", checkBranch(t, `{"dataPath": "user.zip", "operator": "isType", "expectedValue": "number"}`))
+}
+
+func TestQuestionCheck_WithJSONPath_ReachesIntoArrays(t *testing.T) {
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "$.user.orders[-1].status", "operator": "equals", "expectedValue": "cancelled"}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "$.user.orders[?(@.total > 100 && @.status != 'cancelled')]", "operator": "exists"}`))
+	assert.Equal(t, "no", checkBranch(t, `{"dataPath": "$.user.orders[?(@.total > 500)]", "operator": "exists"}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "$.user.orders[*].id", "operator": "contains", "expectedValue": "B2"}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "$..status", "operator": "contains", "expectedValue": "open"}`))
+	assert.Equal(t, "no", checkBranch(t, `{"dataPath": "$.user.orders[0:2].id", "operator": "contains", "expectedValue": "C3"}`))
+	assert.Equal(t, "yes", checkBranch(t, `{"dataPath": "$.user['tags'][0]", "operator": "in", "expectedValue": ["vip"]}`))
+}
+
+func TestQuestionCheck_WithUnorderableValues_Fails(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "question",
+			"check": {"dataPath": "user.active", "operator": "greaterThan", "expectedValue": 1},
+			"branches": {"yes": {"nodeType": "clickButton", "selector": "#next"}}
+		}
+	}`, map[string]interface{}{"active": true})
+
+	err := engine.Execute()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot compare boolean with number")
+	assert.Empty(t, browser.Actions)
+}
+
+func TestValidateWorkflow_WithMalformedCheckValues_ReportsEachCheck(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "sequence",
+			"sequence": [
+				{"nodeType": "question", "check": {"dataPath": "user.age", "operator": "between", "expectedValue": 18}, "branches": {}},
+				{"nodeType": "question", "check": {"dataPath": "user.zip", "operator": "matches", "expectedValue": "[0-9"}, "branches": {}},
+				{"nodeType": "question", "check": {"dataPath": "user.zip", "operator": "exists", "expectedValue": "x"}, "branches": {}},
+				{"nodeType": "question", "check": {"dataPath": "user.zip", "operator": "isType", "expectedValue": "int"}, "branches": {}},
+				{"nodeType": "question", "check": {"dataPath": "$.user.orders[", "operator": "exists"}, "branches": {}},
+				{"nodeType": "question", "check": {"dataPath": "user.zip", "operator": "isEmpty"}, "branches": {}}
+			]
+		}
+	}`))
+
+	require.Len(t, errs, 5)
+	assert.Equal(t, "/graph/sequence/0/check/expectedValue", errs[0].Pointer)
+	assert.Equal(t, "/graph/sequence/1/check/expectedValue", errs[1].Pointer)
+	assert.Equal(t, "/graph/sequence/2/check/expectedValue", errs[2].Pointer)
+	assert.Equal(t, "/graph/sequence/3/check/expectedValue", errs[3].Pointer)
+	assert.Equal(t, "/graph/sequence/4/check/dataPath", errs[4].Pointer)
+}
@@ -0,0 +1,111 @@
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"rpa-dfs-engine/internal/traverser"
+	"rpa-dfs-engine/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const checkpointWorkflow = `{
+	"graph": {
+		"nodeType": "moveToPage",
+		"url": "https://example.com/records",
+		"next": {
+			"nodeType": "forEach",
+			"dataSource": "{{user.records}}",
+			"questionText": "Submit {{user.records[iterator.index]}}?",
+			"next": {
+				"nodeType": "sequence",
+				"sequence": [
+					{"nodeType": "fillField", "selector": "#name", "value": "{{user.records[iterator.index]}}"},
+					{"nodeType": "clickButton", "selector": "#submit-{{iterator.index}}"}
+				]
+			}
+		}
+	},
+	"metadata": {"name": "records"}
+}`
+
+func runInterruptedRecords(t *testing.T, runsDir string) *traverser.Checkpoint {
+	t.Helper()
+
+	engine, browser := newTestEngine(t, checkpointWorkflow, map[string]interface{}{
+		"records": []interface{}{"a", "b", "c", "d"},
+	})
+	engine.SetIO(strings.NewReader("\nn\n\n"), &bytes.Buffer{})
+	engine.SetRunsDir(runsDir)
+	engine.SetRunID("run-1")
+	browser.FailSelector["#submit-2"] = errors.New("chrome crashed")
+
+	require.Error(t, engine.Execute())
+	assert.Equal(t, []string{
+		"navigate https://example.com/records",
+		"fill #name=a",
+		"click #submit-0",
+		"fill #name=c",
+	}, browser.Actions)
+
+	checkpoint, err := traverser.LoadCheckpoint(traverser.CheckpointPath(runsDir, "run-1"))
+	require.NoError(t, err)
+	return checkpoint
+}
+
+func TestEngineExecute_WithRunsDir_SavesCheckpointAfterLastCompletedAction(t *testing.T) {
+	checkpoint := runInterruptedRecords(t, t.TempDir())
+
+	assert.Equal(t, "run-1", checkpoint.RunID)
+	assert.Equal(t, "/graph/next/next/sequence/0", checkpoint.Node)
+	assert.Equal(t, "https://example.com/records", checkpoint.URL)
+	assert.Equal(t, []bool{true, false, true}, checkpoint.Decisions)
+	assert.Equal(t, map[string]interface{}{"index": float64(2), "count": float64(3), "total": float64(4), "item": "c"}, checkpoint.Iterator)
+}
+
+func TestEngineResume_AfterFailure_ContinuesWithoutResubmittingItems(t *testing.T) {
+	runsDir := t.TempDir()
+	checkpoint := runInterruptedRecords(t, runsDir)
+
+	workflow, err := traverser.ParseWorkflow([]byte(checkpointWorkflow))
+	require.NoError(t, err)
+	browser := mocks.NewMockWorkflowBrowser()
+	engine := traverser.NewEngine(browser)
+	engine.SetWorkflow(workflow)
+	engine.SetIO(strings.NewReader(""), &bytes.Buffer{})
+	engine.SetRunsDir(runsDir)
+
+	err = engine.Resume(checkpoint)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"navigate https://example.com/records",
+		"click #submit-2",
+		"fill #name=d",
+		"click #submit-3",
+	}, browser.Actions)
+	assert.Equal(t, "run-1", engine.Result().RunID)
+	assert.Equal(t, traverser.NodeReplayed, engine.Result().Nodes[0].Status)
+	_, err = os.Stat(traverser.CheckpointPath(runsDir, "run-1"))
+	assert.True(t, os.IsNotExist(err), "checkpoint should be removed after a successful run")
+}
+
+func TestEngineResume_WithChangedWorkflow_ReportsMismatch(t *testing.T) {
+	checkpoint := runInterruptedRecords(t, t.TempDir())
+
+	engine, _ := newTestEngine(t, `{
+		"graph": {"nodeType": "clickButton", "selector": "#a", "next": {"nodeType": "clickButton", "selector": "#b"}},
+		"metadata": {"name": "records"}
+	}`, nil)
+	checkpoint.Step = 2
+
+	err := engine.Resume(checkpoint)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checkpoint does not match workflow")
+}
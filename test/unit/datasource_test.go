@@ -0,0 +1,206 @@
+package unit
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// forEachFileWorkflow fills one record per row of the data source.
+func forEachFileWorkflow(source, extra string) string {
+	return `{
+		"graph": {
+			"nodeType": "forEach",
+			"dataSource": "` + filepath.ToSlash(source) + `",
+			` + extra + `
+			"next": {
+				"nodeType": "fillField",
+				"selector": "#email",
+				"value": "{{iterator.count}}:{{iterator.item.name}}:{{iterator.item.email}}"
+			}
+		}
+	}`
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestEngineExecute_WithCSVDataSource_MapsHeadersToItemFields(t *testing.T) {
+	path := writeFile(t, "people.csv", "\ufeffFull Name,E-mail\nAda Lovelace,ada@example.com\n\"Hopper, Grace\",grace@example.com\n")
+	engine, browser := newTestEngine(t, forEachFileWorkflow(path,
+		`"columns": {"Full Name": "name", "E-mail": "email"},`), nil)
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"fill #email=1:Ada Lovelace:ada@example.com",
+		"fill #email=2:Hopper, Grace:grace@example.com",
+	}, browser.Actions)
+}
+
+func TestEngineExecute_WithJSONLDataSourceFromContext_StreamsEachLine(t *testing.T) {
+	path := writeFile(t, "people.jsonl", `{"name": "Ada", "mail": "ada@example.com"}
+{"name": "Grace", "mail": "grace@example.com"}
+`)
+	engine, browser := newTestEngine(t, forEachFileWorkflow("{{user.importFile}}",
+		`"columns": {"mail": "email"},`), map[string]interface{}{"importFile": path})
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fill #email=1:Ada:ada@example.com", "fill #email=2:Grace:grace@example.com"}, browser.Actions)
+}
+
+func TestEngineExecute_WithXLSXDataSource_ReadsNamedSheet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "people.xlsx")
+	writeXLSX(t, path)
+	engine, browser := newTestEngine(t, forEachFileWorkflow(path, `"sheet": "People",`), nil)
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fill #email=1:Ada:ada@example.com", "fill #email=2:Grace:"}, browser.Actions)
+}
+
+func TestEngineExecute_WithXLSXDateCells_FormatsThemAsDates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "people.xlsx")
+	writeXLSXSheet(t, path, `<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy\-mm\-dd\ hh:mm;[Red]@"/></numFmts>
+<cellXfs count="4"><xf numFmtId="0"/><xf numFmtId="14"/><xf numFmtId="164"/><xf numFmtId="2"/></cellXfs>`,
+		`<row r="1"><c r="A1" t="inlineStr"><is><t>name</t></is></c><c r="B1" t="inlineStr"><is><t>email</t></is></c></row>
+<row r="2"><c r="A2" s="1"><v>45292</v></c><c r="B2" s="2"><v>45292.75</v></c></row>
+<row r="3"><c r="A3"><v>45292</v></c><c r="B3" s="3"><v>45292.5</v></c></row>`)
+	engine, browser := newTestEngine(t, forEachFileWorkflow(path, ""), nil)
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"fill #email=1:2024-01-01:2024-01-01 18:00:00",
+		"fill #email=2:45292:45292.5",
+	}, browser.Actions)
+}
+
+func TestEngineExecute_WithXLSXLowercaseCellReference_FailsInsteadOfPanicking(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "people.xlsx")
+	writeXLSXSheet(t, path, "",
+		`<row r="1"><c r="A1" t="inlineStr"><is><t>name</t></is></c></row>
+<row r="2"><c r="a2" t="inlineStr"><is><t>Ada</t></is></c></row>`)
+	engine, browser := newTestEngine(t, forEachFileWorkflow(path, ""), nil)
+
+	err := engine.Execute()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid cell reference "a2"`)
+	assert.Empty(t, browser.Actions)
+}
+
+func TestEngineExecute_WithRelativeDataSource_ResolvesItNextToTheWorkflow(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "people.csv"), []byte("name,email\nAda,ada@example.com\n"), 0o644))
+	path := writeWorkflowFile(t, dir, "import.json", forEachFileWorkflow("people.csv", ""))
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { os.Chdir(cwd) })
+
+	engine, browser := newFileEngine(t, path, nil)
+	err = engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fill #email=1:Ada:ada@example.com"}, browser.Actions)
+}
+
+func TestEngineExecute_WithAnswerPolicies_SkipsQuestions(t *testing.T) {
+	path := writeFile(t, "people.csv", "name,email\nAda,ada@example.com\nGrace,grace@example.com\n")
+	output := &bytes.Buffer{}
+
+	engine, browser := newTestEngine(t, forEachFileWorkflow(path,
+		`"questionText": "Process {{iterator.item.name}}?", "answer": "always",`), nil)
+	engine.SetIO(strings.NewReader("n\nn\n"), output)
+	require.NoError(t, engine.Execute())
+	assert.Len(t, browser.Actions, 2)
+	assert.Empty(t, output.String())
+
+	engine, browser = newTestEngine(t, forEachFileWorkflow(path, `"questionText": "Process {{iterator.item.name}}?",`), nil)
+	require.NoError(t, engine.SetAnswerPolicy(traverser.AnswerNever))
+	require.NoError(t, engine.Execute())
+	assert.Empty(t, browser.Actions)
+
+	assert.Error(t, engine.SetAnswerPolicy("sometimes"))
+}
+
+func TestEngineExecute_WithMissingDataFile_FailsWithPath(t *testing.T) {
+	engine, _ := newTestEngine(t, forEachFileWorkflow("missing/people.csv", ""), nil)
+
+	err := engine.Execute()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "data source missing/people.csv")
+}
+
+// writeXLSX writes a minimal workbook whose second sheet, People, uses shared and inline strings.
+func writeXLSX(t *testing.T, path string) {
+	t.Helper()
+	writeXLSXParts(t, path, map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Summary" sheetId="1" r:id="rId1"/><sheet name="People" sheetId="2" r:id="rId2"/></sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Target="worksheets/sheet2.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><si><t>name</t></si><si><t>email</t></si><si><r><t>Gr</t></r><r><t>ace</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData/></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2" t="inlineStr"><is><t>Ada</t></is></c><c r="B2" t="inlineStr"><is><t>ada@example.com</t></is></c></row>
+<row r="3"><c r="A3" t="s"><v>2</v></c></row>
+</sheetData></worksheet>`,
+	})
+}
+
+// writeXLSXSheet writes a workbook with one sheet holding rows and the cell styles in styles.
+func writeXLSXSheet(t *testing.T, path, styles, rows string) {
+	t.Helper()
+	writeXLSXParts(t, path, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/styles.xml":            `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` + styles + `</styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + rows + `</sheetData></worksheet>`,
+	})
+}
+
+func writeXLSXParts(t *testing.T, path string, parts map[string]string) {
+	t.Helper()
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	defer file.Close()
+	archive := zip.NewWriter(file)
+	for name, content := range parts {
+		w, err := archive.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+}
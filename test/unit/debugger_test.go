@@ -0,0 +1,103 @@
+package unit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const debugWorkflow = `{
+	"graph": {
+		"id": "open",
+		"nodeType": "moveToPage",
+		"url": "https://example.com",
+		"next": {
+			"id": "form",
+			"nodeType": "sequence",
+			"sequence": [
+				{"id": "email", "nodeType": "fillField", "selector": "#email", "value": "{{user.email}}"},
+				{"id": "name", "nodeType": "fillField", "selector": "#name", "value": "{{user.name}}"}
+			],
+			"next": {"id": "submit", "nodeType": "clickButton", "selector": "#submit"}
+		}
+	},
+	"metadata": {"name": "signup"}
+}`
+
+func runDebugSession(t *testing.T, breakpoints []string, commands string) (string, []string, error) {
+	t.Helper()
+
+	engine, browser := newTestEngine(t, debugWorkflow, map[string]interface{}{"email": "a@example.com", "name": "Ann"})
+	browser.Elements["#submit"] = traverser.SelectorInfo{Count: 1, Visible: true, Enabled: true, Tag: "button", Text: "Sign up"}
+	var output bytes.Buffer
+	engine.SetIO(strings.NewReader(commands), &output)
+	engine.SetDebugger(traverser.NewDebugger(breakpoints))
+
+	err := engine.Execute()
+	return output.String(), browser.Actions, err
+}
+
+func TestDebugger_WithBreakpointById_StopsAndEditsContext(t *testing.T) {
+	output, actions, err := runDebugSession(t, []string{"name"}, "p user.name\nset user.name \"Bob\"\nc\n")
+
+	require.NoError(t, err)
+	assert.Contains(t, output, "⏸  name (fillField) at signup#/graph/next/sequence/1")
+	assert.Contains(t, output, "value: {{user.name}} → Ann")
+	assert.Contains(t, output, `"Ann"`)
+	assert.Contains(t, output, "user.name = Bob")
+	assert.Equal(t, []string{
+		"navigate https://example.com",
+		"fill #email=a@example.com",
+		"fill #name=Bob",
+		"click #submit",
+	}, actions)
+}
+
+func TestDebugger_WithStepOver_SkipsSequenceItems(t *testing.T) {
+	output, _, err := runDebugSession(t, nil, "s\nn\nc\n")
+
+	require.NoError(t, err)
+	assert.Contains(t, output, "⏸  open (moveToPage)")
+	assert.Contains(t, output, "⏸  form (sequence)")
+	assert.NotContains(t, output, "⏸  email")
+	assert.Contains(t, output, "⏸  submit (clickButton)")
+}
+
+func TestDebugger_WithEvalAndQuit_InspectsSelectorAndStopsRun(t *testing.T) {
+	output, actions, err := runDebugSession(t, []string{"clickButton"}, "eval #submit\n$ #missing\nq\n")
+
+	require.ErrorIs(t, err, traverser.ErrDebugQuit)
+	assert.Contains(t, output, `#submit matches 1 element(s); first: <button> visible=true enabled=true text="Sign up"`)
+	assert.Contains(t, output, "#missing matches nothing")
+	assert.NotContains(t, actions, "click #submit")
+}
+
+func TestDebugger_WithRevealedSecret_MasksItOnTheConsole(t *testing.T) {
+	key := newSecretKey(t)
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "fillField", "selector": "#password", "value": "{{user.password}}",
+			"next": {
+				"nodeType": "evaluate", "script": "() => form.password", "storeAs": "vars.echo",
+				"next": {"id": "submit", "nodeType": "clickButton", "selector": "#submit"}
+			}
+		}
+	}`, secretContext(t, key, "hunter2"))
+	engine.SetSecretKey(key)
+	browser.Results["() => form.password"] = "hunter2"
+	var output bytes.Buffer
+	engine.SetIO(strings.NewReader("p vars\neval #{{user.password}}\nq\n"), &output)
+	engine.SetDebugger(traverser.NewDebugger([]string{"submit"}))
+
+	err := engine.Execute()
+
+	require.ErrorIs(t, err, traverser.ErrDebugQuit)
+	assert.Contains(t, output.String(), `"echo": "********"`)
+	assert.Contains(t, output.String(), "#******** matches nothing")
+	assert.NotContains(t, output.String(), "hunter2")
+}
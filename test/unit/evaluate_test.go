@@ -0,0 +1,107 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const chartScript = "async (chart, options) => window.charts[chart].select(options)"
+
+func TestEngineExecute_WithEvaluate_PassesResolvedArgsAndStoresResult(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "evaluate",
+			"script": "`+chartScript+`",
+			"args": ["sales-{{user.year}}", {"labels": "{{user.labels}}", "limit": 3}],
+			"storeAs": "vars.selection",
+			"next": {"nodeType": "fillField", "selector": "#total", "value": "{{vars.selection.total}}"}
+		}
+	}`, map[string]interface{}{"year": "2026", "labels": []interface{}{"Q1", "Q2\"); alert(1); //"}})
+	browser.Results[chartScript] = map[string]interface{}{"total": 1250.5}
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`evaluate ` + chartScript + ` ["sales-2026",{"labels":["Q1","Q2\"); alert(1); //"],"limit":3}]`,
+		"fill #total=1250.5",
+	}, browser.Actions)
+	assert.Equal(t, map[string]interface{}{"total": 1250.5}, engine.Result().Extracted["vars.selection"])
+}
+
+func TestEngineExecute_WithEvaluateInFrame_ResolvesFrameSelector(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {"nodeType": "evaluate", "frame": "iframe#{{user.frame}}", "script": "() => document.title"}
+	}`, map[string]interface{}{"frame": "payment"})
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"evaluate in iframe#payment () => document.title []"}, browser.Actions)
+}
+
+func TestEngineExecute_WithThrowingScript_FailsWithStack(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {"nodeType": "evaluate", "script": "() => widget.open()"}
+	}`, nil)
+	browser.FailSelector["() => widget.open()"] = &traverser.ScriptError{
+		Message: "ReferenceError: widget is not defined",
+		Stack:   "ReferenceError: widget is not defined\n    at <anonymous>:1:7",
+	}
+
+	err := engine.Execute()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "at <anonymous>:1:7")
+	assert.Equal(t, traverser.ErrorKindScript, engine.Result().Nodes[0].ErrorKind)
+}
+
+func TestEngineExecute_WithWrappedScriptError_IsNotReportedAsBrowserFailure(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {"nodeType": "evaluate", "script": "() => widget.open()"}
+	}`, nil)
+	browser.FailSelector["() => widget.open()"] = fmt.Errorf("frame main: %w", &traverser.ScriptError{
+		Message: "ReferenceError: widget is not defined",
+	})
+
+	err := engine.Execute()
+
+	var actionErr *traverser.ActionError
+	require.Error(t, err)
+	assert.False(t, errors.As(err, &actionErr), err.Error())
+	assert.Contains(t, err.Error(), "frame main: script threw ReferenceError")
+}
+
+func TestValidateWorkflow_WithEvaluate_RequiresScriptAndArrayArgs(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+		"graph": {"nodeType": "evaluate", "args": {"a": 1}, "storeAs": "user"}
+	}`))
+
+	messages := make([]string, len(errs))
+	for i, e := range errs {
+		messages[i] = e.Message
+	}
+	require.Len(t, errs, 3, messages)
+	assert.Contains(t, messages, "args must be an array")
+	assert.Contains(t, messages, `nodeType "evaluate" requires property "script"`)
+}
+
+func TestEngineExecute_WithEvaluateInDryRun_LeavesArgsOutOfPlan(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {"nodeType": "evaluate", "script": "(token) => login(token)", "args": ["{{user.token}}"], "storeAs": "vars.session"}
+	}`, map[string]interface{}{"token": "s3cr3t"})
+	engine.SetDryRun(true)
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Empty(t, browser.Actions)
+	actions := engine.Plan().Steps[0].Actions
+	assert.Equal(t, []string{"evaluate script in page with 1 args", "store vars.session"}, actions)
+}
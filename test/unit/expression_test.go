@@ -0,0 +1,129 @@
+package unit
+
+import (
+	"testing"
+
+	"rpa-dfs-engine/internal/expression"
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExpressionContext() *traverser.Context {
+	ctx := traverser.NewContext(map[string]interface{}{
+		"age":     float64(30),
+		"name":    "O'Brien",
+		"email":   "john@example.com",
+		"tags":    []interface{}{"vip", "beta"},
+		"profile": map[string]interface{}{"city": "Berlin"},
+		"phone":   nil,
+	})
+	ctx.SetIterator(1, 2)
+	return ctx
+}
+
+func evaluate(t *testing.T, source string) interface{} {
+	t.Helper()
+	expr, err := expression.Parse(source)
+	require.NoError(t, err)
+	value, err := expr.Evaluate(newExpressionContext())
+	require.NoError(t, err)
+	return value
+}
+
+func TestExpressionEvaluate_WithComparisons_ReturnsTypedResults(t *testing.T) {
+	assert.Equal(t, true, evaluate(t, "{{user.age}} > 18"))
+	assert.Equal(t, true, evaluate(t, "user.age >= 30 && user.age <= 30"))
+	assert.Equal(t, false, evaluate(t, "user.profile.city != 'Berlin'"))
+	assert.Equal(t, true, evaluate(t, `user.name == "O'Brien"`))
+	assert.Equal(t, true, evaluate(t, "iterator.index == 1"))
+}
+
+func TestExpressionEvaluate_WithBooleanOperators_ShortCircuits(t *testing.T) {
+	assert.Equal(t, true, evaluate(t, "not (user.age < 18) and (false or true)"))
+	assert.Equal(t, false, evaluate(t, "user.missing != null && user.missing.deep > 1"))
+	assert.Equal(t, true, evaluate(t, "!false || 1 > 'x'"))
+}
+
+func TestExpressionEvaluate_WithArithmetic_ComputesNumbers(t *testing.T) {
+	assert.Equal(t, float64(61), evaluate(t, "user.age * 2 + 1"))
+	assert.Equal(t, float64(1), evaluate(t, "-(user.age % 7) + 3"))
+	assert.Equal(t, "ab", evaluate(t, "'a' + 'b'"))
+}
+
+func TestExpressionEvaluate_WithStringFunctions_ReturnsResults(t *testing.T) {
+	assert.Equal(t, true, evaluate(t, "contains(user.email, '@example')"))
+	assert.Equal(t, true, evaluate(t, "contains(user.tags, 'vip')"))
+	assert.Equal(t, true, evaluate(t, "startsWith(user.email, 'john')"))
+	assert.Equal(t, true, evaluate(t, `matches(user.email, "^[a-z]+@")`))
+	assert.Equal(t, float64(2), evaluate(t, "len(user.tags)"))
+	assert.Equal(t, "vip", evaluate(t, "user.tags[iterator.index - 1]"))
+}
+
+func TestExpressionEvaluate_WithNullChecks_TreatsMissingAsNull(t *testing.T) {
+	assert.Equal(t, true, evaluate(t, "user.phone == null"))
+	assert.Equal(t, true, evaluate(t, "isNull(user.unknown.path)"))
+	assert.Equal(t, false, evaluate(t, "user.email == null"))
+}
+
+func TestExpressionEvaluate_WithMismatchedTypes_ReturnsError(t *testing.T) {
+	expr, err := expression.Parse("user.name > 5")
+	require.NoError(t, err)
+
+	_, err = expr.Evaluate(newExpressionContext())
+
+	var evalErr *expression.EvalError
+	require.ErrorAs(t, err, &evalErr)
+	assert.Equal(t, 11, evalErr.Column)
+	assert.Contains(t, evalErr.Message, "cannot compare string > number")
+}
+
+func TestExpressionParse_WithSyntaxError_ReportsColumn(t *testing.T) {
+	cases := map[string]int{
+		"user.age > ":             12,
+		"user.age >> 1":           11,
+		"contains(user.email)":    1,
+		"unknownFn(1)":            1,
+		"matches(user.name, '(')": 20,
+		"'unterminated":           1,
+		"{{user.age > 1":          15,
+	}
+
+	for source, column := range cases {
+		_, err := expression.Parse(source)
+
+		var parseErr *expression.ParseError
+		if assert.ErrorAs(t, err, &parseErr, source) {
+			assert.Equal(t, column, parseErr.Column, source)
+		}
+	}
+}
+
+func TestValidateWorkflow_WithInvalidConditionExpression_ReportsAtValidation(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "conditional",
+			"conditionExpression": "{{user.age}} >",
+			"branches": {}
+		}
+	}`))
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, "/graph/conditionExpression", errs[0].Pointer)
+	assert.Contains(t, errs[0].Message, "column 15")
+}
+
+func TestExpressionReferences_WithComputedIndex_ListsLiteralPaths(t *testing.T) {
+	expr, err := expression.Parse("{{user.items[iterator.index].price}} > 10 and contains(vars.name, 'x')")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"user.items", "iterator.index", "vars.name"}, expr.References())
+}
+
+func TestExpressionReferences_WithLiteralsOnly_IsEmpty(t *testing.T) {
+	expr, err := expression.Parse("1 + 2 > 2 and not false")
+	require.NoError(t, err)
+
+	assert.Empty(t, expr.References())
+}
@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"testing"
+
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineExecute_WithExtractText_StoresValueForLaterNodes(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "extractText",
+			"selector": ".confirmation",
+			"pattern": "Order #(\\d+)",
+			"storeAs": "vars.orderId",
+			"next": {
+				"nodeType": "conditional",
+				"conditionExpression": "vars.orderId != null",
+				"branches": {
+					"yes": {"nodeType": "moveToPage", "url": "https://example.com/orders/{{vars.orderId}}"}
+				}
+			}
+		}
+	}`, nil)
+	browser.Elements[".confirmation"] = traverser.SelectorInfo{Text: "Thank you! Order #10442 is confirmed."}
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"navigate https://example.com/orders/10442"}, browser.Actions)
+	assert.Equal(t, map[string]interface{}{"vars.orderId": "10442"}, engine.Result().Extracted)
+}
+
+func TestEngineExecute_WithExtractAttributeValueAndCount_StoresEachValue(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "extractAttribute",
+			"selector": "a.invoice",
+			"attribute": "href",
+			"storeAs": "vars.invoice.url",
+			"next": {
+				"nodeType": "extractValue",
+				"selector": "#email",
+				"storeAs": "vars.invoice.email",
+				"next": {
+					"nodeType": "extractCount",
+					"selector": ".line-item",
+					"storeAs": "vars.invoice.lines",
+					"next": {
+						"nodeType": "question",
+						"check": {"dataPath": "vars.invoice.lines", "operator": "greaterThan", "expectedValue": 2},
+						"branches": {"yes": {"nodeType": "clickButton", "selector": "#paginate"}}
+					}
+				}
+			}
+		}
+	}`, nil)
+	browser.Attributes["a.invoice@href"] = "/invoices/7.pdf"
+	browser.Values["#email"] = "ann@example.com"
+	browser.Elements[".line-item"] = traverser.SelectorInfo{Count: 3}
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	invoice, ok := engine.Context().Get("vars.invoice")
+	require.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"url": "/invoices/7.pdf", "email": "ann@example.com", "lines": 3}, invoice)
+	assert.Equal(t, []string{"click #paginate"}, browser.Actions)
+}
+
+func TestEngineExecute_WithMissingAttribute_FailsWithBrowserError(t *testing.T) {
+	engine, _ := newTestEngine(t, `{
+		"graph": {"nodeType": "extractAttribute", "selector": "a", "attribute": "href", "storeAs": "vars.link"}
+	}`, nil)
+
+	err := engine.Execute()
+
+	var actionErr *traverser.ActionError
+	require.ErrorAs(t, err, &actionErr)
+	assert.Equal(t, "extractAttribute", actionErr.Action)
+	assert.Equal(t, traverser.ErrorKindBrowser, traverser.ErrorKind(err))
+}
+
+func TestValidateWorkflow_WithInvalidStoreAsAndPattern_ReportsErrors(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "extractText",
+			"selector": "h1",
+			"storeAs": "iterator.title",
+			"pattern": "(",
+			"next": {"nodeType": "extractCount", "selector": "li", "storeAs": "count"}
+		}
+	}`))
+
+	require.Len(t, errs, 3)
+	assert.Equal(t, "/graph/next/storeAs", errs[0].Pointer)
+	assert.Contains(t, errs[0].Message, "must name a scope and a key")
+	assert.Equal(t, "/graph/pattern", errs[1].Pointer)
+	assert.Equal(t, "/graph/storeAs", errs[2].Pointer)
+	assert.Contains(t, errs[2].Message, "read-only iterator scope")
+}
@@ -0,0 +1,116 @@
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"rpa-dfs-engine/internal/traverser"
+	"rpa-dfs-engine/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const graphWorkflow = `{
+	"graph": {
+		"nodeType": "moveToPage",
+		"id": "open",
+		"url": "https://example.com/login",
+		"next": {
+			"nodeType": "conditional",
+			"id": "adult",
+			"conditionExpression": "user.age > 18",
+			"branches": {
+				"yes": {"nodeType": "clickButton", "id": "accept", "selector": "#accept"},
+				"noId": "open"
+			},
+			"next": {
+				"nodeType": "fillField",
+				"id": "email",
+				"selector": "input[name=\"email\"]",
+				"value": "{{user.email}}",
+				"onError": {"nodeType": "clickButton", "selector": "#skip"}
+			}
+		}
+	},
+	"metadata": {"name": "graph"}
+}`
+
+func parseGraphWorkflow(t *testing.T) *traverser.Workflow {
+	t.Helper()
+
+	workflow, err := traverser.ParseWorkflow([]byte(graphWorkflow))
+	require.NoError(t, err)
+	return workflow
+}
+
+func TestRenderGraph_WithMermaid_DrawsNodesAndLabelledBranches(t *testing.T) {
+	diagram, err := parseGraphWorkflow(t).RenderGraph(traverser.GraphMermaid, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, `flowchart TD
+  n1["open · moveToPage<br/>https://example.com/login"]
+  n2{"adult · conditional<br/>user.age > 18"}
+  n3["accept · clickButton<br/>#accept"]
+  n4["email · fillField<br/>input[name=#quot;email#quot;]"]
+  n5["clickButton<br/>#skip"]
+  n1 --> n2
+  n2 -->|yes| n3
+  n2 -->|then| n4
+  n4 -.->|onError| n5
+  n2 -.->|no| n1
+`, diagram)
+}
+
+func TestRenderGraph_WithDOT_DrawsDigraph(t *testing.T) {
+	diagram, err := parseGraphWorkflow(t).RenderGraph(traverser.GraphDOT, nil)
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(diagram, `digraph "graph" {`))
+	assert.Contains(t, diagram, `n2 [label="adult · conditional\nuser.age > 18", shape=diamond];`)
+	assert.Contains(t, diagram, `n4 [label="email · fillField\ninput[name=\"email\"]"];`)
+	assert.Contains(t, diagram, `n2 -> n3 [label="yes"];`)
+	assert.Contains(t, diagram, `n2 -> n1 [label="no", style=dashed];`)
+}
+
+func TestRenderGraph_WithUnknownFormat_ReturnsError(t *testing.T) {
+	_, err := parseGraphWorkflow(t).RenderGraph("svg", nil)
+
+	assert.Error(t, err)
+}
+
+func TestRenderGraph_WithRun_OverlaysExecutedPathAndFailures(t *testing.T) {
+	workflow := parseGraphWorkflow(t)
+	browser := mocks.NewMockWorkflowBrowser()
+	browser.FailSelector[`input[name="email"]`] = errors.New("element is detached")
+	engine := traverser.NewEngine(browser)
+	engine.SetWorkflow(workflow)
+	engine.SetContext(map[string]interface{}{"age": 30, "email": "a@b.c"})
+	engine.SetIO(strings.NewReader(""), &bytes.Buffer{})
+	require.NoError(t, engine.Execute())
+
+	diagram, err := workflow.RenderGraph(traverser.GraphMermaid, engine.Result())
+
+	require.NoError(t, err)
+	assert.Contains(t, diagram, `n4["email · fillField<br/>input[name=#quot;email#quot;]<br/>❌ browser: fillField input[name=#quot;email#quot;]: element…"]`)
+	assert.Contains(t, diagram, "  class n1,n2,n3,n5 executed\n")
+	assert.Contains(t, diagram, "  class n4 recovered\n")
+	// Every edge except the jump back to "open" was followed.
+	assert.Contains(t, diagram, "  linkStyle 0,1,2,3 stroke:")
+}
+
+func TestRenderGraph_WithResultWithoutPointers_MatchesNodesByID(t *testing.T) {
+	run := &traverser.RunResult{Workflow: "graph", Nodes: []traverser.NodeResult{
+		{Workflow: "graph", Node: "open", Status: traverser.NodeSucceeded},
+		{Workflow: "graph", Node: "adult", Status: traverser.NodeFailed, ErrorKind: "data", Error: "age missing"},
+	}}
+
+	diagram, err := parseGraphWorkflow(t).RenderGraph(traverser.GraphDOT, run)
+
+	require.NoError(t, err)
+	assert.Contains(t, diagram, `n1 [label="open · moveToPage\nhttps://example.com/login", style=filled, fillcolor="#d4edda"`)
+	assert.Contains(t, diagram, `❌ data: age missing", shape=diamond, style=filled, fillcolor="#f8d7da"`)
+	assert.Contains(t, diagram, `n1 -> n2 [color="#28a745", penwidth=2];`)
+}
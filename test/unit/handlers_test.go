@@ -2,10 +2,15 @@ package unit
 
 import (
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"rpa-dfs-engine/internal/handlers"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 
@@ -218,3 +223,42 @@ func TestArgLength_WithInsufficientArgs_DetectsInvalid(t *testing.T) {
 
 	assert.False(t, hasSufficientArgs)
 }
+
+func TestRunHandler_WithUnknownFlag_FailsBeforeRunning(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workflow.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"graph": {"nodeType": "clickButton", "selector": "#go"}}`), 0o644))
+
+	err := handlers.NewRunHandler([]string{"--dryrun", path}).Execute()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "flag provided but not defined: -dryrun")
+}
+
+func TestRunHandler_WithHelpFlag_ExitsCleanly(t *testing.T) {
+	assert.NoError(t, handlers.NewRunHandler([]string{"-h"}).Execute())
+}
+
+func TestProcessHandler_WithWorkflowPathInQuery_RefusesToRun(t *testing.T) {
+	query := url.Values{"email": {"ada@example.com"}, "token": {"t0k3n"}, "workflow": {"/tmp/evil.json"}}
+
+	err := handlers.NewProcessHandler(query).Execute()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid workflow name")
+}
+
+func TestTestHandler_WithWorkflowPathInQuery_RefusesToRun(t *testing.T) {
+	query := url.Values{"isTest": {"true"}, "workflow": {"../../home/ada/workflow.json"}}
+
+	err := handlers.NewTestHandler(query).Execute()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid workflow name")
+}
+
+func TestSecretHandler_WithPlaintextArgument_RefusesToEncrypt(t *testing.T) {
+	err := handlers.NewSecretHandler([]string{"encrypt", "hunter2"}).Execute()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reads the value from standard input")
+}
@@ -0,0 +1,263 @@
+package unit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func issueRules(issues []traverser.LintIssue) []string {
+	var rules []string
+	for _, issue := range issues {
+		rules = append(rules, issue.Rule)
+	}
+	return rules
+}
+
+func TestLintWorkflow_WithDuplicateID_ReportsSecondDeclaration(t *testing.T) {
+	issues, err := traverser.LintWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "sequence",
+			"sequence": [
+				{"nodeType": "wait", "id": "pause", "duration": 1},
+				{"nodeType": "wait", "id": "pause", "duration": 2}
+			]
+		}
+	}`), nil, nil)
+
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "duplicate-id", issues[0].Rule)
+	assert.Equal(t, traverser.LintError, issues[0].Severity)
+	assert.Equal(t, "/graph/sequence/1/id", issues[0].Pointer)
+	assert.Equal(t, 6, issues[0].Line)
+	assert.Contains(t, issues[0].Message, "first declared on line 5")
+}
+
+func TestLintWorkflow_WithConditionalWithoutNo_ReportsMissingBranch(t *testing.T) {
+	issues, err := traverser.LintWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "conditional",
+			"id": "adult",
+			"conditionExpression": "user.age > 18",
+			"branches": {"yes": {"nodeType": "wait", "duration": 1}}
+		}
+	}`), nil, nil)
+
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "missing-no-branch", issues[0].Rule)
+	assert.Equal(t, "/graph/branches", issues[0].Pointer)
+}
+
+func TestLintWorkflow_WithNoIdJump_AcceptsBranch(t *testing.T) {
+	issues, err := traverser.LintWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "conditional",
+			"id": "adult",
+			"conditionExpression": "user.age > 18",
+			"branches": {"yes": {"nodeType": "wait", "duration": 1}, "noId": "adult"}
+		}
+	}`), nil, nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestLintWorkflow_WithConstantCondition_ReportsUnreachableBranch(t *testing.T) {
+	issues, err := traverser.LintWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "conditional",
+			"conditionExpression": "1 > 2",
+			"branches": {
+				"yes": {"nodeType": "wait", "duration": 1},
+				"no": {"nodeType": "wait", "duration": 2}
+			}
+		}
+	}`), nil, nil)
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"unreachable-node"}, issueRules(issues))
+	assert.Equal(t, "/graph/branches/yes", issues[0].Pointer)
+	assert.Contains(t, issues[0].Message, "always false")
+}
+
+func TestLintWorkflow_WithBothBranchJumps_ReportsUnreachableNext(t *testing.T) {
+	issues, err := traverser.LintWorkflow([]byte(`{
+		"graph": {
+			"id": "start",
+			"nodeType": "question",
+			"check": {"dataPath": "user.retry", "operator": "exists"},
+			"branches": {"yesId": "start", "noId": "done"},
+			"next": {
+				"nodeType": "clickButton",
+				"selector": "#never",
+				"next": {"id": "done", "nodeType": "wait", "duration": 1}
+			}
+		}
+	}`), nil, nil)
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"unreachable-node"}, issueRules(issues))
+	assert.Equal(t, "/graph/next", issues[0].Pointer)
+	assert.Contains(t, issues[0].Message, "every branch it takes jumps by id")
+}
+
+func TestLintWorkflow_WithConstantConditionJumping_ReportsUnreachableNext(t *testing.T) {
+	issues, err := traverser.LintWorkflow([]byte(`{
+		"graph": {
+			"id": "start",
+			"nodeType": "conditional",
+			"conditionExpression": "true",
+			"branches": {"yesId": "start", "no": {"nodeType": "wait", "duration": 2}},
+			"next": {"nodeType": "wait", "duration": 1}
+		}
+	}`), nil, nil)
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"unreachable-node", "unreachable-node"}, issueRules(issues))
+	assert.Equal(t, "/graph/branches/no", issues[0].Pointer)
+	assert.Equal(t, "/graph/next", issues[1].Pointer)
+}
+
+func TestLintWorkflow_WithContext_ReportsUnknownUserKeys(t *testing.T) {
+	user := map[string]interface{}{
+		"email": "a@b.c",
+		"files": []interface{}{map[string]interface{}{"path": "a.pdf"}},
+	}
+	issues, err := traverser.LintWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "sequence",
+			"sequence": [
+				{"nodeType": "fillField", "selector": "#email", "value": "{{user.email}}"},
+				{"nodeType": "fillField", "selector": "#phone", "value": "{{user.phone}}"},
+				{"nodeType": "fillField", "selector": "#city", "value": "{{user.city | default:\"Paris\"}}"},
+				{"nodeType": "sendFile", "selector": "#file", "filePath": "{{user.files[0].path}}"},
+				{"nodeType": "extractText", "selector": "#order", "storeAs": "user.order.id"},
+				{"nodeType": "conditional", "conditionExpression": "user.order.id != null and user.age > 18",
+					"branches": {"yes": {"nodeType": "wait", "duration": 1}, "no": {"nodeType": "wait", "duration": 1}}}
+			]
+		}
+	}`), nil, user)
+
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, "/graph/sequence/1/value", issues[0].Pointer)
+	assert.Equal(t, "user.phone is not provided by the context", issues[0].Message)
+	assert.Equal(t, "/graph/sequence/5/conditionExpression", issues[1].Pointer)
+	assert.Contains(t, issues[1].Message, "user.age")
+}
+
+func TestLintWorkflow_WithoutContext_SkipsReferences(t *testing.T) {
+	issues, err := traverser.LintWorkflow([]byte(`{
+		"graph": {"nodeType": "fillField", "selector": "#phone", "value": "{{user.phone}}"}
+	}`), nil, nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestLintWorkflow_WithFragileSelectors_ReportsEach(t *testing.T) {
+	fragile := []string{
+		"div > div:nth-child(7)",
+		"ul li:nth-of-type(2) a",
+		"#app > main > form > div > input",
+		".css-1dbjc4n",
+		".Button_primary__3xK9a",
+		"#ember123",
+	}
+	stable := []string{
+		"#email",
+		"button[type='submit']",
+		"[data-testid='login > submit']",
+		"form.login > .actions > button",
+		".btn-primary2",
+		"input[name=\"{{iterator.item.field}}\"]",
+	}
+
+	for _, selector := range fragile {
+		issues, err := traverser.LintWorkflow(clickWorkflow(selector), nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"fragile-selector"}, issueRules(issues), selector)
+	}
+	for _, selector := range stable {
+		issues, err := traverser.LintWorkflow(clickWorkflow(selector), nil, nil)
+		require.NoError(t, err)
+		assert.Empty(t, issues, selector)
+	}
+}
+
+func clickWorkflow(selector string) []byte {
+	quoted, _ := json.Marshal(selector)
+	return []byte(`{"graph": {"nodeType": "clickButton", "selector": ` + string(quoted) + `}}`)
+}
+
+func TestLintWorkflow_WithConfig_OverridesSeverities(t *testing.T) {
+	config := &traverser.LintConfig{Rules: map[string]string{
+		"fragile-selector": traverser.LintError,
+		"duplicate-id":     traverser.LintOff,
+	}}
+	issues, err := traverser.LintWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "clickButton",
+			"id": "go",
+			"selector": "li:nth-child(2)",
+			"next": {"nodeType": "wait", "id": "go", "duration": 1}
+		}
+	}`), config, nil)
+
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "fragile-selector", issues[0].Rule)
+	assert.Equal(t, traverser.LintError, issues[0].Severity)
+}
+
+func TestLoadLintConfig_WithUnknownRule_ReturnsError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lint.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("rules:\n  fragile-selectors: error\n"), 0o644))
+
+	_, err := traverser.LoadLintConfig(path)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown lint rule "fragile-selectors"`)
+}
+
+func TestLoadLintConfig_WithInvalidSeverity_ReturnsError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lint.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules": {"duplicate-id": "fatal"}}`), 0o644))
+
+	_, err := traverser.LoadLintConfig(path)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "severity must be one of")
+}
+
+func TestLintWorkflowYAML_WithDuplicateID_ReportsYAMLLine(t *testing.T) {
+	issues, err := traverser.LintWorkflowYAML([]byte(`graph:
+  nodeType: sequence
+  sequence:
+    - nodeType: wait
+      id: pause
+      duration: 1
+    - nodeType: wait
+      id: pause
+      duration: 2
+`), nil, nil)
+
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, 8, issues[0].Line)
+	assert.Contains(t, issues[0].Message, "first declared on line 5")
+}
+
+func TestLintWorkflow_WithInvalidJSON_ReturnsError(t *testing.T) {
+	_, err := traverser.LintWorkflow([]byte(`{"graph": `), nil, nil)
+
+	assert.Error(t, err)
+}
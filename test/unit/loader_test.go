@@ -0,0 +1,81 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeContextFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadContext_WithAllLayers_AppliesThemInOrder(t *testing.T) {
+	dir := t.TempDir()
+	base := writeContextFile(t, dir, "user.json", `{"user": {
+		"email": "ada@example.com",
+		"profile": {"city": "Berlin", "zip": "10115"},
+		"tags": ["a", "b"]
+	}}`)
+	staging := writeContextFile(t, dir, "user.staging.json", `{"user": {"email": "qa@example.com", "profile": {"city": "Hamburg"}}}`)
+
+	loaded, err := traverser.LoadContext(traverser.ContextSources{
+		Context: base,
+		Env:     "staging",
+		EnvMap:  map[string]string{"USER_": "user"},
+		Environ: []string{"USER_FIRST_NAME=Ada", "USER_PROFILE__ZIP=20095", "USER=root", "HOME=/root"},
+		Sets:    []string{"user.tags=[\"x\"]", "user.email=ops@example.com"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"email":     "ops@example.com",
+		"firstName": "Ada",
+		"profile":   map[string]interface{}{"city": "Hamburg", "zip": "20095"},
+		"tags":      []interface{}{"x"},
+	}, loaded.User)
+	assert.Equal(t, map[string]string{
+		"user.email":        "--set",
+		"user.firstName":    "env USER_FIRST_NAME",
+		"user.profile.city": staging,
+		"user.profile.zip":  "env USER_PROFILE__ZIP",
+		"user.tags":         "--set",
+	}, loaded.Sources)
+}
+
+func TestLoadContext_WithInlineJSONReplacedBySet_DropsNestedSources(t *testing.T) {
+	loaded, err := traverser.LoadContext(traverser.ContextSources{
+		Context: `{"user": {"address": {"city": "Berlin", "street": "Main"}, "retries": 1}}`,
+		Sets:    []string{"user.address=unknown", "user.retries=3"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "unknown", loaded.User["address"])
+	assert.Equal(t, float64(3), loaded.User["retries"])
+	assert.Equal(t, []string{"user.address", "user.retries"}, loaded.Paths())
+}
+
+func TestLoadContext_WithSetOutsideUser_Fails(t *testing.T) {
+	_, err := traverser.LoadContext(traverser.ContextSources{Sets: []string{"vars.token=abc"}})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only user.* paths")
+}
+
+func TestLoadContext_WithEnvAndInlineContext_Fails(t *testing.T) {
+	_, err := traverser.LoadContext(traverser.ContextSources{Context: `{"user": {}}`, Env: "prod"})
+
+	assert.Error(t, err)
+}
+
+func TestEnvContextFile_WithYAMLBase_KeepsExtension(t *testing.T) {
+	assert.Equal(t, "contexts/user.prod.yaml", traverser.EnvContextFile("contexts/user.yaml", "prod"))
+}
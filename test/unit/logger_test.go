@@ -7,6 +7,8 @@ import (
 	"testing"
 	"time"
 
+	"rpa-dfs-engine/internal/logger"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -143,3 +145,15 @@ func TestLogEntryLength_WithLongMessage_HandlesAppropriately(t *testing.T) {
 	assert.Contains(t, logEntry, prefix)
 	assert.Contains(t, logEntry, longMessage)
 }
+
+func TestRedactArgs_WithSetOverridesAndSecretEncrypt_MasksValues(t *testing.T) {
+	assert.Equal(t,
+		[]string{"rpa", "run", "--set", "user.password=********", "--set=user.pin=********", "workflow.json"},
+		logger.RedactArgs([]string{"rpa", "run", "--set", "user.password=hunter2", "--set=user.pin=1234", "workflow.json"}))
+	assert.Equal(t,
+		[]string{"rpa", "secret", "encrypt", "--key", "secret.key", "********"},
+		logger.RedactArgs([]string{"rpa", "secret", "encrypt", "--key", "secret.key", "hunter2"}))
+	assert.Equal(t,
+		[]string{"rpa", "secret", "rotate", "--new-key", "new.key", "user.json"},
+		logger.RedactArgs([]string{"rpa", "secret", "rotate", "--new-key", "new.key", "user.json"}))
+}
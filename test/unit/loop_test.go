@@ -0,0 +1,175 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"rpa-dfs-engine/internal/traverser"
+	"rpa-dfs-engine/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineExecute_WithWhileExpression_ExposesIterationCount(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "sequence",
+			"sequence": [
+				{
+					"nodeType": "while",
+					"conditionExpression": "iterator.count <= user.pages",
+					"maxIterations": 10,
+					"next": {"nodeType": "fillField", "selector": "#page", "value": "{{iterator.count}}"}
+				},
+				{"nodeType": "clickButton", "selector": "#done"}
+			]
+		}
+	}`, map[string]interface{}{"pages": float64(3)})
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fill #page=1", "fill #page=2", "fill #page=3", "click #done"}, browser.Actions)
+}
+
+func TestEngineExecute_WithUntilElementVisible_RepeatsBodyUntilItAppears(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "until",
+			"selector": "#ready",
+			"maxIterations": 5,
+			"next": {"nodeType": "clickButton", "selector": "#refresh"}
+		}
+	}`, map[string]interface{}{})
+	browser.Elements["#ready"] = traverser.SelectorInfo{Visible: true}
+	browser.FailSelector["#ready"] = traverser.ErrElementNotFound
+	browser.FailTimes["#ready"] = 2
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"click #refresh", "click #refresh"}, browser.Actions)
+}
+
+func TestEngineExecute_WithUntilTextMatches_StopsOnMatchingText(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "until",
+			"selector": "#status",
+			"expected": "^ready$",
+			"match": "matches",
+			"maxIterations": 5,
+			"next": {"nodeType": "clickButton", "selector": "#refresh"}
+		}
+	}`, map[string]interface{}{})
+	browser.Elements["#status"] = traverser.SelectorInfo{Text: " ready "}
+	browser.FailSelector["#status"] = traverser.ErrElementNotFound
+	browser.FailTimes["#status"] = 1
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"click #refresh"}, browser.Actions)
+}
+
+// timedBrowser records when the loop condition is checked and when the body clicks.
+type timedBrowser struct {
+	*mocks.MockWorkflowBrowser
+	checks []time.Time
+	clicks []time.Time
+}
+
+func (b *timedBrowser) Visible(selector string) (bool, error) {
+	b.checks = append(b.checks, time.Now())
+	return b.MockWorkflowBrowser.Visible(selector)
+}
+
+func (b *timedBrowser) ClickButton(selector string) error {
+	b.clicks = append(b.clicks, time.Now())
+	return b.MockWorkflowBrowser.ClickButton(selector)
+}
+
+func TestEngineExecute_WithLoopDelay_WaitsAfterBodyBeforeNextCheck(t *testing.T) {
+	workflow, err := traverser.ParseWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "until",
+			"selector": "#ready",
+			"maxIterations": 5,
+			"delay": 100,
+			"next": {"nodeType": "clickButton", "selector": "#refresh"}
+		}
+	}`))
+	require.NoError(t, err)
+	browser := &timedBrowser{MockWorkflowBrowser: mocks.NewMockWorkflowBrowser()}
+	browser.Elements["#ready"] = traverser.SelectorInfo{Visible: true}
+	browser.FailSelector["#ready"] = traverser.ErrElementNotFound
+	browser.FailTimes["#ready"] = 1
+	engine := traverser.NewEngine(browser)
+	engine.SetWorkflow(workflow)
+	engine.SetContext(map[string]interface{}{})
+
+	require.NoError(t, engine.Execute())
+
+	require.Len(t, browser.clicks, 1)
+	require.Len(t, browser.checks, 2)
+	assert.GreaterOrEqual(t, browser.checks[1].Sub(browser.clicks[0]), 100*time.Millisecond)
+}
+
+func TestEngineExecute_WithUntilScriptTemplate_PassesValueAsArgument(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "until",
+			"script": "window.status === {{user.status}}",
+			"maxIterations": 3,
+			"next": {"nodeType": "clickButton", "selector": "#refresh"}
+		}
+	}`, map[string]interface{}{"status": "done')"})
+	browser.Scripts["window.status === args[0]"] = true
+
+	require.NoError(t, engine.Execute())
+	assert.Empty(t, browser.Actions)
+	assert.Equal(t, []interface{}{"done')"}, browser.ScriptArgs["window.status === args[0]"])
+}
+
+func TestEngineExecute_WithWhileBeyondMaxIterations_FailsWithLoopLimit(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "while",
+			"selector": "#next",
+			"maxIterations": 3,
+			"next": {"nodeType": "clickButton", "selector": "#next"}
+		}
+	}`, map[string]interface{}{})
+	browser.Elements["#next"] = traverser.SelectorInfo{Visible: true}
+
+	err := engine.Execute()
+
+	var limitErr *traverser.LoopLimitError
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, 3, limitErr.MaxIterations)
+	assert.Len(t, browser.Actions, 3)
+}
+
+func TestValidateWorkflow_WithMalformedLoops_ReportsEachLoop(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "sequence",
+			"sequence": [
+				{"nodeType": "while", "conditionExpression": "true"},
+				{"nodeType": "until", "selector": "#a", "script": "done()", "maxIterations": 2},
+				{"nodeType": "until", "expected": "Ready", "urlPattern": "/done", "maxIterations": 2},
+				{"nodeType": "while", "selector": "#a", "maxIterations": 0},
+				{"nodeType": "until", "selector": "#a", "expected": "Ready", "maxIterations": 2, "delay": 500}
+			]
+		}
+	}`))
+
+	require.Len(t, errs, 4)
+	assert.Equal(t, "/graph/sequence/0", errs[0].Pointer)
+	assert.Contains(t, errs[0].Message, `requires property "maxIterations"`)
+	assert.Contains(t, errs[1].Message, "only one condition")
+	assert.Equal(t, "/graph/sequence/2/expected", errs[2].Pointer)
+	assert.Equal(t, "/graph/sequence/3/maxIterations", errs[3].Pointer)
+}
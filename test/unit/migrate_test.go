@@ -0,0 +1,194 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaVersion_WithoutDeclaration_ReturnsOne(t *testing.T) {
+	version, err := traverser.SchemaVersion([]byte(`{"graph": {"nodeType": "wait", "duration": 1}}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+}
+
+func TestMigrateWorkflow_WithUnversionedDocument_DeclaresCurrentVersionKeepingKeyOrder(t *testing.T) {
+	migration, err := traverser.MigrateWorkflow([]byte(`{
+  "graph": {"nodeType": "clickButton", "selector": "div > button[data-a='1&2']"},
+  "metadata": {"name": "Demo", "version": "1.0.0"}
+}`))
+
+	require.NoError(t, err)
+	assert.True(t, migration.Changed())
+	assert.Equal(t, 1, migration.From)
+	assert.Equal(t, traverser.CurrentSchemaVersion, migration.To)
+	assert.Equal(t, `{
+  "graph": {
+    "nodeType": "clickButton",
+    "selector": "div > button[data-a='1&2']"
+  },
+  "metadata": {
+    "name": "Demo",
+    "version": "1.0.0",
+    "schemaVersion": 1
+  }
+}
+`, string(migration.Data))
+	assert.Nil(t, traverser.ValidateWorkflow(migration.Data))
+}
+
+func TestMigrateWorkflow_WithoutMetadata_AddsIt(t *testing.T) {
+	migration, err := traverser.MigrateWorkflow([]byte(`{"graph": {"nodeType": "wait", "duration": 1}}`))
+
+	require.NoError(t, err)
+	version, err := traverser.SchemaVersion(migration.Data)
+	require.NoError(t, err)
+	assert.Equal(t, traverser.CurrentSchemaVersion, version)
+	assert.Contains(t, string(migration.Data), `"metadata": {`)
+}
+
+func TestMigrateWorkflow_WithCurrentVersion_LeavesDocumentUntouched(t *testing.T) {
+	data := []byte(`{"graph": {"nodeType": "wait", "duration": 1}, "metadata": {"schemaVersion": 1}}`)
+
+	migration, err := traverser.MigrateWorkflow(data)
+
+	require.NoError(t, err)
+	assert.False(t, migration.Changed())
+	assert.Empty(t, migration.Applied)
+	assert.Equal(t, data, migration.Data)
+}
+
+func TestMigrateWorkflow_WithNewerVersion_ReturnsSchemaVersionError(t *testing.T) {
+	_, err := traverser.MigrateWorkflow([]byte(`{"graph": {"nodeType": "wait", "duration": 1}, "metadata": {"schemaVersion": 99}}`))
+
+	var versionErr *traverser.SchemaVersionError
+	require.True(t, errors.As(err, &versionErr))
+	assert.Equal(t, 99, versionErr.Version)
+}
+
+func TestParseWorkflow_WithNewerVersion_RefusesDocument(t *testing.T) {
+	_, err := traverser.ParseWorkflow([]byte(`{
+		"graph": {"nodeType": "teleport"},
+		"metadata": {"schemaVersion": 2}
+	}`))
+
+	var versionErr *traverser.SchemaVersionError
+	require.True(t, errors.As(err, &versionErr))
+	assert.Contains(t, err.Error(), "newer than this build supports")
+}
+
+func TestParseWorkflow_WithUnversionedDocument_ReportsCurrentVersion(t *testing.T) {
+	workflow, err := traverser.ParseWorkflow([]byte(`{"graph": {"nodeType": "wait", "duration": 1}}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, traverser.CurrentSchemaVersion, workflow.Metadata.SchemaVersion)
+}
+
+func TestValidateWorkflow_WithNewerVersion_ReportsOnlyTheVersion(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+  "graph": {"nodeType": "teleport"},
+  "metadata": {"schemaVersion": 7}
+}`))
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, "/metadata/schemaVersion", errs[0].Pointer)
+	assert.Equal(t, 3, errs[0].Line)
+}
+
+func TestValidateWorkflow_WithInvalidSchemaVersion_ReportsError(t *testing.T) {
+	for _, version := range []string{`"1"`, `0`, `1.5`} {
+		errs := traverser.ValidateWorkflow([]byte(`{"graph": {"nodeType": "wait", "duration": 1}, "metadata": {"schemaVersion": ` + version + `}}`))
+
+		require.Len(t, errs, 1, version)
+		assert.Equal(t, "/metadata/schemaVersion", errs[0].Pointer)
+		assert.Contains(t, errs[0].Message, "positive integer")
+	}
+}
+
+// useFixtureMigrations replaces the upgrade chain with a made-up schema 3 for the test:
+// version 2 renamed the "sleep" node to "wait", version 3 requires a workflow name.
+func useFixtureMigrations(t *testing.T) {
+	t.Helper()
+	restore, err := traverser.SetMigrations([]traverser.MigrationStep{
+		{From: 1, Description: "rename sleep to wait", Apply: func(doc *traverser.Object) error {
+			graph, ok := doc.Get("graph").(*traverser.Object)
+			if !ok {
+				return errors.New("graph is not an object")
+			}
+			if graph.Get("nodeType") == "sleep" {
+				graph.Set("nodeType", "wait")
+			}
+			return nil
+		}},
+		{From: 2, Description: "name the workflow", Apply: func(doc *traverser.Object) error {
+			if metadata, ok := doc.Get("metadata").(*traverser.Object); ok && !metadata.Has("name") {
+				metadata.Set("name", "unnamed")
+			}
+			return nil
+		}},
+	}, 3)
+	require.NoError(t, err)
+	t.Cleanup(restore)
+}
+
+func TestLoadWorkflow_WithOldSchemaVersion_RunsEveryMigrationStep(t *testing.T) {
+	useFixtureMigrations(t)
+	path := writeWorkflowFile(t, t.TempDir(), "old.json", `{
+		"graph": {"nodeType": "sleep", "duration": 1},
+		"metadata": {"schemaVersion": 1}
+	}`)
+
+	workflow, err := traverser.LoadWorkflow(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, traverser.NodeTypeWait, workflow.Graph.NodeType)
+	assert.Equal(t, "unnamed", workflow.Metadata.Name)
+	assert.Equal(t, 3, workflow.Metadata.SchemaVersion)
+}
+
+func TestMigrateWorkflow_FromIntermediateVersion_SkipsEarlierSteps(t *testing.T) {
+	useFixtureMigrations(t)
+
+	migration, err := traverser.MigrateWorkflow([]byte(`{"graph": {"nodeType": "sleep"}, "metadata": {"schemaVersion": 2}}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, migration.From)
+	assert.Equal(t, 3, migration.To)
+	assert.Equal(t, []string{"2 → 3: name the workflow"}, migration.Applied)
+	assert.Contains(t, string(migration.Data), `"nodeType": "sleep"`)
+	assert.Contains(t, string(migration.Data), `"schemaVersion": 3`)
+}
+
+func TestMigrateWorkflow_WithFixtureChainAndNewerVersion_ReturnsSchemaVersionError(t *testing.T) {
+	useFixtureMigrations(t)
+
+	_, err := traverser.MigrateWorkflow([]byte(`{"graph": {"nodeType": "wait", "duration": 1}, "metadata": {"schemaVersion": 4}}`))
+
+	var versionErr *traverser.SchemaVersionError
+	require.True(t, errors.As(err, &versionErr))
+	assert.Contains(t, err.Error(), "schema version 4 is newer than this build supports (3)")
+}
+
+func TestSetMigrations_WithGapOrShortChain_Fails(t *testing.T) {
+	step := func(from int) traverser.MigrationStep {
+		return traverser.MigrationStep{From: from, Description: fmt.Sprintf("step %d", from), Apply: func(*traverser.Object) error { return nil }}
+	}
+
+	_, err := traverser.SetMigrations([]traverser.MigrationStep{step(1), step(3)}, 4)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"step 3" starts at schema version 3, expected 2`)
+
+	_, err = traverser.SetMigrations([]traverser.MigrationStep{step(1)}, 3)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "end at schema version 2, current is 3")
+
+	workflow, err := traverser.ParseWorkflow([]byte(`{"graph": {"nodeType": "wait", "duration": 1}}`))
+	require.NoError(t, err)
+	assert.Equal(t, traverser.CurrentSchemaVersion, workflow.Metadata.SchemaVersion)
+}
@@ -0,0 +1,160 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// portalWorkflow checks two portals in parallel and reports both prices.
+func portalWorkflow(join string, extra string) string {
+	return `{
+		"metadata": {"name": "portals"},
+		"graph": {
+			"nodeType": "parallel",
+			"id": "check-portals",
+			"join": "` + join + `",
+			` + extra + `
+			"parallel": {
+				"north": {
+					"nodeType": "moveToPage",
+					"url": "https://north.example.com/{{user.account}}",
+					"next": {"nodeType": "extractText", "selector": "#north-price", "storeAs": "vars.price"}
+				},
+				"south": {
+					"nodeType": "moveToPage",
+					"url": "https://south.example.com/{{user.account}}",
+					"next": {"nodeType": "extractText", "selector": "#south-price", "storeAs": "vars.price"}
+				}
+			},
+			"next": {"nodeType": "fillField", "selector": "#report", "value": "{{vars.north.price}}/{{vars.south.price}}"}
+		}
+	}`
+}
+
+func TestEngineExecute_WithParallelJoinAll_MergesBranchOutputsByName(t *testing.T) {
+	engine, browser := newTestEngine(t, portalWorkflow("all", `"isolate": true,`), map[string]interface{}{"account": "a-1"})
+	browser.Elements["#north-price"] = traverser.SelectorInfo{Text: "10"}
+	browser.Elements["#south-price"] = traverser.SelectorInfo{Text: "12"}
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fill #report=10/12"}, browser.Actions)
+	require.Len(t, browser.Tabs, 2)
+	for _, tab := range browser.Tabs {
+		assert.True(t, tab.Closed)
+		assert.True(t, tab.Isolated)
+		assert.Len(t, tab.Actions, 1)
+	}
+	_, leaked := engine.Context().Get("vars.price")
+	assert.False(t, leaked)
+	assert.Equal(t, "12", engine.Result().Extracted["vars.south.price"])
+
+	var branches []string
+	for _, node := range engine.Result().Nodes {
+		branches = append(branches, node.Branch)
+	}
+	assert.Equal(t, []string{"", "north", "north", "south", "south", ""}, branches)
+}
+
+func TestEngineExecute_WithParallelJoinAllAndFailedBranch_FailsWithBranchErrors(t *testing.T) {
+	engine, browser := newTestEngine(t, portalWorkflow("all", ""), nil)
+	browser.Elements["#north-price"] = traverser.SelectorInfo{Text: "10"}
+	browser.FailSelector["#south-price"] = errors.New("connection reset")
+
+	err := engine.Execute()
+
+	var parallelErr *traverser.ParallelError
+	require.True(t, errors.As(err, &parallelErr))
+	require.Len(t, parallelErr.Failures, 1)
+	assert.Equal(t, "south", parallelErr.Failures[0].Branch)
+	assert.Equal(t, traverser.ErrorKindBrowser, traverser.ErrorKind(err))
+	assert.Contains(t, err.Error(), "portals#check-portals: parallel join all: 1 of 2 branches failed")
+	assert.Empty(t, browser.Actions)
+}
+
+func TestEngineExecute_WithParallelJoinAny_ContinuesWithSucceededBranches(t *testing.T) {
+	engine, browser := newTestEngine(t, portalWorkflow("any", ""), nil)
+	browser.Elements["#north-price"] = traverser.SelectorInfo{Text: "10"}
+	browser.FailSelector["#south-price"] = errors.New("connection reset")
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fill #report=10/{{vars.south.price}}"}, browser.Actions)
+}
+
+func TestEngineExecute_WithParallelFirstSuccess_CancelsSlowerBranches(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "parallel",
+			"join": "first-success",
+			"parallel": {
+				"fast": {"nodeType": "extractText", "selector": "#fast", "storeAs": "vars.result"},
+				"slow": {
+					"nodeType": "waitFor",
+					"script": "window.neverReady",
+					"timeout": 10000,
+					"interval": 5,
+					"next": {"nodeType": "clickButton", "selector": "#slow"}
+				}
+			}
+		}
+	}`, nil)
+	browser.Elements["#fast"] = traverser.SelectorInfo{Text: "done"}
+
+	started := time.Now()
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Less(t, time.Since(started), 5*time.Second)
+	value, _ := engine.Context().Get("vars.fast.result")
+	assert.Equal(t, "done", value)
+	_, merged := engine.Context().Get("vars.slow")
+	assert.False(t, merged)
+}
+
+func TestEngineExecute_WithParallelMaxConcurrency_DoesNotStartBranchesAfterWinner(t *testing.T) {
+	engine, browser := newTestEngine(t, portalWorkflow("first-success", `"maxConcurrency": 1,`), nil)
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	require.Len(t, browser.Tabs, 1)
+	assert.Equal(t, []string{"navigate https://north.example.com/{{user.account}}"}, browser.Tabs[0].Actions)
+}
+
+func TestEngineDryRun_WithParallel_PlansEachBranch(t *testing.T) {
+	engine, _ := newTestEngine(t, portalWorkflow("all", ""), map[string]interface{}{"account": "a-1"})
+	engine.SetDryRun(true)
+
+	require.NoError(t, engine.Execute())
+
+	steps := engine.Plan().Steps
+	require.Len(t, steps, 6)
+	assert.Equal(t, []string{"branch north", "branch south"}, steps[0].Actions)
+	assert.Equal(t, []string{"navigate https://north.example.com/a-1"}, steps[1].Actions)
+	assert.Equal(t, []string{`fill #report = "<text of #north-price>/<text of #south-price>"`}, steps[5].Actions)
+}
+
+func TestValidateWorkflow_WithInvalidParallel_ReportsErrors(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "parallel",
+			"join": "majority",
+			"isolate": "yes",
+			"parallel": {"portal-a": {"nodeType": "wait", "duration": 1}}
+		}
+	}`))
+
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[0].Message, "isolate must be a boolean")
+	assert.Contains(t, errs[1].Message, "join must be one of all, any, first-success")
+	assert.Contains(t, errs[2].Message, `branch name "portal-a" must be letters, digits and underscores`)
+}
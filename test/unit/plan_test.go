@@ -0,0 +1,101 @@
+package unit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDryRunEngine(t *testing.T, workflowJSON string, user map[string]interface{}) *traverser.Engine {
+	t.Helper()
+
+	workflow, err := traverser.ParseWorkflow([]byte(workflowJSON))
+	require.NoError(t, err)
+
+	engine := traverser.NewEngine(nil)
+	engine.SetWorkflow(workflow)
+	engine.SetContext(user)
+	engine.SetDryRun(true)
+	return engine
+}
+
+func TestEngineDryRun_WithLoginFlow_RecordsResolvedActionsWithSecretsMasked(t *testing.T) {
+	engine := newDryRunEngine(t, `{
+		"graph": {
+			"nodeType": "moveToPage",
+			"url": "https://example.com/login",
+			"next": {
+				"id": "password",
+				"nodeType": "fillField",
+				"selector": "#password",
+				"value": "{{user.password}}",
+				"next": {
+					"nodeType": "conditional",
+					"conditionExpression": "user.remember == true",
+					"branches": {"yes": {"nodeType": "clickButton", "selector": "#remember"}}
+				}
+			}
+		}
+	}`, map[string]interface{}{"password": "hunter2", "remember": true})
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	plan := engine.Plan()
+	require.Len(t, plan.Steps, 4)
+	assert.Equal(t, []string{"navigate https://example.com/login"}, plan.Steps[0].Actions)
+	assert.Equal(t, "password", plan.Steps[1].Node)
+	assert.Equal(t, []string{`fill #password = "********"`}, plan.Steps[1].Actions)
+	assert.Equal(t, []string{"user.remember == true → yes"}, plan.Steps[2].Actions)
+	assert.Equal(t, 1, plan.Steps[3].Depth)
+	assert.Equal(t, []string{"click #remember"}, plan.Steps[3].Actions)
+	assert.Zero(t, plan.IssueCount())
+}
+
+func TestEngineDryRun_WithUnresolvedTemplateAndMissingFile_ReportsIssues(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.pdf")
+	engine := newDryRunEngine(t, `{
+		"graph": {
+			"nodeType": "fillField",
+			"selector": "#name",
+			"value": "{{user.name}}",
+			"next": {"nodeType": "sendFile", "selector": "#cv", "filePath": "{{user.cv}}"}
+		}
+	}`, map[string]interface{}{"cv": missing})
+	engine.SetStrict(true)
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	plan := engine.Plan()
+	assert.Equal(t, []string{"error: unresolved {{user.name}}"}, plan.Steps[0].Issues)
+	assert.Equal(t, []string{"error: file not found: " + missing}, plan.Steps[1].Issues)
+	assert.Equal(t, 2, plan.IssueCount())
+}
+
+func TestEngineDryRun_WithForEachQuestion_DoesNotPromptAndWalksEveryItem(t *testing.T) {
+	engine := newDryRunEngine(t, `{
+		"graph": {
+			"nodeType": "forEach",
+			"dataSource": "{{user.items}}",
+			"questionText": "Process {{iterator.count}}?",
+			"next": {"nodeType": "clickButton", "selector": "#item-{{iterator.index}}"}
+		}
+	}`, map[string]interface{}{"items": []interface{}{"a", "b"}})
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	plan := engine.Plan()
+	require.Len(t, plan.Steps, 3)
+	assert.Equal(t, []string{
+		"iterate 2 items of {{user.items}}",
+		`ask "Process 1?" → continue`,
+		`ask "Process 2?" → continue`,
+	}, plan.Steps[0].Actions)
+	assert.Equal(t, []string{"click #item-1"}, plan.Steps[2].Actions)
+}
@@ -0,0 +1,101 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineExecute_WithRetryOnFlakyClick_SucceedsAndReportsAttempts(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"id": "submit",
+			"nodeType": "clickButton",
+			"selector": "#submit",
+			"retry": {"count": 3, "backoff": 1, "factor": 2, "on": ["browser"]}
+		}
+	}`, nil)
+	browser.FailSelector["#submit"] = errors.New("detached node")
+	browser.FailTimes["#submit"] = 2
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"click #submit"}, browser.Actions)
+	result := engine.Result()
+	assert.Equal(t, traverser.RunSucceeded, result.Status)
+	require.Len(t, result.Nodes, 1)
+	assert.Equal(t, "submit", result.Nodes[0].Node)
+	assert.Equal(t, 3, result.Nodes[0].Attempts)
+	assert.Equal(t, []string{"retry"}, result.Nodes[0].Policies)
+}
+
+func TestEngineExecute_WithRetryForOtherErrorKind_FailsOnFirstAttempt(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {"nodeType": "clickButton", "selector": "#submit", "retry": {"count": 3, "on": ["timeout"]}}
+	}`, nil)
+	browser.FailSelector["#submit"] = fmt.Errorf("%w: #submit", traverser.ErrElementNotFound)
+
+	err := engine.Execute()
+
+	require.ErrorIs(t, err, traverser.ErrElementNotFound)
+	result := engine.Result()
+	assert.Equal(t, traverser.RunFailed, result.Status)
+	assert.Equal(t, 1, result.Nodes[0].Attempts)
+	assert.Equal(t, traverser.NodeFailed, result.Nodes[0].Status)
+	assert.Equal(t, traverser.ErrorKindNotFound, result.Nodes[0].ErrorKind)
+}
+
+func TestEngineExecute_WithOnError_RunsRecoveryAndContinues(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"id": "accept-cookies",
+			"nodeType": "clickButton",
+			"selector": "#cookies",
+			"retry": {"count": 1},
+			"onError": {"nodeType": "moveToPage", "url": "https://example.com/reload"},
+			"next": {"nodeType": "clickButton", "selector": "#continue"}
+		}
+	}`, nil)
+	browser.FailSelector["#cookies"] = fmt.Errorf("%w: #cookies", traverser.ErrElementNotFound)
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"navigate https://example.com/reload", "click #continue"}, browser.Actions)
+	node := engine.Result().Nodes[0]
+	assert.Equal(t, traverser.NodeRecovered, node.Status)
+	assert.Equal(t, 2, node.Attempts)
+	assert.Equal(t, []string{"retry", "onError"}, node.Policies)
+	assert.Contains(t, node.Error, "element not found")
+}
+
+func TestEngineExecute_WithNodeTimeout_SetsAndRestoresBrowserTimeout(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {"nodeType": "clickButton", "selector": "#slow", "timeout": 5000}
+	}`, nil)
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{5 * time.Second, 30 * time.Second}, browser.Timeouts)
+	assert.Equal(t, []string{"timeout"}, engine.Result().Nodes[0].Policies)
+}
+
+func TestValidateWorkflow_WithInvalidRetry_ReportsErrors(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+		"graph": {"nodeType": "clickButton", "selector": "#a", "retry": {"backoff": -1, "on": ["flaky"]}}
+	}`))
+
+	require.Len(t, errs, 3)
+	assert.Equal(t, "/graph/retry/backoff", errs[0].Pointer)
+	assert.Equal(t, "/graph/retry/on/0", errs[1].Pointer)
+	assert.Contains(t, errs[1].Message, "error kind must be one of")
+	assert.Equal(t, "/graph/retry", errs[2].Pointer)
+}
@@ -0,0 +1,176 @@
+package unit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerDatePicker registers a "selectDatePicker" node that fills a date field and
+// records the date it picked, and removes it when the test ends.
+func registerDatePicker(t *testing.T) {
+	t.Helper()
+	err := traverser.RegisterNodeType(traverser.CustomNodeType{
+		Name: "selectDatePicker",
+		Required: map[string]traverser.PropertyKind{
+			"selector": traverser.PropertyString,
+			"date":     traverser.PropertyString,
+		},
+		Optional:    map[string]traverser.PropertyKind{"storeAs": traverser.PropertyContextPath},
+		KeyProperty: "date",
+		Validate: func(properties map[string]interface{}) error {
+			date := properties["date"].(string)
+			if strings.Contains(date, "{{") {
+				return nil
+			}
+			if _, err := time.Parse("2006-01-02", date); err != nil {
+				return errors.New("date must be YYYY-MM-DD")
+			}
+			return nil
+		},
+		Execute: func(ctx *traverser.NodeContext) error {
+			selector, err := ctx.String("selector")
+			if err != nil {
+				return err
+			}
+			date, err := ctx.String("date")
+			if err != nil {
+				return err
+			}
+			ctx.LogInfo("picking %s", date)
+			if err := ctx.Browser().FillField(selector, date); err != nil {
+				return &traverser.ActionError{Action: "selectDatePicker", Target: selector, Err: err}
+			}
+			if storeAs, ok := ctx.Property("storeAs"); ok {
+				return ctx.Set(storeAs.(string), date)
+			}
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { traverser.UnregisterNodeType("selectDatePicker") })
+}
+
+const datePickerWorkflow = `{
+	"graph": {
+		"nodeType": "selectDatePicker",
+		"id": "pick-start",
+		"selector": "#start",
+		"date": "{{user.start}}",
+		"storeAs": "vars.start",
+		"next": {"nodeType": "fillField", "selector": "#note", "value": "from {{vars.start}}"}
+	}
+}`
+
+func TestEngineExecute_WithCustomNodeType_RunsExecutor(t *testing.T) {
+	registerDatePicker(t)
+	engine, browser := newTestEngine(t, datePickerWorkflow, map[string]interface{}{"start": "2026-11-02"})
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fill #start=2026-11-02", "fill #note=from 2026-11-02"}, browser.Actions)
+	require.Len(t, engine.Result().Nodes, 2)
+	assert.Equal(t, "selectDatePicker", engine.Result().Nodes[0].NodeType)
+}
+
+func TestEngineExecute_WithFailingCustomNode_AppliesRetryPolicy(t *testing.T) {
+	registerDatePicker(t)
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "selectDatePicker",
+			"selector": "#start",
+			"date": "2026-11-02",
+			"retry": {"count": 1, "on": ["browser"]}
+		}
+	}`, nil)
+	browser.FailSelector["#start"] = errors.New("detached")
+	browser.FailTimes["#start"] = 1
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, engine.Result().Nodes[0].Attempts)
+}
+
+func TestValidateWorkflow_WithCustomNodeType_ChecksSchemaAndValidator(t *testing.T) {
+	registerDatePicker(t)
+
+	errs := traverser.ValidateWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "sequence",
+			"sequence": [
+				{"nodeType": "selectDatePicker", "selector": "#start"},
+				{"nodeType": "selectDatePicker", "selector": "#end", "date": "02/11/2026"},
+				{"nodeType": "selectDatePicker", "selector": "#end", "date": "2026-11-02", "color": "red"}
+			]
+		}
+	}`))
+
+	require.Len(t, errs, 3)
+	assert.Equal(t, "/graph/sequence/0", errs[0].Pointer)
+	assert.Contains(t, errs[0].Message, `requires property "date"`)
+	assert.Equal(t, "/graph/sequence/1", errs[1].Pointer)
+	assert.Equal(t, "selectDatePicker: date must be YYYY-MM-DD", errs[1].Message)
+	assert.Equal(t, "/graph/sequence/2/color", errs[2].Pointer)
+}
+
+func TestValidateWorkflow_WithUnregisteredNodeType_ReportsUnknown(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{"graph": {"nodeType": "selectDatePicker", "selector": "#a", "date": "2026-11-02"}}`))
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, `unknown nodeType "selectDatePicker"`)
+}
+
+func TestRegisterNodeType_WithInvalidDefinitions_ReturnsErrors(t *testing.T) {
+	execute := func(*traverser.NodeContext) error { return nil }
+	registerDatePicker(t)
+
+	cases := map[string]traverser.CustomNodeType{
+		"is built in":        {Name: traverser.NodeTypeClickButton, Execute: execute},
+		"already registered": {Name: "selectDatePicker", Execute: execute},
+		"needs an Execute":   {Name: "solveInternalSSO"},
+		"performs multiple":  {Name: "loginAndSubmit", Execute: execute},
+		`property "next" is reserved`: {Name: "solveInternalSSO", Execute: execute,
+			Optional: map[string]traverser.PropertyKind{"next": traverser.PropertyString}},
+	}
+	for message, nodeType := range cases {
+		err := traverser.RegisterNodeType(nodeType)
+		if assert.Error(t, err, message) {
+			assert.Contains(t, err.Error(), message)
+		}
+	}
+	assert.Equal(t, []string{"selectDatePicker"}, traverser.RegisteredNodeTypes())
+}
+
+func TestRenderGraph_WithCustomNodeType_ShowsKeyProperty(t *testing.T) {
+	registerDatePicker(t)
+	workflow, err := traverser.ParseWorkflow([]byte(datePickerWorkflow))
+	require.NoError(t, err)
+
+	mermaid, err := workflow.RenderGraph(traverser.GraphMermaid, nil)
+	require.NoError(t, err)
+	dot, err := workflow.RenderGraph(traverser.GraphDOT, nil)
+	require.NoError(t, err)
+
+	assert.Contains(t, mermaid, `n1[/"pick-start · selectDatePicker<br/>{{user.start}}"/]`)
+	assert.Contains(t, dot, "shape=parallelogram")
+}
+
+func TestEngineExecute_WithCustomNodeInDryRun_DescribesBrowserActions(t *testing.T) {
+	registerDatePicker(t)
+	engine, browser := newTestEngine(t, datePickerWorkflow, map[string]interface{}{"start": "2026-11-02"})
+	engine.SetDryRun(true)
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Empty(t, browser.Actions)
+	assert.Equal(t, []string{`fill #start = "2026-11-02"`}, engine.Plan().Steps[0].Actions)
+}
@@ -0,0 +1,186 @@
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"rpa-dfs-engine/internal/logger"
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSecretKey(t *testing.T) []byte {
+	t.Helper()
+	text, err := traverser.GenerateSecretKey()
+	require.NoError(t, err)
+	key, err := traverser.ParseSecretKey(text)
+	require.NoError(t, err)
+	return key
+}
+
+// secretContext returns a user scope parsed from a context file holding password encrypted with key.
+func secretContext(t *testing.T, key []byte, password string) map[string]interface{} {
+	t.Helper()
+	value, err := traverser.EncryptSecret(key, password)
+	require.NoError(t, err)
+	user, err := traverser.ParseContext([]byte(fmt.Sprintf(
+		`{"user": {"email": "ada@example.com", "password": {"$secret": %q}}}`, value)))
+	require.NoError(t, err)
+	return user
+}
+
+func TestEncryptSecret_WithOtherKey_CannotDecrypt(t *testing.T) {
+	key := newSecretKey(t)
+	value, err := traverser.EncryptSecret(key, "hunter2")
+	require.NoError(t, err)
+
+	plaintext, err := traverser.DecryptSecret(key, value)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", plaintext)
+	assert.True(t, strings.HasPrefix(value, "enc:"))
+	assert.NotContains(t, value, "hunter2")
+
+	_, err = traverser.DecryptSecret(newSecretKey(t), value)
+	assert.Error(t, err)
+}
+
+func TestEngineExecute_WithSecret_FillsDecryptedValueAndMasksResult(t *testing.T) {
+	key := newSecretKey(t)
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "conditional",
+			"conditionExpression": "user.password == 'hunter2'",
+			"branches": {
+				"yes": {
+					"nodeType": "fillField", "selector": "#password", "value": "{{user.password}}",
+					"next": {"nodeType": "clickButton", "selector": "#login"}
+				}
+			}
+		}
+	}`, secretContext(t, key, "hunter2"))
+	engine.SetSecretKey(key)
+	browser.FailSelector["#login"] = errors.New("login rejected for ada@example.com/hunter2")
+
+	err := engine.Execute()
+
+	require.Error(t, err)
+	assert.Equal(t, []string{"fill #password=hunter2"}, browser.Actions)
+	result := engine.Result()
+	assert.NotContains(t, result.Error, "hunter2")
+	assert.Contains(t, result.Error, "ada@example.com/********")
+	for _, node := range result.Nodes {
+		assert.NotContains(t, node.Error, "hunter2")
+	}
+}
+
+func TestEngineExecute_WithFilteredSecret_MasksFilteredValueInLogsAndResult(t *testing.T) {
+	key := newSecretKey(t)
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "moveToPage", "url": "https://example.com/login?pw={{user.password | urlencode}}",
+			"next": {"nodeType": "clickButton", "selector": "#login"}
+		}
+	}`, secretContext(t, key, "p@ss w0rd!"))
+	engine.SetSecretKey(key)
+	browser.FailSelector["#login"] = errors.New("login rejected for pw=p%40ss+w0rd%21")
+	var logs bytes.Buffer
+	logger.SetOutput(&logs)
+	defer logger.SetOutput(nil)
+
+	err := engine.Execute()
+
+	require.Error(t, err)
+	assert.Equal(t, "navigate https://example.com/login?pw=p%40ss+w0rd%21", browser.Actions[0])
+	assert.Contains(t, logs.String(), "Navigate to: https://example.com/login?pw=********")
+	assert.NotContains(t, logs.String(), "p%40ss+w0rd%21")
+	result := engine.Result()
+	assert.NotContains(t, result.Error, "p%40ss+w0rd%21")
+	assert.Contains(t, result.Error, "pw=********")
+}
+
+func TestEngineExecute_WithFilteredSecretParam_KeepsPlaintextOutOfCheckpoint(t *testing.T) {
+	key := newSecretKey(t)
+	dir := t.TempDir()
+	writeWorkflowFile(t, dir, "login.json", `{
+		"graph": {
+			"nodeType": "fillField", "selector": "#password", "value": "{{user.pw}}",
+			"next": {"nodeType": "clickButton", "selector": "#login"}
+		}
+	}`)
+	main := writeWorkflowFile(t, dir, "main.json", `{
+		"graph": {"nodeType": "callWorkflow", "workflow": "login.json", "params": {"pw": "{{user.password | trim}}"}}
+	}`)
+	engine, browser := newFileEngine(t, main, secretContext(t, key, " hunter2 "))
+	engine.SetSecretKey(key)
+	runsDir := t.TempDir()
+	engine.SetRunsDir(runsDir)
+	engine.SetRunID("run-1")
+	browser.FailSelector["#login"] = errors.New("chrome crashed")
+
+	require.Error(t, engine.Execute())
+
+	assert.Equal(t, []string{"fill #password=hunter2"}, browser.Actions)
+	data, err := os.ReadFile(traverser.CheckpointPath(runsDir, "run-1"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+	assert.Contains(t, string(data), `"$secret": "enc:`)
+}
+
+func TestEngineExecute_WithSecretAndNoKey_FailsBeforeFirstAction(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {"nodeType": "fillField", "selector": "#password", "value": "{{user.password}}"}
+	}`, secretContext(t, newSecretKey(t), "hunter2"))
+
+	err := engine.Execute()
+
+	assert.ErrorIs(t, err, traverser.ErrNoSecretKey)
+	assert.Empty(t, browser.Actions)
+}
+
+func TestEngineExecute_WithSecretInDryRun_PlansMaskWithoutKey(t *testing.T) {
+	engine, _ := newTestEngine(t, `{
+		"graph": {"nodeType": "fillField", "selector": "#pin", "value": "{{user.password}}"}
+	}`, secretContext(t, newSecretKey(t), "4711"))
+	engine.SetDryRun(true)
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{`fill #pin = "********"`}, engine.Plan().Steps[0].Actions)
+}
+
+func TestRotateSecrets_WithNewKey_ReencryptsAndKeepsFormatting(t *testing.T) {
+	oldKey, newKey := newSecretKey(t), newSecretKey(t)
+	value, err := traverser.EncryptSecret(oldKey, "hunter2")
+	require.NoError(t, err)
+	data := []byte("user:\n  # rotated quarterly\n  password:\n    $secret: " + value + "\n")
+
+	rotated, count, err := traverser.RotateSecrets(data, oldKey, newKey)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Contains(t, string(rotated), "# rotated quarterly")
+	user, err := traverser.ParseContextYAML(rotated)
+	require.NoError(t, err)
+	secret, ok := user["password"].(traverser.Secret)
+	require.True(t, ok)
+	plaintext, err := traverser.DecryptSecret(newKey, secret.Ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", plaintext)
+
+	_, _, err = traverser.RotateSecrets(rotated, oldKey, newKey)
+	assert.Error(t, err)
+}
+
+func TestParseContext_WithPlainSecretValue_Fails(t *testing.T) {
+	_, err := traverser.ParseContext([]byte(`{"user": {"password": {"$secret": "hunter2"}}}`))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "user.password")
+}
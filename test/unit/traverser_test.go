@@ -0,0 +1,329 @@
+package unit
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"rpa-dfs-engine/internal/traverser"
+	"rpa-dfs-engine/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEngine(t *testing.T, workflowJSON string, user map[string]interface{}) (*traverser.Engine, *mocks.MockWorkflowBrowser) {
+	t.Helper()
+
+	workflow, err := traverser.ParseWorkflow([]byte(workflowJSON))
+	require.NoError(t, err)
+
+	browser := mocks.NewMockWorkflowBrowser()
+	engine := traverser.NewEngine(browser)
+	engine.SetWorkflow(workflow)
+	engine.SetContext(user)
+	engine.SetIO(strings.NewReader(""), &bytes.Buffer{})
+	return engine, browser
+}
+
+func TestEngineExecute_WithLoginChain_PerformsActionsInOrder(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "moveToPage",
+			"url": "https://example.com/login",
+			"next": {
+				"nodeType": "fillField",
+				"selector": "#email",
+				"value": "{{user.email}}",
+				"next": {
+					"nodeType": "clickButton",
+					"selector": "#login-btn",
+					"next": null
+				}
+			}
+		},
+		"metadata": {"name": "Simple Login", "version": "1.0.0"}
+	}`, map[string]interface{}{"email": "john@example.com"})
+
+	err := engine.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"navigate https://example.com/login",
+		"fill #email=john@example.com",
+		"click #login-btn",
+	}, browser.Actions)
+}
+
+func TestEngineExecute_WithSequence_RunsItemsThenNext(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "sequence",
+			"sequence": [
+				{"nodeType": "fillField", "selector": "#first", "value": "{{user.firstName}}"},
+				{"nodeType": "fillField", "selector": "#last", "value": "{{user.lastName}}"}
+			],
+			"next": {"nodeType": "clickButton", "selector": "#submit"}
+		}
+	}`, map[string]interface{}{"firstName": "John", "lastName": "Doe"})
+
+	err := engine.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fill #first=John", "fill #last=Doe", "click #submit"}, browser.Actions)
+}
+
+func TestEngineExecute_WithConditional_TakesMatchingBranch(t *testing.T) {
+	workflow := `{
+		"graph": {
+			"nodeType": "conditional",
+			"conditionExpression": "{{user.age}} > 18",
+			"branches": {
+				"yes": {"nodeType": "fillField", "selector": "#age", "value": "adult"},
+				"no": {"nodeType": "fillField", "selector": "#age", "value": "minor"}
+			}
+		}
+	}`
+
+	adult, adultBrowser := newTestEngine(t, workflow, map[string]interface{}{"age": float64(30)})
+	minor, minorBrowser := newTestEngine(t, workflow, map[string]interface{}{"age": float64(12)})
+
+	assert.NoError(t, adult.Execute())
+	assert.NoError(t, minor.Execute())
+	assert.Equal(t, []string{"fill #age=adult"}, adultBrowser.Actions)
+	assert.Equal(t, []string{"fill #age=minor"}, minorBrowser.Actions)
+}
+
+func TestEngineExecute_WithQuestion_BranchesOnDataCheck(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "question",
+			"check": {"dataPath": "user.isActive", "operator": "equals", "expectedValue": true},
+			"branches": {
+				"yes": {"nodeType": "fillField", "selector": "#status", "value": "active"},
+				"no": {"nodeType": "fillField", "selector": "#status", "value": "inactive"}
+			}
+		}
+	}`, map[string]interface{}{"isActive": true})
+
+	err := engine.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fill #status=active"}, browser.Actions)
+}
+
+func TestEngineExecute_WithForEachAnswers_SkipsDeclinedItems(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "forEach",
+			"dataSource": "{{user.files}}",
+			"questionText": "Upload {{user.files[iterator.index]}} ({{iterator.count}}/{{iterator.total}})?",
+			"next": {
+				"nodeType": "sendFile",
+				"selector": "input[type='file']",
+				"filePath": "{{user.files[iterator.index]}}"
+			}
+		}
+	}`, map[string]interface{}{"files": []interface{}{"/a.pdf", "/b.pdf", "/c.pdf"}})
+	output := &bytes.Buffer{}
+	engine.SetIO(strings.NewReader("\nn\n\n"), output)
+
+	err := engine.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"upload input[type='file']=/a.pdf",
+		"upload input[type='file']=/c.pdf",
+	}, browser.Actions)
+	assert.Contains(t, output.String(), "Upload /b.pdf (2/3)?")
+}
+
+func TestEngineExecute_WithBrowserError_StopsAndReturnsError(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "clickButton",
+			"selector": "#missing",
+			"next": {"nodeType": "clickButton", "selector": "#after"}
+		}
+	}`, nil)
+	browser.FailSelector["#missing"] = fmt.Errorf("element not found")
+
+	err := engine.Execute()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "#missing")
+	assert.Empty(t, browser.Actions)
+}
+
+func TestParseWorkflow_WithUnknownNodeType_ReturnsValidationError(t *testing.T) {
+	_, err := traverser.ParseWorkflow([]byte(`{"graph": {"nodeType": "fillForm"}}`))
+
+	var errs traverser.ValidationErrors
+	assert.ErrorAs(t, err, &errs)
+}
+
+func TestContextGet_WithNestedAndIndexedPaths_ResolvesValues(t *testing.T) {
+	ctx := traverser.NewContext(map[string]interface{}{
+		"profile": map[string]interface{}{"social": map[string]interface{}{"twitter": "@johndoe"}},
+		"files":   []interface{}{"first.pdf", "second.pdf"},
+	})
+	ctx.SetIterator(1, 2)
+
+	twitter, twitterOk := ctx.Get("user.profile.social.twitter")
+	file, fileOk := ctx.Get("user.files[iterator.index]")
+	literal, literalOk := ctx.Get("{{user.files[0]}}")
+	_, missingOk := ctx.Get("user.files[5]")
+
+	assert.True(t, twitterOk)
+	assert.Equal(t, "@johndoe", twitter)
+	assert.True(t, fileOk)
+	assert.Equal(t, "second.pdf", file)
+	assert.True(t, literalOk)
+	assert.Equal(t, "first.pdf", literal)
+	assert.False(t, missingOk)
+}
+
+func TestParseWorkflow_WithoutGraph_ReturnsError(t *testing.T) {
+	_, err := traverser.ParseWorkflow([]byte(`{"metadata": {"name": "empty"}}`))
+
+	assert.Error(t, err)
+}
+
+func TestEngineExecute_WithTemplateFilters_AppliesPipeline(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "sequence",
+			"sequence": [
+				{"nodeType": "fillField", "selector": "#name", "value": "{{ user.name | trim | upper }}"},
+				{"nodeType": "fillField", "selector": "#city", "value": "{{user.city | default:\"Berlin\"}}"},
+				{"nodeType": "fillField", "selector": "#born", "value": "{{user.born | date:\"02.01.2006\"}}"},
+				{"nodeType": "fillField", "selector": "#tags", "value": "{{user.tags | json}}"},
+				{"nodeType": "moveToPage", "url": "https://example.com/search?q={{user.query | urlencode}}"}
+			]
+		}
+	}`, map[string]interface{}{
+		"name":  "  john ",
+		"born":  "1990-05-17",
+		"tags":  []interface{}{"a", "b"},
+		"query": "go & rpa",
+	})
+
+	err := engine.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"fill #name=JOHN",
+		"fill #city=Berlin",
+		"fill #born=17.05.1990",
+		`fill #tags=["a","b"]`,
+		"navigate https://example.com/search?q=go+%26+rpa",
+	}, browser.Actions)
+}
+
+func TestEngineExecute_WithUnresolvedReference_LeavesTemplateInPlace(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {"nodeType": "fillField", "selector": "#email", "value": "{{user.email}}"}
+	}`, nil)
+
+	err := engine.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fill #email={{user.email}}"}, browser.Actions)
+}
+
+func TestEngineExecute_WithStrictModeAndUnresolvedReference_FailsRun(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {"nodeType": "fillField", "selector": "#email", "value": "{{user.email}} {{user.name | upper}}"}
+	}`, nil)
+	engine.SetStrict(true)
+
+	err := engine.Execute()
+
+	var unresolved *traverser.UnresolvedReferenceError
+	require.ErrorAs(t, err, &unresolved)
+	assert.Equal(t, []string{"{{user.email}}", "{{user.name | upper}}"}, unresolved.References)
+	assert.Empty(t, browser.Actions)
+}
+
+func TestValidateWorkflow_WithUnknownFilter_ReportsTemplateError(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+		"graph": {"nodeType": "fillField", "selector": "#name", "value": "{{user.name | shout}}"}
+	}`))
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, "/graph/value", errs[0].Pointer)
+	assert.Contains(t, errs[0].Message, `unknown filter "shout"`)
+}
+
+func TestEngineExecute_WithNextIdJump_ContinuesAtTargetNode(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "conditional",
+			"conditionExpression": "false",
+			"branches": {
+				"yes": {"id": "done", "nodeType": "clickButton", "selector": "#submit"},
+				"no": {
+					"nodeType": "forEach",
+					"dataSource": "user.items",
+					"questionText": "",
+					"nextId": "done",
+					"next": {"nodeType": "fillField", "selector": "#item", "value": "{{user.items[iterator.index]}}"}
+				}
+			}
+		}
+	}`, map[string]interface{}{"items": []interface{}{"a", "b"}})
+
+	err := engine.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fill #item=a", "fill #item=b", "click #submit"}, browser.Actions)
+}
+
+func TestEngineExecute_WithBranchJumpCycle_ReturnsCycleErrorWithPath(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"id": "search-start",
+			"nodeType": "moveToPage",
+			"url": "https://example.com/search",
+			"maxVisits": 2,
+			"next": {
+				"id": "has-results",
+				"nodeType": "conditional",
+				"conditionExpression": "user.found == true",
+				"branches": {
+					"yes": {"nodeType": "clickButton", "selector": "#first"},
+					"noId": "search-start"
+				}
+			}
+		}
+	}`, map[string]interface{}{"found": false})
+
+	err := engine.Execute()
+
+	var cycle *traverser.CycleError
+	require.ErrorAs(t, err, &cycle)
+	assert.Equal(t, "search-start", cycle.NodeID)
+	assert.Equal(t, 2, cycle.MaxVisits)
+	assert.Equal(t, []string{
+		"search-start", "has-results", "search-start", "has-results", "search-start", "has-results", "search-start",
+	}, cycle.Path)
+	assert.Len(t, browser.Actions, 3)
+}
+
+func TestValidateWorkflow_WithDuplicateIdsAndUnknownJump_ReportsBoth(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+		"graph": {
+			"id": "a",
+			"nodeType": "clickButton",
+			"selector": "#x",
+			"next": {"id": "a", "nodeType": "clickButton", "selector": "#y", "nextId": "missing"}
+		}
+	}`))
+
+	require.Len(t, errs, 2)
+	assert.Equal(t, "/graph/next/id", errs[0].Pointer)
+	assert.Contains(t, errs[0].Message, `duplicate id "a"`)
+	assert.Equal(t, "/graph/next/nextId", errs[1].Pointer)
+	assert.Contains(t, errs[1].Message, `unknown node id "missing"`)
+}
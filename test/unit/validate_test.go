@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"testing"
+
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateWorkflow_WithValidDocument_ReturnsNil(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "moveToPage",
+			"url": "https://example.com",
+			"next": {
+				"nodeType": "question",
+				"check": {"dataPath": "user.isActive", "operator": "equals", "expectedValue": true},
+				"branches": {
+					"yes": {"nodeType": "clickButton", "selector": "#yes"},
+					"no": null
+				}
+			}
+		},
+		"metadata": {"name": "Valid", "version": "1.0.0"}
+	}`))
+
+	assert.Nil(t, errs)
+}
+
+func TestValidateWorkflow_WithMissingRequiredProperty_ReportsPointerAndPosition(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+  "graph": {
+    "nodeType": "moveToPage",
+    "url": "https://example.com",
+    "next": {
+      "nodeType": "forEach",
+      "questionText": "Go?"
+    }
+  }
+}`))
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, "/graph/next", errs[0].Pointer)
+	assert.Equal(t, 5, errs[0].Line)
+	assert.Equal(t, 5, errs[0].Column)
+	assert.Contains(t, errs[0].Message, `"dataSource"`)
+}
+
+func TestValidateWorkflow_WithMultipleViolations_ReportsEveryOne(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "sequence",
+			"sequence": [
+				{"nodeType": "fillField", "selector": "#a"},
+				{"nodeType": "clickButton", "selector": "#b", "color": "red"},
+				{"nodeType": "teleport"}
+			]
+		}
+	}`))
+
+	require.Len(t, errs, 3)
+	assert.Equal(t, "/graph/sequence/0", errs[0].Pointer)
+	assert.Equal(t, "/graph/sequence/1/color", errs[1].Pointer)
+	assert.Contains(t, errs[1].Message, "unknown property")
+	assert.Equal(t, "/graph/sequence/2/nodeType", errs[2].Pointer)
+	assert.Contains(t, errs[2].Message, `unknown nodeType "teleport"`)
+}
+
+func TestValidateWorkflow_WithFillFormNode_ReportsMultiActionNode(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+		"graph": {"nodeType": "fillForm", "fields": [], "submitButton": "#submit"}
+	}`))
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, "performs multiple actions")
+}
+
+func TestValidateWorkflow_WithInvalidOperatorAndDuration_ReportsTypeErrors(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "wait",
+			"duration": -5,
+			"next": {
+				"nodeType": "question",
+				"check": {"dataPath": "user.x", "operator": "like", "expectedValue": 1},
+				"branches": {}
+			}
+		}
+	}`))
+
+	require.Len(t, errs, 2)
+	assert.Equal(t, "/graph/duration", errs[0].Pointer)
+	assert.Equal(t, "/graph/next/check/operator", errs[1].Pointer)
+}
+
+func TestValidateWorkflow_WithSyntaxError_ReportsLine(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte("{\n  \"graph\": {\n    \"nodeType\": \"wait\",,\n  }\n}"))
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, 3, errs[0].Line)
+	assert.Contains(t, errs[0].Message, "invalid JSON")
+}
@@ -0,0 +1,113 @@
+package unit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineExecute_WithWaitForSelector_PollsUntilVisible(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "waitFor",
+			"selector": "#dashboard",
+			"interval": 1,
+			"next": {"nodeType": "clickButton", "selector": "#dashboard"}
+		}
+	}`, nil)
+	browser.FailSelector["#dashboard"] = fmt.Errorf("%w: #dashboard", traverser.ErrElementNotFound)
+	browser.FailTimes["#dashboard"] = 3
+	browser.Elements["#dashboard"] = traverser.SelectorInfo{Count: 1, Visible: true}
+
+	err := engine.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"click #dashboard"}, browser.Actions)
+	assert.Equal(t, 0, browser.FailTimes["#dashboard"])
+}
+
+func TestEngineExecute_WithWaitForHiddenAndEnabled_ChecksState(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "waitFor",
+			"selector": ".spinner",
+			"state": "hidden",
+			"next": {"nodeType": "waitFor", "selector": "#submit", "state": "enabled"}
+		}
+	}`, nil)
+	browser.Elements["#submit"] = traverser.SelectorInfo{Count: 1, Visible: true, Enabled: true}
+
+	require.NoError(t, engine.Execute())
+}
+
+func TestEngineExecute_WithWaitForURLAndNetworkIdle_WaitsForBoth(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "waitFor",
+			"urlPattern": "/users/{{user.id}}$",
+			"next": {"nodeType": "waitFor", "networkIdle": 500, "interval": 1}
+		}
+	}`, map[string]interface{}{"id": 42})
+	browser.URL = "https://example.com/users/42"
+	browser.BusyPolls = 2
+
+	require.NoError(t, engine.Execute())
+	assert.Equal(t, 0, browser.BusyPolls)
+}
+
+func TestEngineExecute_WithWaitForScriptNeverTrue_FailsWithTimeout(t *testing.T) {
+	engine, _ := newTestEngine(t, `{
+		"graph": {
+			"nodeType": "waitFor",
+			"script": "window.appReady === true",
+			"timeout": 30,
+			"interval": 5
+		}
+	}`, nil)
+
+	started := time.Now()
+	err := engine.Execute()
+
+	require.Error(t, err)
+	assert.Equal(t, traverser.ErrorKindTimeout, traverser.ErrorKind(err))
+	assert.Contains(t, err.Error(), `waitFor script "window.appReady === true" is true: not reached within 30ms`)
+	assert.Less(t, time.Since(started), time.Second)
+}
+
+func TestValidateWorkflow_WithWaitForConditions_RequiresExactlyOne(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{
+		"graph": {
+			"nodeType": "waitFor",
+			"selector": "#a",
+			"script": "true",
+			"next": {
+				"nodeType": "waitFor",
+				"state": "gone",
+				"next": {"nodeType": "waitFor", "urlPattern": "(", "interval": 100}
+			}
+		}
+	}`))
+
+	require.Len(t, errs, 5)
+	assert.Contains(t, errs[0].Message, "invalid pattern")
+	assert.Contains(t, errs[1].Message, "state must be one of visible, hidden, enabled")
+	assert.Contains(t, errs[2].Message, "waitFor needs one of selector, urlPattern, networkIdle, script")
+	assert.Contains(t, errs[3].Message, "state requires a selector")
+	assert.Contains(t, errs[4].Message, "waitFor takes only one condition, found selector, script")
+}
+
+func TestEngineExecute_WithWaitForScriptTemplate_PassesValueAsArgument(t *testing.T) {
+	engine, browser := newTestEngine(t, `{
+		"graph": {"nodeType": "waitFor", "script": "document.title === {{user.title}} && {{user.count}} > 0"}
+	}`, map[string]interface{}{"title": `It's done") || alert(1) || ("`, "count": float64(2)})
+	browser.Scripts["document.title === args[0] && args[1] > 0"] = true
+
+	require.NoError(t, engine.Execute())
+	assert.Equal(t, []interface{}{`It's done") || alert(1) || ("`, float64(2)},
+		browser.ScriptArgs["document.title === args[0] && args[1] > 0"])
+}
@@ -0,0 +1,182 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"rpa-dfs-engine/internal/traverser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const loginYAML = `# Shared steps live under x- keys
+x-submit: &submit
+  nodeType: clickButton
+  selector: "#login"
+
+graph:
+  nodeType: moveToPage
+  url: https://example.com/login
+  next:
+    nodeType: fillField
+    selector: "#email"
+    value: "{{user.email}}"
+    next:
+      <<: *submit
+      next:
+        <<: *submit
+        selector: "#confirm"
+metadata:
+  name: login
+`
+
+func TestParseWorkflowYAML_WithAnchorsAndMergeKeys_ExpandsThem(t *testing.T) {
+	workflow, err := traverser.ParseWorkflowYAML([]byte(loginYAML))
+
+	require.NoError(t, err)
+	assert.Equal(t, "login", workflow.Name())
+	submit := workflow.Graph.Next.Next
+	assert.Equal(t, traverser.NodeTypeClickButton, submit.NodeType)
+	assert.Equal(t, "#login", submit.Selector)
+	assert.Equal(t, "#confirm", submit.Next.Selector)
+}
+
+func TestEngineExecute_WithYAMLWorkflowAndContextFiles_RunsWorkflow(t *testing.T) {
+	dir := t.TempDir()
+	path := writeWorkflowFile(t, dir, "login.yml", loginYAML)
+	contextPath := writeWorkflowFile(t, dir, "user.yaml", "user:\n  email: &email john@example.com\n  backup: *email\n")
+	user, err := traverser.LoadContextFile(contextPath)
+	require.NoError(t, err)
+	engine, browser := newFileEngine(t, path, user)
+
+	require.NoError(t, engine.Execute())
+
+	assert.Equal(t, "john@example.com", user["backup"])
+	assert.Equal(t, []string{
+		"navigate https://example.com/login",
+		"fill #email=john@example.com",
+		"click #login",
+		"click #confirm",
+	}, browser.Actions)
+}
+
+func TestValidateWorkflowYAML_WithViolations_ReportsYAMLPositions(t *testing.T) {
+	errs := traverser.ValidateWorkflowYAML([]byte(`graph:
+  nodeType: moveToPage
+  url: https://example.com
+  next:
+    nodeType: fillField
+    selector: "#email"
+    color: red
+`))
+
+	require.Len(t, errs, 2)
+	assert.Equal(t, "/graph/next/color", errs[0].Pointer)
+	assert.Equal(t, 7, errs[0].Line)
+	assert.Equal(t, 5, errs[0].Column)
+	assert.Equal(t, "/graph/next", errs[1].Pointer)
+	assert.Equal(t, 4, errs[1].Line)
+	assert.Contains(t, errs[1].Message, `"value"`)
+}
+
+func TestValidateWorkflowYAML_WithErrorInAnchor_ReportsAnchorLine(t *testing.T) {
+	errs := traverser.ValidateWorkflowYAML([]byte(`x-step: &step
+  nodeType: clickButton
+  selctor: "#go"
+graph:
+  nodeType: sequence
+  sequence:
+    - *step
+`))
+
+	require.NotEmpty(t, errs)
+	assert.Equal(t, "/graph/sequence/0/selctor", errs[0].Pointer)
+	assert.Equal(t, 3, errs[0].Line)
+}
+
+func TestValidateWorkflowYAML_WithSyntaxError_ReportsLine(t *testing.T) {
+	errs := traverser.ValidateWorkflowYAML([]byte("graph:\n  nodeType: wait\n   duration: 5\n"))
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, 3, errs[0].Line)
+	assert.Contains(t, errs[0].Message, "invalid YAML")
+}
+
+func TestParseWorkflowYAML_WithNewerSchemaVersion_RefusesDocument(t *testing.T) {
+	_, err := traverser.ParseWorkflowYAML([]byte("graph: {nodeType: wait, duration: 1}\nmetadata: {schemaVersion: 9}\n"))
+
+	var versionErr *traverser.SchemaVersionError
+	assert.True(t, errors.As(err, &versionErr))
+}
+
+func TestJSONToYAML_RoundTrip_KeepsDocumentUnchanged(t *testing.T) {
+	original := `{
+  "graph": {
+    "nodeType": "fillField",
+    "selector": "input[name='a'] > b",
+    "value": "true",
+    "timeout": 1500,
+    "next": null
+  },
+  "metadata": {
+    "name": "Round trip",
+    "version": "1.0",
+    "description": "line one\nline two"
+  },
+  "x-ratio": 1.50,
+  "x-empty": {},
+  "x-list": [
+    "0123",
+    false,
+    "null"
+  ]
+}
+`
+
+	yamlData, err := traverser.JSONToYAML([]byte(original))
+	require.NoError(t, err)
+	jsonData, err := traverser.YAMLToJSON(yamlData)
+	require.NoError(t, err)
+
+	assert.Equal(t, original, string(jsonData))
+}
+
+func TestYAMLToJSON_WithYAMLOnlyNumbers_WritesJSONNumbers(t *testing.T) {
+	data, err := traverser.YAMLToJSON([]byte("user:\n  hex: 0x10\n  float: .5\n"))
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"user": {"hex": 16, "float": 0.5}}`, string(data))
+}
+
+func TestValidateWorkflow_WithExtensionKey_IgnoresIt(t *testing.T) {
+	errs := traverser.ValidateWorkflow([]byte(`{"graph": {"nodeType": "wait", "duration": 1}, "x-notes": {"owner": "ops"}}`))
+
+	assert.Nil(t, errs)
+}
+
+func TestParseContextYAML_WithNestedAliasBomb_FailsInsteadOfExpanding(t *testing.T) {
+	var doc strings.Builder
+	doc.WriteString("a0: &a0 [lol, lol, lol, lol, lol, lol, lol, lol, lol]\n")
+	for level := 1; level <= 9; level++ {
+		doc.WriteString(fmt.Sprintf("a%d: &a%d [", level, level))
+		for i := 0; i < 9; i++ {
+			if i > 0 {
+				doc.WriteString(", ")
+			}
+			doc.WriteString(fmt.Sprintf("*a%d", level-1))
+		}
+		doc.WriteString("]\n")
+	}
+	doc.WriteString("user: {bomb: *a9}\n")
+
+	started := time.Now()
+	_, err := traverser.ParseContextYAML([]byte(doc.String()))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expands to more than")
+	assert.Less(t, time.Since(started), 10*time.Second)
+}